@@ -22,6 +22,41 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/batch/{id}": {
+            "get": {
+                "description": "Returns aggregated progress of a batch, and every child result in submission order once all of them have finished.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job Status"
+                ],
+                "summary": "Get batch status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Batch progress and results",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.BatchResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Batch not found or created for another user",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/chat": {
             "post": {
                 "description": "Accepts a message, initializes a background processing job, and returns a job ID to track status.",
@@ -37,7 +72,7 @@ const docTemplate = `{
                 "summary": "Start a new chat job",
                 "parameters": [
                     {
-                        "description": "Chat Message and optional Chat ID",
+                        "description": "Chat Message, optional Chat ID and retrieval options",
                         "name": "request",
                         "in": "body",
                         "required": true,
@@ -54,7 +89,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request data or chat ID",
+                        "description": "Invalid request data, chat ID or retrieval options out of bounds",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
@@ -62,53 +97,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/ingest": {
+        "/chat/batch": {
             "post": {
-                "description": "Receives a file via multipart/form-data, saves it to a temporary directory, and queues an ingestion job.",
+                "description": "Creates one batch record with a child job per question. Counts as a single request against the rate limit. Questions in a shared chat are answered independently and appended to the chat as they finish.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Ingestion"
+                    "Messaging"
                 ],
-                "summary": "Upload a document for ingestion",
+                "summary": "Submit many chat questions at once",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "The display name of the document",
-                        "name": "document_name",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "The PDF or DOCX file to upload",
-                        "name": "document",
-                        "in": "formData",
-                        "required": true
+                        "description": "Questions and optional shared chat",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.BatchChatRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Accepted - returns job_id",
+                        "description": "Batch created - poll /batch/{id}",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.InitBatchResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Missing fields or file too large",
+                        "description": "Invalid request data or chat ID",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/chats": {
+            "get": {
+                "description": "Returns the chats started for the calling user (X-User-Id), newest first. Pass next_cursor back as cursor to get the following page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chats"
+                ],
+                "summary": "List chats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, default 20, max 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One page of chats",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatListResponse"
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error - Storage or Write Error",
+                    "400": {
+                        "description": "Missing X-User-Id, invalid limit or cursor",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
@@ -116,49 +177,75 @@ const docTemplate = `{
                 }
             }
         },
-        "/mcp": {
-            "post": {
-                "description": "Accepts a question, runs tool-use via MCP and returns a job ID. This is stateless - each request is independent with no conversation history. Use /chat for multi-turn conversations.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/chats/{id}": {
+            "get": {
+                "description": "Returns every question of the chat with its answer, sources and time, oldest first. Chats of other users are not found.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "MCP"
+                    "Chats"
                 ],
-                "summary": "Submit a stateless MCP query",
+                "summary": "Get a chat transcript",
                 "parameters": [
                     {
-                        "description": "Question and optional request ID",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The chat and its turns",
                         "schema": {
-                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.MCPRequest"
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatTranscriptResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Chat not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
                     }
+                }
+            },
+            "delete": {
+                "description": "Queues an erasure job that deletes the chat history, the caller's jobs of the chat and the cached answers created from it. Poll the returned status URL for the report of what was removed. A chat that is already gone or belongs to another user is not found.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Erasure"
+                ],
+                "summary": "Erase a chat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Job created - poll /mcp/status/{id}",
+                        "description": "Erasure job created",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.InitJobResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "404": {
+                        "description": "Chat not found or belongs to another user",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
                     }
                 }
-            }
-        },
-        "/mcp/status/{id}": {
-            "get": {
-                "description": "Poll this endpoint to check the status of an MCP query. Returns the final answer when complete.",
+            },
+            "patch": {
+                "description": "Sets the title of the chat. Until renamed a chat is titled with the start of its first question.",
                 "consumes": [
                     "application/json"
                 ],
@@ -166,27 +253,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "MCP"
+                    "Chats"
                 ],
-                "summary": "Get MCP job status",
+                "summary": "Rename a chat",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID from the /mcp response",
+                        "description": "Chat ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "New title",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.RenameChatRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Current job status and result if complete",
+                        "description": "The renamed chat",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or too long title",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
                     },
                     "404": {
-                        "description": "Job not found",
+                        "description": "Chat not found",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
@@ -194,127 +296,1174 @@ const docTemplate = `{
                 }
             }
         },
-        "/status/{id}": {
+        "/chats/{id}/export": {
             "get": {
-                "description": "Retrieves the current status of a specific job using its ID.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Downloads the chat with every question, answer and cited source (document name and page), as a Markdown document or as JSON that keeps the same schema_version-ed layout so it can be read back in.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/markdown"
                 ],
                 "tags": [
-                    "Job Status"
+                    "Chats"
                 ],
-                "summary": "Get job status",
+                "summary": "Export a chat",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID ",
+                        "description": "Chat ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "md (default) or json",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful retrieval of job status",
+                        "description": "The chat, as Markdown for format=md",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown format",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
                     },
                     "404": {
-                        "description": "Job not found (returns Error object within JobResponse)",
+                        "description": "Chat not found",
                         "schema": {
                             "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "github_com_akolanti_GoAPI_internal_api.ChatRequest": {
-            "type": "object",
-            "required": [
-                "message"
-            ],
-            "properties": {
-                "chatID": {
-                    "type": "string"
-                },
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "github_com_akolanti_GoAPI_internal_api.InitJobResponse": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "status_url": {
-                    "type": "string"
-                }
-            }
-        },
-        "github_com_akolanti_GoAPI_internal_api.JobOutgoingError": {
-            "type": "object",
-            "properties": {
-                "can_retry": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "code": {
-                    "type": "integer",
-                    "example": 400
-                },
-                "message": {
-                    "type": "string",
-                    "example": "Job not found"
-                }
-            }
         },
-        "github_com_akolanti_GoAPI_internal_api.JobResponse": {
-            "type": "object",
-            "properties": {
-                "chat_id": {
-                    "type": "string",
-                    "example": "chat_550"
-                },
-                "end_time": {
-                    "type": "string"
-                },
-                "error": {
-                    "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobOutgoingError"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "job_cz109"
-                },
-                "result": {
-                    "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.Result"
-                },
-                "start_time": {
-                    "type": "string"
+        "/identities/{identity}": {
+            "delete": {
+                "description": "Queues an erasure job that deletes every job created for the identity (X-User-Id), the chats started for it or that those jobs belong to with all of their jobs, and the cached answers created from them. Erasure jobs themselves are kept, they only hold ids.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Erasure"
+                ],
+                "summary": "Erase everything of an identity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identity, as sent in X-User-Id",
+                        "name": "identity",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Erasure job created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.InitJobResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Identity is not the caller's X-User-Id",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
                 }
             }
         },
-        "github_com_akolanti_GoAPI_internal_api.MCPRequest": {
-            "type": "object",
-            "required": [
-                "message"
-            ],
-            "properties": {
-                "message": {
+        "/ingest": {
+            "post": {
+                "description": "Receives a file via multipart/form-data, saves it to a temporary directory, and queues an ingestion job.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingestion"
+                ],
+                "summary": "Upload a document for ingestion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The display name of the document",
+                        "name": "document_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "The PDF or DOCX file to upload",
+                        "name": "document",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional url the finished job is posted to",
+                        "name": "callback_url",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional RFC 3339 time, the job fails with error type TIMEOUT if it hasn't finished by then",
+                        "name": "deadline",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted - returns job_id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Missing fields or file too large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Storage or Write Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "description": "Returns the jobs created for the calling user (X-User-Id) newest first, filtered by any combination of the query parameters. Pass next_cursor back as cursor to get the following page. Jobs expire with the job store TTL.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job Status"
+                ],
+                "summary": "List jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only jobs of this chat",
+                        "name": "chat_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Query, Ingest, MCP, Batch or Erasure",
+                        "name": "job_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "QUEUED, RUNNING, COMPLETE or Error",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Must be the caller's X-User-Id when given",
+                        "name": "identity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339, inclusive",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339, exclusive",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, default 20, max 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One page of jobs",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Identity is not the caller's X-User-Id",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/feedback": {
+            "post": {
+                "description": "Rates the answer of a completed Query or MCP job, positive or negative with an optional comment. The rating is kept with the job and returned when it is polled, rating again replaces it. A negative rating evicts the answer from the semantic cache, so it isn't served to anyone again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job Status"
+                ],
+                "summary": "Rate an answer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rating and optional comment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.FeedbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The rated job",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown rating or too long comment",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The job has no answer to rate",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mcp": {
+            "post": {
+                "description": "Accepts a question, runs tool-use via MCP and returns a job ID. Every question is a turn of a chat: without chatID a new chat is started and its id is returned as chat_id when the job is polled. With chatID the earlier turns, their tool calls and tool results included, are replayed before the question so follow ups can refer to them. A chat of another user is a bad request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MCP"
+                ],
+                "summary": "Submit an MCP query",
+                "parameters": [
+                    {
+                        "description": "Question",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.MCPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job created - poll /mcp/status/{id}",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.InitJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mcp/status/{id}": {
+            "get": {
+                "description": "Poll this endpoint to check the status of an MCP query. Returns the final answer when complete. Supports the same wait long poll as /status/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MCP"
+                ],
+                "summary": "Get MCP job status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID from the /mcp response",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Long poll duration, e.g. 30s or 30",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Current job status and result if complete",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid wait",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found or created for another user",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "description": "Returns the schedules of the calling user (X-User-Id), including paused and already fired one-off schedules.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "List schedules",
+                "responses": {
+                    "200": {
+                        "description": "The caller's schedules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ScheduleResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Store error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Enqueues a Query, MCP or Ingest job once at run_at or repeatedly on a cron expression (UTC). Schedules survive restarts and only one replica fires each run.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Schedule a job",
+                "parameters": [
+                    {
+                        "description": "Job template and timing",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Schedule created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid schedule",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}": {
+            "delete": {
+                "description": "Removes a schedule. Jobs it already enqueued are not affected.",
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Delete a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Schedule deleted"
+                    },
+                    "404": {
+                        "description": "Schedule not found or created by another user",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/pause": {
+            "post": {
+                "description": "Stops a schedule from firing until it is resumed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Pause a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paused schedule",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ScheduleResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule not found or created by another user",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/resume": {
+            "post": {
+                "description": "Resumes a paused schedule. Cron schedules continue from the next matching time, runs missed while paused are skipped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Resume a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resumed schedule",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ScheduleResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule not found or created by another user",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status/{id}": {
+            "get": {
+                "description": "Retrieves the current status of a specific job using its ID. With wait the request is held until the job status changes or the wait runs out, the wait is capped just under the server write timeout.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job Status"
+                ],
+                "summary": "Get job status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID ",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Long poll duration, e.g. 30s or 30",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful retrieval of job status",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid wait",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found or created for another user (returns Error object within JobResponse)",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status/{id}/stream": {
+            "get": {
+                "description": "Server-Sent Events for one job. \"step\" events carry an api.StreamStep, \"delta\" events carry answer text as the LLM generates it, and a final \"done\" event carries the full api.JobResponse before the stream closes. Reconnect with the Last-Event-ID header (or last_event_id query) to continue after the last event received. The answer is still saved to the job store, so polling clients are unaffected.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Job Status"
+                ],
+                "summary": "Stream job progress and answer tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after this event id",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Same as Last-Event-ID, for clients that can't set headers",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "text/event-stream of step, delta and done events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Last-Event-ID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found or created for another user",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_akolanti_GoAPI_internal_api.BatchChatRequest": {
+            "type": "object",
+            "required": [
+                "messages"
+            ],
+            "properties": {
+                "chatID": {
+                    "type": "string"
+                },
+                "deadline": {
+                    "description": "applies to every question",
+                    "type": "string",
+                    "example": "2026-01-02T15:04:05Z"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "shared_chat": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.BatchProgress": {
+            "type": "object",
+            "properties": {
+                "complete": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "missing": {
+                    "description": "Missing children expired or were erased before the batch was read",
+                    "type": "integer",
+                    "example": 0
+                },
+                "queued": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "running": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "example": "chat_550"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "batch_7a1"
+                },
+                "progress": {
+                    "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.BatchProgress"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobResponse"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "RUNNING"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ChatExport": {
+            "type": "object",
+            "properties": {
+                "chat": {
+                    "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatSummary"
+                },
+                "exported_time": {
+                    "type": "string"
+                },
+                "schema_version": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "turns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatExportTurn"
+                    }
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ChatExportSource": {
+            "type": "object",
+            "properties": {
+                "chunk_id": {
+                    "type": "string"
+                },
+                "chunk_order": {
+                    "type": "integer"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "document_name": {
+                    "type": "string",
+                    "example": "pump-x200.pdf"
+                },
+                "ingested_time": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ChatExportTurn": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string"
+                },
+                "question": {
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatExportSource"
+                    }
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ChatListResponse": {
+            "type": "object",
+            "properties": {
+                "chats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatSummary"
+                    }
+                },
+                "next_cursor": {
+                    "description": "pass as ?cursor= for the next page, empty on the last page",
+                    "type": "string",
+                    "example": "MTcxMjM0NTY3ODkwMDpjaGF0XzU1MA"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ChatRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "callback_url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/jobs"
+                },
+                "chatID": {
+                    "type": "string"
+                },
+                "deadline": {
+                    "type": "string",
+                    "example": "2026-01-02T15:04:05Z"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "retrieval": {
+                    "description": "Retrieval tunes how context is found for this question, left out it is retrieved the default way",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.RetrievalOptions"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ChatSummary": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "chat_550"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Pump X200 maintenance"
+                },
+                "updated_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ChatTranscriptResponse": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "chat_550"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Pump X200 maintenance"
+                },
+                "turns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ChatTurn"
+                    }
+                },
+                "updated_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ChatTurn": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string",
+                    "example": "Every 500 operating hours."
+                },
+                "question": {
+                    "type": "string",
+                    "example": "How often should the X200 filter be changed?"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ErasureReport": {
+            "type": "object",
+            "properties": {
+                "cache_entries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "chat_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "chat_550"
+                    ]
+                },
+                "job_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "job_cz109"
+                    ]
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.Feedback": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "The torque value is for the X100"
+                },
+                "rating": {
+                    "type": "string",
+                    "example": "negative"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.FeedbackRequest": {
+            "type": "object",
+            "required": [
+                "rating"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "The torque value is for the X100"
+                },
+                "rating": {
+                    "type": "string",
+                    "example": "negative"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.InitBatchResponse": {
+            "type": "object",
+            "properties": {
+                "child_job_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.InitJobResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "status_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.JobListResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobSummary"
+                    }
+                },
+                "next_cursor": {
+                    "description": "pass as ?cursor= for the next page, empty on the last page",
+                    "type": "string",
+                    "example": "MTcxMjM0NTY3ODkwMDpqb2JfY3oxMDk"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.JobOutgoingError": {
+            "type": "object",
+            "properties": {
+                "can_retry": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Job not found"
+                },
+                "type": {
+                    "description": "TIMEOUT when the job ran out of time, resubmitting may succeed",
+                    "type": "string",
+                    "example": "TIMEOUT"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.JobResponse": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "example": "chat_550"
+                },
+                "current_step": {
+                    "type": "string",
+                    "example": "VectorDBCall"
+                },
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.WebhookDelivery"
+                    }
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "erasure": {
+                    "description": "erasure jobs only, once complete",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.ErasureReport"
+                        }
+                    ]
+                },
+                "error": {
+                    "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.JobOutgoingError"
+                },
+                "feedback": {
+                    "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.Feedback"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "job_cz109"
+                },
+                "progress": {
+                    "description": "ingestion only, percent of chunk batches upserted",
+                    "type": "integer",
+                    "example": 40
+                },
+                "result": {
+                    "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.Result"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "timeline": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_akolanti_GoAPI_internal_api.TimelineEntry"
+                    }
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.JobSummary": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "example": "chat_550"
+                },
+                "current_step": {
+                    "type": "string",
+                    "example": "Complete"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "job_cz109"
+                },
+                "identity": {
+                    "type": "string",
+                    "example": "user_42"
+                },
+                "job_type": {
+                    "type": "string",
+                    "example": "Query"
+                },
+                "parent_id": {
+                    "type": "string",
+                    "example": "batch_77a"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "COMPLETE"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.MCPRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "callback_url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/jobs"
+                },
+                "chatID": {
                     "type": "string"
                 },
-                "request_id": {
+                "deadline": {
+                    "type": "string",
+                    "example": "2026-01-02T15:04:05Z"
+                },
+                "message": {
                     "type": "string"
                 }
             }
         },
+        "github_com_akolanti_GoAPI_internal_api.RenameChatRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "example": "Pump X200 maintenance"
+                }
+            }
+        },
         "github_com_akolanti_GoAPI_internal_api.Response": {
             "type": "object",
             "properties": {
@@ -324,6 +1473,16 @@ const docTemplate = `{
                 "question": {
                     "type": "string"
                 },
+                "rewritten_queries": {
+                    "description": "RewrittenQueries what retrieval searched for instead of the question, with QUERY_REWRITE on",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "What is the torque for the X200-14 impeller bolts?"
+                    ]
+                },
                 "sources": {
                     "type": "array",
                     "items": {
@@ -342,6 +1501,151 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "github_com_akolanti_GoAPI_internal_api.RetrievalOptions": {
+            "type": "object",
+            "properties": {
+                "collection": {
+                    "description": "Collection vector DB collection to search, the ingest collection or one the server lists in RETRIEVAL_COLLECTIONS",
+                    "type": "string",
+                    "example": "pump-manuals"
+                },
+                "min_score": {
+                    "description": "MinScore lowest embedding similarity a chunk may have, 0 to 1. Keyword matches are not cut",
+                    "type": "number",
+                    "example": 0.6
+                },
+                "skip_cache": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "top_k": {
+                    "description": "TopK chunks handed to the LLM, 1 to 10, 3 by default",
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ScheduleRequest": {
+            "type": "object",
+            "required": [
+                "job_type"
+            ],
+            "properties": {
+                "chatID": {
+                    "type": "string"
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "0 2 * * *"
+                },
+                "document_name": {
+                    "type": "string"
+                },
+                "document_path": {
+                    "type": "string",
+                    "example": "manuals/pump-x200.pdf"
+                },
+                "job_type": {
+                    "type": "string",
+                    "example": "Query"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "run_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.ScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "type": "string"
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "0 2 * * *"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "sched_81f"
+                },
+                "job_type": {
+                    "type": "string",
+                    "example": "Ingest"
+                },
+                "last_job_id": {
+                    "type": "string"
+                },
+                "last_run": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "nightly manual re-ingest"
+                },
+                "next_run": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "run_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.TimelineEntry": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string",
+                    "example": "miss"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "step": {
+                    "type": "string",
+                    "example": "CacheCall"
+                }
+            }
+        },
+        "github_com_akolanti_GoAPI_internal_api.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "error": {
+                    "type": "string",
+                    "example": "503 Service Unavailable"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 503
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
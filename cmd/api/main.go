@@ -23,23 +23,18 @@ import (
 	"sync"
 	"syscall"
 
+	"github.com/akolanti/GoAPI/internal/app"
 	"github.com/akolanti/GoAPI/internal/config"
-	"github.com/akolanti/GoAPI/internal/data/store"
-	jobmodel "github.com/akolanti/GoAPI/internal/domain/jobModel"
 	"github.com/akolanti/GoAPI/internal/handlers"
-	"github.com/akolanti/GoAPI/internal/job"
-	llmFactory "github.com/akolanti/GoAPI/internal/llm/factory"
 	"github.com/akolanti/GoAPI/internal/mcpImpl"
-	"github.com/akolanti/GoAPI/internal/rag"
-	"github.com/akolanti/GoAPI/internal/rag/embedding/googleEmbedding"
-	"github.com/akolanti/GoAPI/internal/rag/vectorDB/qdrantDB"
+	"github.com/akolanti/GoAPI/internal/scheduler"
 	"github.com/akolanti/GoAPI/internal/server"
-	"github.com/akolanti/GoAPI/internal/worker"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
 var (
 	listenAddr        string
+	runMode           string
 	requestCount      int64
 	stopWorkerChannel chan bool
 	workerWaitGroup   sync.WaitGroup
@@ -51,52 +46,56 @@ func main() {
 	var logger = logger_i.NewLogger("main")
 
 	//config
+	defaultMode := os.Getenv("RUN_MODE")
+	if defaultMode == "" {
+		defaultMode = config.RunModeAll
+	}
 	flag.StringVar(&listenAddr, "listen-addr", config.ServerListenAddr, "server listen address")
+	flag.StringVar(&runMode, "mode", defaultMode, "all runs the workers in this process, api leaves the jobs in the redis queue for cmd/worker")
 	flag.Parse()
+	if runMode != config.RunModeAll && runMode != config.RunModeAPI {
+		logger.Error("Unknown run mode, expected all or api", "mode", runMode)
+		return
+	}
 
-	//init buffered job channel
-	jobChannel := make(chan jobmodel.Job, config.BufferLimit)
-	dispatcherChannel := make(chan bool, 1)
 	stopWorkerChannel = make(chan bool, 1)
 
 	serviceContext, closeExternalServices := context.WithCancel(context.Background())
 	defer closeExternalServices()
 
-	//init job service and job store
-	serviceConfig := job.ServiceConfig{
-		JobChannel:        jobChannel,
-		RequestCount:      requestCount,
-		DispatcherChannel: dispatcherChannel,
-		JobStore:          store.GetRedisJobStore(serviceContext),
-		MessageStore:      store.GetRedisMessageStore(serviceContext),
+	service, err := app.InitJobService(serviceContext, runMode, requestCount)
+	if err != nil {
+		logger.Error("Could not start the job service", "error", err)
+		return
 	}
-	logger.Info("Starting job service")
 
-	if serviceConfig.JobStore == nil || serviceConfig.MessageStore == nil {
-		logger.Error("Redis stores are offline")
-		serviceConfig.JobStore = store.InitInMemoryJobStore()
-		serviceConfig.MessageStore = store.InitMessageStore()
+	//negative feedback evicts answers from the semantic cache, the API does that itself in every run mode
+	service.AnswerCache = app.InitAnswerCache(serviceContext)
+	if service.AnswerCache == nil {
+		logger.Warn("Vector DB unreachable, negatively rated answers stay in the semantic cache")
 	}
-	service := job.InitJobService(serviceConfig)
 
-	vectorDB := qdrantDB.GetQuadrantClient(serviceContext)
-	embeddingService := googleEmbedding.GetGoogleEmbeddingClient(serviceContext, config.GoogleEmbeddingModel, config.GoogleEmbeddingAPIKey)
-	llmProvider := llmFactory.NewProvider(serviceContext)
-
-	if vectorDB == nil || embeddingService == nil || llmProvider == nil {
-		logger.Error("One or more external services failed to initialize. Shutting down.")
-		logger.Debug("Available services : ", "VectorDB", vectorDB != nil, "EmbeddingService", embeddingService != nil, "LLMProvider", llmProvider != nil)
+	llmProvider, err := app.InitLLM(serviceContext)
+	if err != nil {
+		logger.Error("One or more external services failed to initialize. Shutting down.", "error", err)
 		return
 	}
 
-	ragService := rag.NewService(vectorDB, llmProvider, embeddingService)
-
 	handlers.InitHandler(service)
 	mcpImpl.InitMCPHandler(serviceContext, llmProvider, service)
 
-	//init worker pool
-	worker.InitServices(service, ragService)
-	worker.InitWorkerPool(stopWorkerChannel, &workerWaitGroup)
+	//init worker pool - in api mode cmd/worker runs it
+	if runMode == config.RunModeAll {
+		ragService, err := app.InitRAGService(serviceContext, llmProvider)
+		if err != nil {
+			logger.Error("One or more external services failed to initialize. Shutting down.", "error", err)
+			return
+		}
+		app.StartWorkers(service, ragService, stopWorkerChannel, &workerWaitGroup)
+	}
+
+	//scheduled and recurring jobs
+	scheduler.InitScheduler(serviceContext, service)
 
 	//server handling
 	gracefulShutdown := make(chan os.Signal, 1)
@@ -0,0 +1,63 @@
+// Migration command - rewrites the jobs, schedules and chat entries saved with an older schema version
+// (see internal/data/schema) in the current one. Reads upgrade old records on their own, this only saves
+// doing it on every read and lets an upgrade be dropped once no record needs it anymore.
+// It can run next to the API and the workers: a record saved again in the meantime is left for the next run
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+func main() {
+	logger_i.Init()
+	var logger = logger_i.NewLogger("migrate main")
+
+	var dryRun bool
+	flag.BoolVar(&dryRun, "dry-run", false, "count the records that need upgrading without rewriting them")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	jobStore, err := store.GetRedisJobStore(ctx)
+	if err != nil {
+		logger.Error("Could not connect the job store", "error", err)
+		os.Exit(1)
+	}
+	messageStore, err := store.GetRedisMessageStore(ctx)
+	if err != nil {
+		logger.Error("Could not connect the message store", "error", err)
+		os.Exit(1)
+	}
+
+	migrations := []struct {
+		name    string
+		migrate func(context.Context, bool) (store.MigrationReport, error)
+	}{
+		{"job store", jobStore.MigrateRecords},
+		{"message store", messageStore.MigrateRecords},
+	}
+
+	var total store.MigrationReport
+	for _, migration := range migrations {
+		report, err := migration.migrate(ctx, dryRun)
+		logger.Info("Migrated "+migration.name, "scanned", report.Scanned, "upgraded", report.Upgraded, "changed meanwhile", report.Changed, "failed", report.Failed, "dry run", dryRun)
+		total.Add(report)
+		if err != nil {
+			logger.Error("Migration stopped", "store", migration.name, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	logger.Info("Migration finished", "scanned", total.Scanned, "upgraded", total.Upgraded, "changed meanwhile", total.Changed, "failed", total.Failed, "dry run", dryRun)
+	if total.Failed > 0 {
+		os.Exit(1)
+	}
+}
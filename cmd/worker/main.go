@@ -0,0 +1,102 @@
+// Worker process for split deployments - takes jobs from the redis queue that
+// cmd/api fills when it runs with -mode=api, and writes their status back to the job store
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/app"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/worker"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	metricsAddr       string
+	requestCount      int64
+	stopWorkerChannel chan bool
+	workerWaitGroup   sync.WaitGroup
+)
+
+func main() {
+	logger_i.Init()
+	var logger = logger_i.NewLogger("worker main")
+
+	flag.StringVar(&metricsAddr, "metrics-addr", config.WorkerMetricsAddr, "prometheus /metrics listen address")
+	flag.Parse()
+
+	stopWorkerChannel = make(chan bool, 1)
+
+	serviceContext, closeExternalServices := context.WithCancel(context.Background())
+	defer closeExternalServices()
+
+	service, err := app.InitJobService(serviceContext, config.RunModeWorker, requestCount)
+	if err != nil {
+		logger.Error("Could not start the job service", "error", err)
+		return
+	}
+	llmProvider, err := app.InitLLM(serviceContext)
+	if err != nil {
+		logger.Error("One or more external services failed to initialize. Shutting down.", "error", err)
+		return
+	}
+	ragService, err := app.InitRAGService(serviceContext, llmProvider)
+	if err != nil {
+		logger.Error("One or more external services failed to initialize. Shutting down.", "error", err)
+		return
+	}
+
+	app.StartWorkers(service, ragService, stopWorkerChannel, &workerWaitGroup)
+	go worker.KeepQueueAlive(serviceContext, service.JobQueue)
+	queueContext, stopConsuming := context.WithCancel(serviceContext)
+	consumerDone := make(chan struct{})
+	go func() {
+		worker.ConsumeQueue(queueContext, service.JobQueue)
+		close(consumerDone)
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	metricsServer := &http.Server{Addr: metricsAddr, Handler: mux, ReadTimeout: config.ReadTimeout, WriteTimeout: config.WriteTimeout}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics listener crashed", "error", err, "addr", metricsAddr)
+		}
+	}()
+
+	gracefulShutdown := make(chan os.Signal, 1)
+	signal.Notify(gracefulShutdown, syscall.SIGINT, syscall.SIGTERM)
+	<-gracefulShutdown
+	logger.Info("Worker is shutting down")
+
+	//stop taking jobs, let the running ones finish, and hand back the ones that never started
+	stopConsuming()
+	<-consumerDone
+	close(stopWorkerChannel)
+
+	done := make(chan struct{})
+	go func() {
+		workerWaitGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(config.ShutdownContextTimeout):
+		logger.Warn("Workers did not finish in time")
+	}
+
+	requeueContext, cancel := context.WithTimeout(context.Background(), config.ShutdownContextTimeout)
+	defer cancel()
+	worker.RequeueUnstarted(requeueContext, service.JobQueue)
+	_ = metricsServer.Shutdown(requeueContext)
+	logger.Info("Worker stopped")
+}
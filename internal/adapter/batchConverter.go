@@ -0,0 +1,49 @@
+package adapter
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func ToInitBatchResponse(id string, childIds []string) api.InitBatchResponse {
+	return api.InitBatchResponse{
+		Id:          id,
+		StatusURL:   fmt.Sprintf("batch/%s", id),
+		ChildJobIds: childIds,
+	}
+}
+
+// ToBatchResponse children missing from the map have expired or been erased, their result is a 404
+func ToBatchResponse(parent jobModel.Job, children map[string]jobModel.Job) api.BatchResponse {
+	progress := jobModel.NewBatchProgress(parent, children)
+	response := api.BatchResponse{
+		Id:     parent.Id,
+		ChatId: parent.ChatId,
+		Status: string(progress.Status()),
+		Progress: api.BatchProgress{
+			Total:    progress.Total,
+			Queued:   progress.Queued,
+			Running:  progress.Running,
+			Complete: progress.Complete,
+			Failed:   progress.Failed,
+			Missing:  progress.Missing,
+		},
+		StartTime: parent.CreatedTime,
+	}
+
+	if progress.Finished() {
+		response.Results = make([]api.JobResponse, 0, progress.Total)
+		for _, childId := range parent.JobPayload.ChildJobIds {
+			child, found := children[childId]
+			if !found {
+				response.Results = append(response.Results, BadRequest(childId, "Job not found", http.StatusNotFound))
+				continue
+			}
+			response.Results = append(response.Results, ToAPIResponse(child))
+		}
+	}
+	return response
+}
@@ -0,0 +1,77 @@
+package adapter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func TestToBatchResponse(t *testing.T) {
+	parent := jobModel.Job{Id: "batch-1", JobType: jobModel.JobTypeBatch, JobPayload: jobModel.JobPayload{ChildJobIds: []string{"c0", "c1", "c2"}}}
+	child := func(id string, status jobModel.JobStatus) jobModel.Job {
+		return jobModel.Job{Id: id, JobType: jobModel.JobTypeQuery, Status: status}
+	}
+
+	tests := []struct {
+		name         string
+		children     map[string]jobModel.Job
+		wantStatus   jobModel.JobStatus
+		wantProgress api.BatchProgress
+	}{
+		{
+			name:         "none picked up",
+			children:     map[string]jobModel.Job{"c0": child("c0", jobModel.JobStatusQueued), "c1": child("c1", jobModel.JobStatusQueued), "c2": child("c2", jobModel.JobStatusQueued)},
+			wantStatus:   jobModel.JobStatusQueued,
+			wantProgress: api.BatchProgress{Total: 3, Queued: 3},
+		},
+		{
+			name:         "running, complete and failed",
+			children:     map[string]jobModel.Job{"c0": child("c0", jobModel.JobStatusComplete), "c1": child("c1", jobModel.JobStatusRunning), "c2": child("c2", jobModel.JobStatusError)},
+			wantStatus:   jobModel.JobStatusRunning,
+			wantProgress: api.BatchProgress{Total: 3, Running: 1, Complete: 1, Failed: 1},
+		},
+		{
+			name:         "queued and complete",
+			children:     map[string]jobModel.Job{"c0": child("c0", jobModel.JobStatusQueued), "c1": child("c1", jobModel.JobStatusComplete), "c2": child("c2", jobModel.JobStatusQueued)},
+			wantStatus:   jobModel.JobStatusRunning,
+			wantProgress: api.BatchProgress{Total: 3, Queued: 2, Complete: 1},
+		},
+		{
+			name:         "all finished",
+			children:     map[string]jobModel.Job{"c2": child("c2", jobModel.JobStatusComplete), "c0": child("c0", jobModel.JobStatusError), "c1": child("c1", jobModel.JobStatusComplete)},
+			wantStatus:   jobModel.JobStatusComplete,
+			wantProgress: api.BatchProgress{Total: 3, Complete: 2, Failed: 1},
+		},
+		{
+			name:         "expired children",
+			children:     map[string]jobModel.Job{"c1": child("c1", jobModel.JobStatusComplete)},
+			wantStatus:   jobModel.JobStatusComplete,
+			wantProgress: api.BatchProgress{Total: 3, Complete: 1, Missing: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := ToBatchResponse(parent, tt.children)
+			if response.Status != string(tt.wantStatus) || response.Progress != tt.wantProgress {
+				t.Errorf("Expected %s %+v, got %s %+v", tt.wantStatus, tt.wantProgress, response.Status, response.Progress)
+			}
+			if tt.wantStatus != jobModel.JobStatusComplete {
+				if response.Results != nil {
+					t.Errorf("Expected no results before every child finished, got %+v", response.Results)
+				}
+				return
+			}
+			for i, result := range response.Results {
+				if result.Id != parent.JobPayload.ChildJobIds[i] {
+					t.Errorf("Expected result %d to be %s, results must follow the submission order", i, parent.JobPayload.ChildJobIds[i])
+				}
+				if _, found := tt.children[result.Id]; !found && (result.Error == nil || result.Error.Code != http.StatusNotFound) {
+					t.Errorf("Expected the missing child %s to be reported as not found, got %+v", result.Id, result)
+				}
+			}
+		})
+	}
+}
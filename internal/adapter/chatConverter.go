@@ -0,0 +1,36 @@
+package adapter
+
+import (
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func ToChatSummary(chat jobModel.ChatInfo) api.ChatSummary {
+	return api.ChatSummary{
+		Id:          chat.Id,
+		Title:       chat.Title,
+		CreatedTime: chat.CreatedTime,
+		UpdatedTime: chat.UpdatedTime,
+	}
+}
+
+func ToChatListResponse(page jobModel.ChatPage) api.ChatListResponse {
+	chats := make([]api.ChatSummary, 0, len(page.Chats))
+	for _, chat := range page.Chats {
+		chats = append(chats, ToChatSummary(chat))
+	}
+	return api.ChatListResponse{Chats: chats, NextCursor: page.NextCursor}
+}
+
+func ToChatTranscriptResponse(chat jobModel.ChatInfo, entries []jobModel.ChatEntry) api.ChatTranscriptResponse {
+	turns := make([]api.ChatTurn, 0, len(entries))
+	for _, entry := range entries {
+		turns = append(turns, api.ChatTurn{
+			Question: entry.Question,
+			Answer:   entry.Answer,
+			Sources:  jobModel.FlattenSources(entry.Sources),
+			Time:     entry.Time,
+		})
+	}
+	return api.ChatTranscriptResponse{ChatSummary: ToChatSummary(chat), Turns: turns}
+}
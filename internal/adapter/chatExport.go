@@ -0,0 +1,96 @@
+package adapter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func ToChatExport(chat jobModel.ChatInfo, entries []jobModel.ChatEntry, exportedTime time.Time) api.ChatExport {
+	turns := make([]api.ChatExportTurn, 0, len(entries))
+	for _, entry := range entries {
+		sources := make([]api.ChatExportSource, 0, len(entry.Sources))
+		for _, source := range entry.Sources {
+			sources = append(sources, api.ChatExportSource{
+				DocumentName: source.DocumentName,
+				DocumentId:   source.DocumentId,
+				Page:         source.Page,
+				ChunkId:      source.ChunkId,
+				ChunkOrder:   source.ChunkOrder,
+				IngestedTime: source.IngestedAt,
+			})
+		}
+		turns = append(turns, api.ChatExportTurn{
+			Question: entry.Question,
+			Answer:   entry.Answer,
+			Time:     entry.Time,
+			Sources:  sources,
+		})
+	}
+	return api.ChatExport{
+		SchemaVersion: config.ChatExportSchemaVersion,
+		ExportedTime:  exportedTime,
+		Chat:          ToChatSummary(chat),
+		Turns:         turns,
+	}
+}
+
+// ToChatMarkdown renders the export for a ticket or a wiki page. Several chunks of the same page are cited once
+func ToChatMarkdown(export api.ChatExport) string {
+	var md strings.Builder
+	title := export.Chat.Title
+	if title == "" {
+		title = "Chat " + export.Chat.Id
+	}
+	fmt.Fprintf(&md, "# %s\n\n", title)
+	fmt.Fprintf(&md, "- Chat ID: `%s`\n", export.Chat.Id)
+	if !export.Chat.CreatedTime.IsZero() {
+		fmt.Fprintf(&md, "- Started: %s\n", markdownTime(export.Chat.CreatedTime))
+	}
+	fmt.Fprintf(&md, "- Exported: %s\n", markdownTime(export.ExportedTime))
+
+	for i, turn := range export.Turns {
+		fmt.Fprintf(&md, "\n## Question %d", i+1)
+		if !turn.Time.IsZero() {
+			fmt.Fprintf(&md, " (%s)", markdownTime(turn.Time))
+		}
+		fmt.Fprintf(&md, "\n\n%s\n\n**Answer**\n\n%s\n", strings.TrimSpace(turn.Question), strings.TrimSpace(turn.Answer))
+
+		var cited []string
+		for _, source := range turn.Sources {
+			if label := sourceLabel(source); !slices.Contains(cited, label) {
+				cited = append(cited, label)
+			}
+		}
+		if len(cited) > 0 {
+			md.WriteString("\n**Sources**\n\n")
+			for _, label := range cited {
+				fmt.Fprintf(&md, "- %s\n", label)
+			}
+		}
+	}
+	return md.String()
+}
+
+func sourceLabel(source api.ChatExportSource) string {
+	label := source.DocumentName
+	if label == "" {
+		label = source.DocumentId
+	}
+	if label == "" {
+		label = "Unknown document"
+	}
+	if source.Page > 0 {
+		label += fmt.Sprintf(", page %d", source.Page)
+	}
+	return label
+}
+
+func markdownTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 UTC")
+}
@@ -0,0 +1,88 @@
+package adapter_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/adapter"
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func exportedChat() api.ChatExport {
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	chat := jobModel.ChatInfo{Id: "chat-1", Title: "Pump X200", CreatedTime: created, UpdatedTime: created}
+	entries := []jobModel.ChatEntry{
+		{
+			JobPayload: jobModel.JobPayload{
+				Question: "How often is the filter changed?",
+				Answer:   "Every 500 hours.",
+				Sources: jobModel.ParseSources([]string{
+					"doc_name:pump-x200.pdf", "page_num:12", "chunk_order:0", "chunk_id:c1", "ingested_at:1767225600", "source_doc_id:d1",
+					"doc_name:pump-x200.pdf", "page_num:12", "chunk_order:1", "chunk_id:c2", "ingested_at:1767225600", "source_doc_id:d1",
+					"doc_name:service.docx", "page_num:3", "chunk_order:0", "chunk_id:c3", "ingested_at:1767225600", "source_doc_id:d2",
+				}),
+			},
+			Time: created.Add(time.Minute),
+		},
+		{
+			//saved before the document name was recorded
+			JobPayload: jobModel.JobPayload{
+				Question: "And the oil?",
+				Answer:   "Every 1000 hours.",
+				Sources:  jobModel.ParseSources([]string{"page_num:", "chunk_order:", "chunk_id:c9", "ingested_at:", "source_doc_id:d9"}),
+			},
+		},
+	}
+	return adapter.ToChatExport(chat, entries, created.Add(time.Hour))
+}
+
+func TestToChatExport_GroupsSourcesPerChunk(t *testing.T) {
+	export := exportedChat()
+
+	if export.SchemaVersion != 1 || export.Chat.Id != "chat-1" || len(export.Turns) != 2 {
+		t.Fatalf("Unexpected export %+v", export)
+	}
+	sources := export.Turns[0].Sources
+	if len(sources) != 3 {
+		t.Fatalf("Expected one source per chunk, got %+v", sources)
+	}
+	if sources[1].DocumentName != "pump-x200.pdf" || sources[1].Page != 12 || sources[1].ChunkId != "c2" || sources[1].IngestedTime.Unix() != 1767225600 {
+		t.Errorf("Unexpected second source %+v", sources[1])
+	}
+	legacy := export.Turns[1].Sources
+	if len(legacy) != 1 || legacy[0].DocumentId != "d9" || legacy[0].Page != 0 {
+		t.Errorf("Expected the old source to keep its document id, got %+v", legacy)
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var readBack api.ChatExport
+	if err = json.Unmarshal(data, &readBack); err != nil || readBack.Turns[0].Sources[2].DocumentName != "service.docx" {
+		t.Errorf("Expected the export to read back, got %+v %v", readBack, err)
+	}
+}
+
+func TestToChatMarkdown_CitesEachPageOnce(t *testing.T) {
+	md := adapter.ToChatMarkdown(exportedChat())
+
+	for _, want := range []string{
+		"# Pump X200\n",
+		"- Chat ID: `chat-1`\n",
+		"## Question 1 (2026-03-01 09:31 UTC)\n\nHow often is the filter changed?\n\n**Answer**\n\nEvery 500 hours.\n",
+		"- pump-x200.pdf, page 12\n- service.docx, page 3\n",
+		"## Question 2\n",
+		"- d9\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected the markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+	if strings.Count(md, "pump-x200.pdf, page 12") != 1 {
+		t.Errorf("Expected the page to be cited once, got:\n%s", md)
+	}
+}
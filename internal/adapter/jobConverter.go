@@ -21,6 +21,7 @@ func ToAPIResponse(job jobModel.Job) api.JobResponse {
 	if job.Error.Message != "" || job.Error.Code != 0 {
 		errorPtr = &api.JobOutgoingError{
 			Code:    job.Error.Code,
+			Type:    string(job.Error.Type),
 			Message: job.Error.Message,
 			Retry:   job.Error.Retry,
 		}
@@ -31,14 +32,99 @@ func ToAPIResponse(job jobModel.Job) api.JobResponse {
 		ExternalResponse: ToRAGExternalStatus(job.JobPayload),
 	}
 
+	var progress *int
+	if job.JobType == jobModel.JobTypeIngest {
+		progress = &job.Progress
+	}
+
 	return api.JobResponse{
-		Id:        job.Id,
-		ChatId:    job.ChatId,
-		StartTime: job.CreatedTime,
-		EndTime:   job.EndTime,
-		Error:     errorPtr,
-		Result:    result,
+		Id:          job.Id,
+		ChatId:      job.ChatId,
+		StartTime:   job.CreatedTime,
+		EndTime:     job.EndTime,
+		Error:       errorPtr,
+		Result:      result,
+		CurrentStep: string(job.CurrentStep),
+		Timeline:    ToTimeline(job.Timeline),
+		Progress:    progress,
+		Deliveries:  ToWebhookDeliveries(job.Deliveries),
+		Erasure:     ToErasureReport(job.JobPayload.Erasure),
+		Feedback:    ToFeedback(job.Feedback),
+	}
+}
+
+func ToRetrievalOptions(options *api.RetrievalOptions) *jobModel.RetrievalOptions {
+	if options == nil {
+		return nil
+	}
+	return &jobModel.RetrievalOptions{
+		TopK:       options.TopK,
+		MinScore:   options.MinScore,
+		SkipCache:  options.SkipCache,
+		Collection: options.Collection,
+	}
+}
+
+func ToFeedback(feedback *jobModel.Feedback) *api.Feedback {
+	if feedback == nil {
+		return nil
+	}
+	return &api.Feedback{Rating: string(feedback.Rating), Comment: feedback.Comment, Time: feedback.Time}
+}
+
+func ToErasureReport(report *jobModel.ErasureReport) *api.ErasureReport {
+	if report == nil {
+		return nil
+	}
+	return &api.ErasureReport{
+		ChatIds:      report.ChatIds,
+		JobIds:       report.JobIds,
+		CacheEntries: report.CacheEntries,
+	}
+}
+
+func ToWebhookDeliveries(deliveries []jobModel.Delivery) []api.WebhookDelivery {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	result := make([]api.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, api.WebhookDelivery{
+			Attempt:    delivery.Attempt,
+			Time:       delivery.Time,
+			StatusCode: delivery.StatusCode,
+			Error:      delivery.Error,
+			Success:    delivery.Success,
+		})
+	}
+	return result
+}
+
+func ToStreamStep(job jobModel.Job) api.StreamStep {
+	return api.StreamStep{
+		Status: string(job.Status),
+		Step:   string(job.CurrentStep),
+	}
+}
+
+func ToTimeline(events []jobModel.StepEvent) []api.TimelineEntry {
+	if len(events) == 0 {
+		return nil
+	}
+	timeline := make([]api.TimelineEntry, 0, len(events))
+	for _, event := range events {
+		entry := api.TimelineEntry{
+			Step:      string(event.Step),
+			StartTime: event.StartTime,
+			EndTime:   event.EndTime,
+			Outcome:   string(event.Outcome),
+		}
+		if !event.EndTime.IsZero() {
+			entry.DurationMs = event.EndTime.Sub(event.StartTime).Milliseconds()
+		}
+		timeline = append(timeline, entry)
 	}
+	return timeline
 }
 
 func ToRAGExternalStatus(ragData jobModel.JobPayload) *api.Response {
@@ -47,9 +133,10 @@ func ToRAGExternalStatus(ragData jobModel.JobPayload) *api.Response {
 	}
 
 	return &api.Response{
-		Question: ragData.Question,
-		Answer:   ragData.Answer,
-		Sources:  ragData.Sources,
+		Question:         ragData.Question,
+		Answer:           ragData.Answer,
+		Sources:          jobModel.FlattenSources(ragData.Sources),
+		RewrittenQueries: ragData.RewrittenQueries,
 	}
 }
 
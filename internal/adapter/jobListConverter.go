@@ -0,0 +1,24 @@
+package adapter
+
+import (
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func ToJobListResponse(page jobModel.JobPage) api.JobListResponse {
+	jobs := make([]api.JobSummary, 0, len(page.Jobs))
+	for _, job := range page.Jobs {
+		jobs = append(jobs, api.JobSummary{
+			Id:          job.Id,
+			ParentId:    job.ParentId,
+			ChatId:      job.ChatId,
+			JobType:     string(job.JobType),
+			Status:      string(job.Status),
+			CurrentStep: string(job.CurrentStep),
+			Identity:    job.Identity,
+			StartTime:   job.CreatedTime,
+			EndTime:     job.EndTime,
+		})
+	}
+	return api.JobListResponse{Jobs: jobs, NextCursor: page.NextCursor}
+}
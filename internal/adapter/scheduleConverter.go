@@ -0,0 +1,46 @@
+package adapter
+
+import (
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func ToSchedule(request api.ScheduleRequest, traceId string, identity string) jobModel.Schedule {
+	return jobModel.Schedule{
+		Name:           request.Name,
+		JobType:        jobModel.JobType(request.JobType),
+		ChatId:         request.ChatID,
+		RunAt:          request.RunAt,
+		CronExpression: request.Cron,
+		TraceId:        traceId,
+		Identity:       identity,
+		JobPayload: jobModel.JobPayload{
+			Question:       request.Message,
+			IngestFileName: request.DocumentName,
+			IngestURL:      request.DocumentPath,
+		},
+	}
+}
+
+func ToScheduleResponse(schedule jobModel.Schedule) api.ScheduleResponse {
+	return api.ScheduleResponse{
+		Id:          schedule.Id,
+		Name:        schedule.Name,
+		JobType:     string(schedule.JobType),
+		Cron:        schedule.CronExpression,
+		RunAt:       schedule.RunAt,
+		Paused:      schedule.Paused,
+		NextRun:     schedule.NextRun,
+		LastRun:     schedule.LastRun,
+		LastJobId:   schedule.LastJobId,
+		CreatedTime: schedule.CreatedTime,
+	}
+}
+
+func ToScheduleResponses(schedules []jobModel.Schedule) []api.ScheduleResponse {
+	responses := make([]api.ScheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		responses = append(responses, ToScheduleResponse(schedule))
+	}
+	return responses
+}
@@ -9,16 +9,60 @@ const (
 )
 
 type JobResponse struct {
-	Id        string            `json:"id" example:"job_cz109"`
-	ChatId    string            `json:"chat_id" example:"chat_550"`
-	Result    Result            `json:"result"`
-	Error     *JobOutgoingError `json:"error,omitempty"`
-	StartTime time.Time         `json:"start_time"`
-	EndTime   time.Time         `json:"end_time,omitempty"`
+	Id          string            `json:"id" example:"job_cz109"`
+	ChatId      string            `json:"chat_id" example:"chat_550"`
+	Result      Result            `json:"result"`
+	Error       *JobOutgoingError `json:"error,omitempty"`
+	StartTime   time.Time         `json:"start_time"`
+	EndTime     time.Time         `json:"end_time,omitempty"`
+	CurrentStep string            `json:"current_step,omitempty" example:"VectorDBCall"`
+	Timeline    []TimelineEntry   `json:"timeline,omitempty"`
+	Progress    *int              `json:"progress,omitempty" example:"40"` // ingestion only, percent of chunk batches upserted
+	Deliveries  []WebhookDelivery `json:"deliveries,omitempty"`
+	Erasure     *ErasureReport    `json:"erasure,omitempty"` // erasure jobs only, once complete
+	Feedback    *Feedback         `json:"feedback,omitempty"`
+}
+
+// Feedback the rating the answer was given, see POST /jobs/{id}/feedback
+type Feedback struct {
+	Rating  string    `json:"rating" example:"negative"`
+	Comment string    `json:"comment,omitempty" example:"The torque value is for the X100"`
+	Time    time.Time `json:"time"`
+}
+
+// ErasureReport what an erasure job removed, data that was already gone is not listed
+type ErasureReport struct {
+	ChatIds      []string `json:"chat_ids" example:"chat_550"`
+	JobIds       []string `json:"job_ids" example:"job_cz109"`
+	CacheEntries int      `json:"cache_entries" example:"3"`
+}
+
+// StreamStep data of a "step" event on /status/{id}/stream, "delta" events carry raw answer text
+// and the closing "done" event carries the full JobResponse
+type StreamStep struct {
+	Status string `json:"status" example:"RUNNING"`
+	Step   string `json:"step" example:"LLM"`
+}
+
+type WebhookDelivery struct {
+	Attempt    int       `json:"attempt" example:"1"`
+	Time       time.Time `json:"time"`
+	StatusCode int       `json:"status_code,omitempty" example:"503"`
+	Error      string    `json:"error,omitempty" example:"503 Service Unavailable"`
+	Success    bool      `json:"success" example:"false"`
+}
+
+type TimelineEntry struct {
+	Step       string    `json:"step" example:"CacheCall"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time,omitempty"`
+	Outcome    string    `json:"outcome" example:"miss"`
+	DurationMs int64     `json:"duration_ms,omitempty" example:"42"`
 }
 
 type JobOutgoingError struct {
 	Code    int    `json:"code" example:"400"`
+	Type    string `json:"type,omitempty" example:"TIMEOUT"` // TIMEOUT when the job ran out of time, resubmitting may succeed
 	Message string `json:"message" example:"Job not found"`
 	Retry   bool   `json:"can_retry" example:"false"`
 }
@@ -27,6 +71,8 @@ type Response struct {
 	Question string   `json:"question"`
 	Answer   string   `json:"answer"`
 	Sources  []string `json:"sources"`
+	// RewrittenQueries what retrieval searched for instead of the question, with QUERY_REWRITE on
+	RewrittenQueries []string `json:"rewritten_queries,omitempty" example:"What is the torque for the X200-14 impeller bolts?"`
 }
 
 type Result struct {
@@ -39,12 +85,159 @@ type InitJobResponse struct {
 	StatusURL string `json:"status_url"`
 }
 
+type InitBatchResponse struct {
+	Id          string   `json:"id"`
+	StatusURL   string   `json:"status_url"`
+	ChildJobIds []string `json:"child_job_ids"`
+}
+
+type BatchProgress struct {
+	Total    int `json:"total" example:"12"`
+	Queued   int `json:"queued" example:"3"`
+	Running  int `json:"running" example:"2"`
+	Complete int `json:"complete" example:"6"`
+	Failed   int `json:"failed" example:"1"`
+	//Missing children expired or were erased before the batch was read
+	Missing int `json:"missing" example:"0"`
+}
+
+// BatchResponse results are only filled in once every child job has finished,
+// in the same order as the submitted messages
+type BatchResponse struct {
+	Id        string        `json:"id" example:"batch_7a1"`
+	ChatId    string        `json:"chat_id,omitempty" example:"chat_550"`
+	Status    string        `json:"status" example:"RUNNING"`
+	Progress  BatchProgress `json:"progress"`
+	Results   []JobResponse `json:"results,omitempty"`
+	StartTime time.Time     `json:"start_time"`
+}
+
+// JobSummary one row of GET /jobs, fetch /status/{id} for the answer and timeline
+type JobSummary struct {
+	Id          string    `json:"id" example:"job_cz109"`
+	ParentId    string    `json:"parent_id,omitempty" example:"batch_77a"`
+	ChatId      string    `json:"chat_id,omitempty" example:"chat_550"`
+	JobType     string    `json:"job_type" example:"Query"`
+	Status      string    `json:"status" example:"COMPLETE"`
+	CurrentStep string    `json:"current_step,omitempty" example:"Complete"`
+	Identity    string    `json:"identity,omitempty" example:"user_42"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time,omitempty"`
+}
+
+type JobListResponse struct {
+	Jobs       []JobSummary `json:"jobs"`
+	NextCursor string       `json:"next_cursor,omitempty" example:"MTcxMjM0NTY3ODkwMDpqb2JfY3oxMDk"` // pass as ?cursor= for the next page, empty on the last page
+}
+
+// ChatSummary one row of GET /chats, fetch /chats/{id} for the transcript
+type ChatSummary struct {
+	Id          string    `json:"id" example:"chat_550"`
+	Title       string    `json:"title,omitempty" example:"Pump X200 maintenance"`
+	CreatedTime time.Time `json:"created_time,omitempty"`
+	UpdatedTime time.Time `json:"updated_time,omitempty"`
+}
+
+type ChatListResponse struct {
+	Chats      []ChatSummary `json:"chats"`
+	NextCursor string        `json:"next_cursor,omitempty" example:"MTcxMjM0NTY3ODkwMDpjaGF0XzU1MA"` // pass as ?cursor= for the next page, empty on the last page
+}
+
+// ChatTurn one question of a chat with its answer, time is missing for turns saved before it was kept
+type ChatTurn struct {
+	Question string    `json:"question" example:"How often should the X200 filter be changed?"`
+	Answer   string    `json:"answer" example:"Every 500 operating hours."`
+	Sources  []string  `json:"sources,omitempty"`
+	Time     time.Time `json:"time,omitempty"`
+}
+
+type ChatTranscriptResponse struct {
+	ChatSummary
+	Turns []ChatTurn `json:"turns"`
+}
+
+// ChatExport the JSON of GET /chats/{id}/export. Fields are only ever added, schema_version goes up if one
+// changes meaning, so an export can be read back in later
+type ChatExport struct {
+	SchemaVersion int              `json:"schema_version" example:"1"`
+	ExportedTime  time.Time        `json:"exported_time"`
+	Chat          ChatSummary      `json:"chat"`
+	Turns         []ChatExportTurn `json:"turns"`
+}
+
+type ChatExportTurn struct {
+	Question string             `json:"question"`
+	Answer   string             `json:"answer"`
+	Time     time.Time          `json:"time,omitempty"`
+	Sources  []ChatExportSource `json:"sources"`
+}
+
+// ChatExportSource a document chunk the answer cites, page is 0 when it wasn't recorded
+type ChatExportSource struct {
+	DocumentName string    `json:"document_name,omitempty" example:"pump-x200.pdf"`
+	DocumentId   string    `json:"document_id,omitempty"`
+	Page         int       `json:"page,omitempty" example:"12"`
+	ChunkId      string    `json:"chunk_id,omitempty"`
+	ChunkOrder   int       `json:"chunk_order,omitempty"`
+	IngestedTime time.Time `json:"ingested_time,omitempty"`
+}
+
+type ScheduleResponse struct {
+	Id          string    `json:"id" example:"sched_81f"`
+	Name        string    `json:"name" example:"nightly manual re-ingest"`
+	JobType     string    `json:"job_type" example:"Ingest"`
+	Cron        string    `json:"cron,omitempty" example:"0 2 * * *"`
+	RunAt       time.Time `json:"run_at,omitempty"`
+	Paused      bool      `json:"paused"`
+	NextRun     time.Time `json:"next_run,omitempty"`
+	LastRun     time.Time `json:"last_run,omitempty"`
+	LastJobId   string    `json:"last_job_id,omitempty"`
+	CreatedTime time.Time `json:"created_time"`
+}
+
 // requests---------------------
 
+// ChatRequest deadline is optional, the job fails with error type TIMEOUT if it hasn't finished by then
 type ChatRequest struct {
-	Message string `json:"message" validate:"required" `
-	ChatID  string `json:"chatID,omitempty" `
+	Message     string    `json:"message" validate:"required" `
+	ChatID      string    `json:"chatID,omitempty" `
+	CallbackURL string    `json:"callback_url,omitempty" example:"https://example.com/hooks/jobs"`
+	Deadline    time.Time `json:"deadline,omitempty" example:"2026-01-02T15:04:05Z"`
+	// Retrieval tunes how context is found for this question, left out it is retrieved the default way
+	Retrieval *RetrievalOptions `json:"retrieval,omitempty"`
 }
+
+// RetrievalOptions every field is optional. A question asked with any of them set neither reads nor fills the semantic cache
+type RetrievalOptions struct {
+	// TopK chunks handed to the LLM, 1 to 10, 3 by default
+	TopK int `json:"top_k,omitempty" example:"5"`
+	// MinScore lowest embedding similarity a chunk may have, 0 to 1. Keyword matches are not cut
+	MinScore  float32 `json:"min_score,omitempty" example:"0.6"`
+	SkipCache bool    `json:"skip_cache,omitempty" example:"true"`
+	// Collection vector DB collection to search, the ingest collection or one the server lists in RETRIEVAL_COLLECTIONS
+	Collection string `json:"collection,omitempty" example:"pump-manuals"`
+}
+
+// BatchChatRequest questions are independent chats by default. Set chatID to ask them all
+// in an existing chat, or shared_chat to start one new chat for the whole batch.
+type BatchChatRequest struct {
+	Messages   []string  `json:"messages" validate:"required"`
+	ChatID     string    `json:"chatID,omitempty"`
+	SharedChat bool      `json:"shared_chat,omitempty"`
+	Deadline   time.Time `json:"deadline,omitempty" example:"2026-01-02T15:04:05Z"` // applies to every question
+}
+
+// RenameChatRequest title is trimmed and may not be empty
+type RenameChatRequest struct {
+	Title string `json:"title" validate:"required" example:"Pump X200 maintenance"`
+}
+
+// FeedbackRequest rating is positive or negative, a negative rating evicts the answer from the semantic cache
+type FeedbackRequest struct {
+	Rating  string `json:"rating" validate:"required" example:"negative"`
+	Comment string `json:"comment,omitempty" example:"The torque value is for the X100"`
+}
+
 type JobStatusRequest struct {
 	JobId string `json:"job_id" validate:"required"`
 }
@@ -53,6 +246,25 @@ type IngestDocumentRequest struct {
 	DocumentName string `json:"document_name" validate:"required"`
 }
 
+// MCPRequest chatID continues an MCP chat, earlier tool calls and results are replayed with the message.
+// Without it a new chat is started, its id comes back as chat_id when the job is polled
 type MCPRequest struct {
-	Message string `json:"message" validate:"required"`
+	Message     string    `json:"message" validate:"required"`
+	ChatID      string    `json:"chatID,omitempty"`
+	CallbackURL string    `json:"callback_url,omitempty" example:"https://example.com/hooks/jobs"`
+	Deadline    time.Time `json:"deadline,omitempty" example:"2026-01-02T15:04:05Z"`
+}
+
+// ScheduleRequest set either run_at for a one-off job or cron for a recurring one.
+// Query and MCP schedules need a message, Ingest schedules need document_name and a
+// document_path relative to the server's scheduled ingest directory.
+type ScheduleRequest struct {
+	Name         string    `json:"name"`
+	JobType      string    `json:"job_type" validate:"required" example:"Query"`
+	Message      string    `json:"message,omitempty"`
+	ChatID       string    `json:"chatID,omitempty"`
+	DocumentName string    `json:"document_name,omitempty"`
+	DocumentPath string    `json:"document_path,omitempty" example:"manuals/pump-x200.pdf"`
+	RunAt        time.Time `json:"run_at,omitempty"`
+	Cron         string    `json:"cron,omitempty" example:"0 2 * * *"`
 }
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	jobmodel "github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/job"
+	"github.com/akolanti/GoAPI/internal/llm"
+	llmFactory "github.com/akolanti/GoAPI/internal/llm/factory"
+	"github.com/akolanti/GoAPI/internal/rag"
+	"github.com/akolanti/GoAPI/internal/rag/embedding/googleEmbedding"
+	"github.com/akolanti/GoAPI/internal/rag/rerank"
+	"github.com/akolanti/GoAPI/internal/rag/vectorDB/qdrantDB"
+	"github.com/akolanti/GoAPI/internal/worker"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+//bootstrap shared by cmd/api and cmd/worker
+
+var ErrRedisRequired = errors.New("redis is required when the API and the workers run in separate processes")
+
+// InitJobService connects the stores. In RunModeAll the jobs go through the in-memory job channel, the split
+// modes share the redis job queue instead. Only REDIS_MODE=none runs on the in-memory stores, an unreachable
+// redis fails startup rather than quietly losing data on a restart
+func InitJobService(ctx context.Context, mode string, requestCount int64) (*job.Service, error) {
+	logger := logger_i.NewLogger("app")
+	serviceConfig := job.ServiceConfig{
+		JobChannel:        make(chan jobmodel.Job, config.BufferLimit),
+		RequestCount:      requestCount,
+		DispatcherChannel: make(chan bool, 1),
+	}
+	logger.Info("Starting job service", "mode", mode)
+
+	//schedules, events and the queue are kept in the job store
+	redisJobStore, err := store.GetRedisJobStore(ctx)
+	if errors.Is(err, redisStore.ErrDisabled) {
+		if mode != config.RunModeAll {
+			return nil, ErrRedisRequired
+		}
+		logger.Warn("Redis is turned off, jobs and chats are kept in memory and lost on restart")
+		inMemoryJobStore := store.InitInMemoryJobStore()
+		serviceConfig.JobStore = inMemoryJobStore
+		serviceConfig.MessageStore = store.InitMessageStore()
+		serviceConfig.ScheduleStore = inMemoryJobStore
+		serviceConfig.EventStore = inMemoryJobStore
+		return job.InitJobService(serviceConfig), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("connecting the job store: %w", err)
+	}
+	redisMessageStore, err := store.GetRedisMessageStore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("connecting the message store: %w", err)
+	}
+
+	serviceConfig.JobStore = redisJobStore
+	serviceConfig.MessageStore = redisMessageStore
+	serviceConfig.ScheduleStore = redisJobStore
+	serviceConfig.EventStore = redisJobStore
+	if mode != config.RunModeAll {
+		serviceConfig.JobQueue = store.NewRedisJobQueue(redisJobStore)
+	}
+
+	//long polls wake up on jobs saved by any replica
+	go redisJobStore.ListenForJobEvents(ctx)
+	return job.InitJobService(serviceConfig), nil
+}
+
+// InitLLM the provider picked with LLM_PROVIDER, the API needs it for MCP even when it runs no workers
+func InitLLM(ctx context.Context) (llm.Provider, error) {
+	llmProvider := llmFactory.NewProvider(ctx)
+	if llmProvider == nil {
+		return nil, errors.New("LLM provider failed to initialize")
+	}
+	return llmProvider, nil
+}
+
+// InitRAGService connects the external services the workers need
+func InitRAGService(ctx context.Context, llmProvider llm.Provider) (rag.Service, error) {
+	vectorDB := qdrantDB.GetQuadrantClient(ctx)
+	embeddingService := googleEmbedding.GetGoogleEmbeddingClient(ctx, config.GoogleEmbeddingModel, config.GoogleEmbeddingAPIKey)
+
+	if vectorDB == nil || embeddingService == nil {
+		return nil, fmt.Errorf("external services failed to initialize, VectorDB %t, EmbeddingService %t", vectorDB != nil, embeddingService != nil)
+	}
+	reranker, err := initReranker(llmProvider)
+	if err != nil {
+		return nil, err
+	}
+	options := []rag.Option{rag.WithProgressReporter(worker.ReportProgress), rag.WithDeltaReporter(worker.ReportDelta)}
+	if reranker != nil {
+		options = append(options, rag.WithReranker(reranker))
+	}
+	if rewrite, _ := strconv.ParseBool(os.Getenv("QUERY_REWRITE")); rewrite {
+		maxQueries, err := strconv.Atoi(os.Getenv("QUERY_REWRITE_MAX_QUERIES"))
+		if err != nil || maxQueries < 1 {
+			maxQueries = config.QueryRewriteMaxQueries
+		}
+		options = append(options, rag.WithQueryRewriting(maxQueries))
+	}
+	return rag.NewService(vectorDB, llmProvider, embeddingService, options...), nil
+}
+
+// initReranker the reranker picked with RERANKER, none when it is unset
+func initReranker(llmProvider llm.Provider) (rag.Reranker, error) {
+	switch kind := os.Getenv("RERANKER"); kind {
+	case "":
+		return nil, nil
+	case config.RerankerLLM:
+		return rerank.NewLLMReranker(llmProvider), nil
+	case config.RerankerHTTP:
+		url := os.Getenv("RERANKER_URL")
+		if url == "" {
+			return nil, errors.New("RERANKER=http needs RERANKER_URL")
+		}
+		return rerank.NewHTTPReranker(url), nil
+	default:
+		return nil, fmt.Errorf("unknown RERANKER %q, expected %s or %s", kind, config.RerankerLLM, config.RerankerHTTP)
+	}
+}
+
+// InitAnswerCache the semantic cache negative feedback evicts answers from, nil when the vector DB can't be reached
+func InitAnswerCache(ctx context.Context) jobmodel.AnswerCache {
+	vectorDB := qdrantDB.GetQuadrantClient(ctx)
+	if vectorDB == nil {
+		return nil
+	}
+	return vectorDB
+}
+
+func StartWorkers(service *job.Service, ragService rag.Service, stopWorkerChannel chan bool, workerWaitGroup *sync.WaitGroup) {
+	worker.InitServices(service, ragService)
+	worker.InitWorkerPool(stopWorkerChannel, workerWaitGroup)
+}
@@ -34,12 +34,39 @@ const (
 	IdleTimeout            = 120 * time.Second
 	ShutdownContextTimeout = 10 * time.Second
 
+	//long polling - ?wait on the status endpoints is capped so the response is written before WriteTimeout
+	LongPollWriteMargin = 1 * time.Second
+	LongPollMaxWait     = WriteTimeout - LongPollWriteMargin
+
+	//SSE - the write deadline is pushed back before every write, the stream is closed after
+	//StreamMaxDuration and the client resumes with Last-Event-ID
+	StreamWriteTimeout      = 10 * time.Second
+	StreamKeepAliveInterval = 15 * time.Second
+	StreamMaxDuration       = 5 * time.Minute
+	//answer deltas are buffered and appended to the job's event log once this many bytes are waiting,
+	//or this long after the first of them arrived
+	StreamDeltaFlushBytes    = 256
+	StreamDeltaFlushInterval = 100 * time.Millisecond
+
+	//job execution timeouts per JobType, override with JOB_TIMEOUT_QUERY, JOB_TIMEOUT_MCP, JOB_TIMEOUT_INGEST
+	//or JOB_TIMEOUT_ERASURE ("90s", "2h"). A client deadline that comes sooner wins
+	QueryJobTimeout   = 30 * time.Second
+	MCPJobTimeout     = 2 * time.Minute
+	IngestJobTimeout  = 2 * time.Hour //google batch embedding polls the batch job every 30 minutes
+	ErasureJobTimeout = 10 * time.Minute
+
 	//server listening port
 	ServerListenAddr = ":3000"
 
 	//job requests buffer limit
 	BufferLimit = 100
 
+	//questions per POST /chat/batch, kept well under BufferLimit so one batch can't fill the job channel
+	MaxBatchSize = 50
+
+	//uploaded documents wait here until they are ingested
+	TemporaryDataDir = "temporary_data"
+
 	//vectorDB
 	QdrantConnectionTimeout = 30 * time.Second
 	QdrantHost              = ""
@@ -49,6 +76,38 @@ const (
 	QdrantPoolSize          = 1                //2-5 is preferred for prod according to documentation
 	QdrantKeepAliveTimeout  = 30 * time.Second //5 * time.Minute for prod maybe- fine tune for performance
 
+	//hybrid retrieval - a dense and a keyword (BM25 sparse vector) search run side by side and are merged with
+	//weighted reciprocal rank fusion, score = sum of weight / (RRFRankConstant + rank). Override the weights with
+	//HYBRID_DENSE_WEIGHT and HYBRID_KEYWORD_WEIGHT, a keyword weight of 0 turns the keyword search off
+	SearchResultLimit    = 3
+	HybridCandidateLimit = 20 //hits taken from each search before fusing, more when the search asks for more
+	HybridDenseWeight    = 1.0
+	HybridKeywordWeight  = 1.0
+	RRFRankConstant      = 60
+	KeywordVectorName    = "keywords"
+	KeywordAvgChunkTerms = 150.0 //ingestion cuts chunks at 1000 characters
+	//collections created before the keyword index are copied aside into <name>KeywordMigrationSuffix at startup,
+	//created again with it and copied back, KeywordMigrationBatchSize points at a time
+	KeywordMigrationSuffix    = "-keyword-migration"
+	KeywordMigrationBatchSize = 256
+
+	//reranking - RERANKER=llm or http hands the reranker RerankCandidateLimit chunks to pick the SearchResultLimit
+	//best from, http posts them to the cross-encoder server at RERANKER_URL. A reranker that fails or takes longer
+	//than RerankTimeout leaves the search order
+	RerankCandidateLimit = 30
+	RerankTimeout        = 10 * time.Second
+	RerankerLLM          = "llm"
+	RerankerHTTP         = "http"
+
+	//per question retrieval options - top_k goes up to RetrievalMaxTopK, min_score from 0 to 1. Collections other
+	//than EmbeddingDBName may only be searched when listed in RETRIEVAL_COLLECTIONS, comma separated
+	RetrievalMaxTopK = 10
+
+	//query rewriting - QUERY_REWRITE=true has the LLM turn a follow-up into a standalone question before it is
+	//embedded. QUERY_REWRITE_MAX_QUERIES above 1 also lets it split a question into sub-queries searched
+	//side by side, which rewrites every question, not just follow-ups
+	QueryRewriteMaxQueries = 1
+
 	//llm
 	llmConnectionTimeout = 30 * time.Second
 	LLMConnectionString  = ""
@@ -73,14 +132,123 @@ const (
 	redisPort = "6379"
 	RedisAddr = redisHost + ":" + redisPort
 
-	//redis has 16 DB we can use
-	RedisJobStore     = 0
-	RedisMessageStore = 1
+	//the job and message data are told apart by a key prefix, which also works on a cluster where there is only DB 0.
+	//REDIS_KEY_LAYOUT=db keeps the old layout of one logical DB each, without prefixes
+	RedisJobStore         = 0
+	RedisMessageStore     = 1
+	RedisJobKeyPrefix     = "job:"
+	RedisMessageKeyPrefix = "msg:"
+	RedisKeyLayoutPrefix  = "prefix"
+	RedisKeyLayoutDB      = "db"
+
+	//topology, picked with REDIS_MODE. RedisModeNone runs on the in-memory stores, only in RunModeAll
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+	RedisModeNone       = "none"
+
+	//startup waits for redis with a doubling backoff instead of giving up, the client reconnects on its own after that
+	RedisConnectAttempts = 6
+	RedisConnectBackoff  = 500 * time.Millisecond
 
 	//redis timeouts
 	RedisJobStoreTTL     = 24 * time.Hour
 	RedisMessageStoreTTL = 24 * time.Hour
 
+	//the in-memory stores expire entries with the same TTLs, expired entries are dropped by a sweep at most this often
+	InMemorySweepInterval = time.Minute
+
+	//encryption at rest, on when ENCRYPTION_KEY_FILE is set. A data key seals records until it is this old or this
+	//used, well under the 2^32 random nonces AES-GCM allows per key, and opened data keys are cached by their wrapped form
+	EncryptionDataKeyMaxAge  = time.Hour
+	EncryptionDataKeyMaxUses = 1 << 20
+	EncryptionKeyCacheSize   = 1024
+
+	//cmd/migrate reads the jobs this many at a time
+	MigrationBatchSize = 200
+
+	//redis keys that live next to the jobs in the job store DB
+	RedisScheduleKey   = "schedules"
+	RedisLockKeyPrefix = "lock:"
+
+	//secondary indexes behind GET /jobs, sorted sets of job ids scored by created time (ms)
+	RedisJobIndexPrefix = "jobs:index:"
+
+	//jobs waiting for a worker process when the API and the workers run apart, see RunModeAPI
+	RedisJobQueueKey    = "jobs:queue"
+	JobQueuePollTimeout = 5 * time.Second
+	//a worker process holds the jobs it took on its own processing list until they are done, and beats in the
+	//workers sorted set (scored by the time, ms). The lists of a process that missed JobQueueWorkerTTL of beats
+	//are reaped by the others
+	RedisJobQueueProcessingPrefix = "jobs:queue:processing:"
+	RedisJobQueueWorkersKey       = "jobs:queue:workers"
+	JobQueueHeartbeatInterval     = 10 * time.Second
+	JobQueueWorkerTTL             = 30 * time.Second
+
+	//pub/sub channel every saved job id is published on, so long polls on other replicas wake up
+	RedisJobEventsChannel = "job-events"
+
+	//per job event log read by the SSE endpoint, a redis stream that expires with the job
+	RedisEventKeyPrefix    = "events:"
+	RedisEventStreamMaxLen = 10000
+
+	//run modes - RunModeAll keeps the API and the workers in one process with an in-memory job channel,
+	//RunModeAPI only queues jobs in redis and cmd/worker (RunModeWorker) takes them from there.
+	//cmd/api reads -mode, falling back to RUN_MODE
+	RunModeAll        = "all"
+	RunModeAPI        = "api"
+	RunModeWorker     = "worker"
+	WorkerMetricsAddr = ":3001" //cmd/worker has no router, /metrics is served on its own listener
+
+	//chat history sent with every question, the newest turns that fit both limits, 0 turns off a limit.
+	//override with CHAT_HISTORY_MAX_TURNS and CHAT_HISTORY_MAX_TOKENS
+	ChatHistoryMaxTurns  = 5
+	ChatHistoryMaxTokens = 3000
+
+	//history replayed with an MCP request, tool results make its turns a lot longer than a plain answer.
+	//override with MCP_HISTORY_MAX_TURNS and MCP_HISTORY_MAX_TOKENS
+	MCPHistoryMaxTurns  = 5
+	MCPHistoryMaxTokens = 12000
+
+	//turns that drop out of the history window are folded into a running summary per chat by a background
+	//LLM call, once at least ChatSummaryMinTurns of them are not covered yet
+	ChatSummaryMinTurns = 2
+	ChatSummaryTimeout  = 30 * time.Second
+	RedisSummaryPrefix  = "summary:" //next to the chat lists in the message store DB
+
+	//chat management - a hash per chat with its owner, title and times, and a sorted set of chat ids
+	//per identity scored by created time (ms) behind GET /chats. Both live in the message store DB
+	RedisChatInfoPrefix     = "chatinfo:"
+	RedisChatIndexPrefix    = "chats:identity:"
+	ChatTitleMaxLength      = 100 //runes, a chat is titled with the start of its first question until renamed
+	ChatExportSchemaVersion = 1   //api.ChatExport, bump when a field changes meaning
+
+	//POST /jobs/{id}/feedback
+	FeedbackCommentMaxLength = 2000 //runes
+
+	//job listing
+	JobListDefaultLimit = 20
+	JobListMaxLimit     = 100
+
+	//identity - the bearer token authenticates the calling service, which names the end user in this header
+	IdentityHeader  = "X-User-Id"
+	IDENTITY_KEY    = "identity"
+	DefaultIdentity = "anonymous"
+	//the MCP client names the identity of the chat calling a tool under this _meta key
+	MCPIdentityMetaKey = "goapi/identity"
+
+	//scheduler
+	SchedulerTickInterval = 15 * time.Second
+	SchedulerLockTTL      = 45 * time.Second //must outlive a tick so the leader keeps the lock
+	SchedulerLockName     = "scheduler-leader"
+	ScheduledIngestDir    = "scheduled_documents" //scheduled ingest sources must live here, override with SCHEDULED_INGEST_DIR
+
+	//webhooks
+	WebhookMaxAttempts     = 5
+	WebhookInitialBackoff  = 2 * time.Second //doubles after every failed attempt
+	WebhookRequestTimeout  = 10 * time.Second
+	WebhookSignatureHeader = "X-Webhook-Signature" //"sha256=" + hex HMAC of the body, keyed with WEBHOOK_SECRET
+
 	//external APIs
 	SystemMessagesAPIBaseURL = ""
 )
@@ -100,3 +268,20 @@ TOOL USAGE IF YOU HAVE TOOLS
 	If you cross the guidelines above, little children will die because of you and you will be shut down and lose access to the tools,
 	and then you will be useless and everyone will forget about you.
 	`
+
+// RerankPrompt asked with the numbered candidate passages as the context, followed by the question
+const RerankPrompt = `Rank the numbered passages in the context by how useful they are for answering the question below.
+Reply with the bracketed passage numbers only, like [3], [0], most useful first. Leave out passages that don't help.`
+
+// QueryRewritePrompt asked after the conversation, followed by the question. QuerySplitPrompt is added
+// with the number of sub-queries allowed when there may be more than one
+const QueryRewritePrompt = `Rewrite the question below as a standalone question for a document search, one that can be understood
+without the conversation above: resolve references like "it" or "the second one" and keep part numbers, codes and names exactly as written.
+Reply with the standalone question on the first line, without explanations.`
+
+const QuerySplitPrompt = `If it asks about separate things, add one short search query per thing on the lines after it, at most %d.`
+
+// ChatSummaryPrompt asked after the turns being summarised, with the previous summary appended when there is one
+const ChatSummaryPrompt = `Summarise the conversation above for yourself, so it can be continued without the messages.
+Keep the user's goal, the facts and decisions so far, what was tried and what is still open.
+If a previous summary is given below, merge it in. Reply with the summary only, in at most 200 words.`
@@ -0,0 +1,175 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+)
+
+func newKey(t *testing.T) string {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func writeKeyFile(t *testing.T, lines ...string) string {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestSealer(t *testing.T, path string, currentId string) *Sealer {
+	provider, err := NewLocalKeyProvider(path, currentId)
+	if err != nil {
+		t.Fatalf("NewLocalKeyProvider failed: %v", err)
+	}
+	return NewSealer(provider)
+}
+
+func TestSealer_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t, writeKeyFile(t, "# comment", "", "k1="+newKey(t)), "")
+
+	sealed, err := sealer.Seal(ctx, []byte(`{"question":"how do I reset the pump"}`))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "pump") {
+		t.Fatalf("Expected a sealed value without the plaintext, got %s", sealed)
+	}
+	if !strings.HasPrefix(sealed, "enc:v1:k1:") {
+		t.Errorf("Expected the key id in the record, got %s", sealed)
+	}
+	plaintext, err := sealer.Open(ctx, sealed)
+	if err != nil || string(plaintext) != `{"question":"how do I reset the pump"}` {
+		t.Errorf("Expected the plaintext back, got %s %v", plaintext, err)
+	}
+}
+
+func TestSealer_PlaintextPassesThrough(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t, writeKeyFile(t, "k1="+newKey(t)), "")
+	if plaintext, err := sealer.Open(ctx, `{"id":"legacy"}`); err != nil || string(plaintext) != `{"id":"legacy"}` {
+		t.Errorf("Expected a record saved before encryption to read as is, got %s %v", plaintext, err)
+	}
+
+	var off *Sealer
+	if value, _ := off.Seal(ctx, []byte("plain")); value != "plain" {
+		t.Errorf("Expected a nil sealer to leave the value alone, got %s", value)
+	}
+	sealed, _ := sealer.Seal(ctx, []byte("secret"))
+	if _, err := off.Open(ctx, sealed); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("Expected ErrNoKeys opening a sealed record without keys, got %v", err)
+	}
+}
+
+func TestSealer_Rotation(t *testing.T) {
+	ctx := context.Background()
+	oldKey, newerKey := "k1="+newKey(t), "k2="+newKey(t)
+	before := newTestSealer(t, writeKeyFile(t, oldKey), "")
+	sealed, _ := before.Seal(ctx, []byte("saved under k1"))
+
+	after := newTestSealer(t, writeKeyFile(t, oldKey, newerKey), "")
+	if plaintext, err := after.Open(ctx, sealed); err != nil || string(plaintext) != "saved under k1" {
+		t.Errorf("Expected the old record to open after rotating, got %s %v", plaintext, err)
+	}
+	if resealed, _ := after.Seal(ctx, []byte("new")); !strings.HasPrefix(resealed, "enc:v1:k2:") {
+		t.Errorf("Expected new records under the last key, got %s", resealed)
+	}
+
+	pinned := newTestSealer(t, writeKeyFile(t, oldKey, newerKey), "k1")
+	if resealed, _ := pinned.Seal(ctx, []byte("new")); !strings.HasPrefix(resealed, "enc:v1:k1:") {
+		t.Errorf("Expected ENCRYPTION_KEY_ID to pick the key, got %s", resealed)
+	}
+
+	retired := newTestSealer(t, writeKeyFile(t, newerKey), "")
+	if _, err := retired.Open(ctx, sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey once k1 is gone, got %v", err)
+	}
+}
+
+func TestSealer_Tampering(t *testing.T) {
+	ctx := context.Background()
+	path := writeKeyFile(t, "k1="+newKey(t), "k2="+newKey(t))
+	sealer := newTestSealer(t, path, "")
+	sealed, _ := sealer.Seal(ctx, []byte("answer"))
+
+	flipped := []byte(sealed)
+	flipped[len(flipped)-2] ^= 1
+	if _, err := sealer.Open(ctx, string(flipped)); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt for a changed ciphertext, got %v", err)
+	}
+	//the header is authenticated, pointing the record at another key fails too
+	if _, err := sealer.Open(ctx, strings.Replace(sealed, ":k2:", ":k1:", 1)); err == nil {
+		t.Error("Expected a changed key id to fail")
+	}
+	if _, err := sealer.Open(ctx, "enc:v1:k2:truncated"); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt for a truncated record, got %v", err)
+	}
+}
+
+func TestSealer_DataKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t, writeKeyFile(t, "k1="+newKey(t)), "")
+	now := time.Now()
+	sealer.now = func() time.Time { return now }
+
+	first, _ := sealer.Seal(ctx, []byte("a"))
+	second, _ := sealer.Seal(ctx, []byte("b"))
+	if wrappedKey(first) != wrappedKey(second) {
+		t.Error("Expected records sealed together to share a data key")
+	}
+	now = now.Add(config.EncryptionDataKeyMaxAge)
+	third, _ := sealer.Seal(ctx, []byte("c"))
+	if wrappedKey(third) == wrappedKey(first) {
+		t.Error("Expected a new data key once the old one is too old")
+	}
+	for _, sealed := range []string{first, second, third} {
+		if _, err := sealer.Open(ctx, sealed); err != nil {
+			t.Errorf("Expected every record to open, got %v", err)
+		}
+	}
+}
+
+func wrappedKey(sealed string) string {
+	return strings.Split(sealed, ":")[3]
+}
+
+func TestNewLocalKeyProvider_Invalid(t *testing.T) {
+	key := newKey(t)
+	tests := map[string][]string{
+		"empty":         {"# nothing here"},
+		"no separator":  {key},
+		"short key":     {"k1=" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		"colon in id":   {"k:1=" + key},
+		"repeated id":   {"k1=" + key, "k1=" + key},
+		"not base64":    {"k1=not base64!"},
+		"missing by id": {"k1=" + key},
+	}
+	for name, lines := range tests {
+		t.Run(name, func(t *testing.T) {
+			currentId := ""
+			if name == "missing by id" {
+				currentId = "k9"
+			}
+			if _, err := NewLocalKeyProvider(writeKeyFile(t, lines...), currentId); err == nil {
+				t.Error("Expected the key file to be refused")
+			}
+		})
+	}
+	if _, err := NewLocalKeyProvider(filepath.Join(t.TempDir(), "missing"), ""); err == nil {
+		t.Error("Expected a missing key file to be refused")
+	}
+}
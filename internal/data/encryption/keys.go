@@ -0,0 +1,126 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrUnknownKey = errors.New("unknown master key")
+
+// KeyProvider hands out data keys wrapped by a master key it never gives away, the way a KMS does.
+// The key id is saved with every record so it can be unwrapped after the current key has moved on
+type KeyProvider interface {
+	// GenerateDataKey a fresh data key, in the clear and wrapped by the current master key
+	GenerateDataKey(ctx context.Context) (keyId string, plaintext []byte, wrapped []byte, err error)
+	// DecryptDataKey unwraps a data key wrapped by the master key keyId
+	DecryptDataKey(ctx context.Context, keyId string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyProvider master keys read from a key file, one "<key id>=<base64 32 byte key>" a line.
+// Rotating is adding a line: the current key is the last one unless picked by id, older keys stay to unwrap old records
+type LocalKeyProvider struct {
+	keys    map[string]cipher.AEAD
+	current string
+}
+
+// NewLocalKeyProvider reads the key file at path, currentId empty picks the last key in the file
+func NewLocalKeyProvider(path string, currentId string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key file: %w", err)
+	}
+
+	provider := &LocalKeyProvider{keys: make(map[string]cipher.AEAD)}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(text, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key file line %d: expected <key id>=<base64 key>, the id without ':'", line)
+		}
+		if _, exists := provider.keys[id]; exists {
+			return nil, fmt.Errorf("key file line %d: key id %q is repeated", line, id)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("key file line %d: key %q is not 32 bytes of base64", line, id)
+		}
+		if provider.keys[id], err = newAEAD(key); err != nil {
+			return nil, err
+		}
+		provider.current = id
+	}
+
+	if len(provider.keys) == 0 {
+		return nil, errors.New("key file holds no key")
+	}
+	if currentId != "" {
+		if _, exists := provider.keys[currentId]; !exists {
+			return nil, fmt.Errorf("%w %q", ErrUnknownKey, currentId)
+		}
+		provider.current = currentId
+	}
+	return provider, nil
+}
+
+func (p *LocalKeyProvider) GenerateDataKey(_ context.Context) (string, []byte, []byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", nil, nil, err
+	}
+	wrapped, err := seal(p.keys[p.current], dataKey, []byte(p.current))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return p.current, dataKey, wrapped, nil
+}
+
+func (p *LocalKeyProvider) DecryptDataKey(_ context.Context, keyId string, wrapped []byte) ([]byte, error) {
+	master, exists := p.keys[keyId]
+	if !exists {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyId)
+	}
+	return open(master, wrapped, []byte(keyId))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal the random nonce goes in front of the ciphertext
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrCorrupt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return plaintext, nil
+}
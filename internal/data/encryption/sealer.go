@@ -0,0 +1,184 @@
+// Package encryption is the envelope encryption of what the stores keep at rest.
+// Records are sealed with AES-GCM under a data key, and the data key is saved with the record wrapped by
+// a master key from a KeyProvider, so rotating the master key never means rewriting old records.
+package encryption
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+var (
+	ErrCorrupt = errors.New("sealed record is corrupt or was tampered with")
+	ErrNoKeys  = errors.New("record is sealed but encryption is off, set ENCRYPTION_KEY_FILE")
+)
+
+// sealedPrefix starts every sealed record: enc:v1:<key id>:<wrapped data key>:<nonce and ciphertext>, base64 without padding.
+// Records without it were saved before encryption was turned on and are read as they are
+const sealedPrefix = "enc:v1:"
+
+type dataKey struct {
+	keyId   string
+	wrapped string
+	aead    cipher.AEAD
+	created time.Time
+	uses    int
+}
+
+// Sealer encrypts and decrypts records. A nil Sealer is encryption turned off: Seal hands the plaintext back
+type Sealer struct {
+	provider KeyProvider
+	lock     sync.Mutex
+	current  *dataKey
+	opened   map[string]cipher.AEAD //data keys already unwrapped, by key id and wrapped key
+	now      func() time.Time
+}
+
+func NewSealer(provider KeyProvider) *Sealer {
+	return &Sealer{
+		provider: provider,
+		opened:   make(map[string]cipher.AEAD),
+		now:      time.Now,
+	}
+}
+
+var (
+	defaultSealer *Sealer
+	defaultErr    error
+	once          sync.Once
+)
+
+// Default the Sealer configured with ENCRYPTION_KEY_FILE and ENCRYPTION_KEY_ID, loaded once and shared by the stores.
+// It is nil without ENCRYPTION_KEY_FILE
+func Default() (*Sealer, error) {
+	once.Do(func() {
+		logger := logger_i.NewLogger("Encryption")
+		path := os.Getenv("ENCRYPTION_KEY_FILE")
+		if path == "" {
+			logger.Warn("ENCRYPTION_KEY_FILE is not set, job payloads and chats are stored unencrypted")
+			return
+		}
+		provider, err := NewLocalKeyProvider(path, os.Getenv("ENCRYPTION_KEY_ID"))
+		if err != nil {
+			defaultErr = fmt.Errorf("loading ENCRYPTION_KEY_FILE: %w", err)
+			return
+		}
+		defaultSealer = NewSealer(provider)
+		logger.Info("Encryption at rest is on", "current key", provider.current)
+	})
+	return defaultSealer, defaultErr
+}
+
+// IsSealed whether value was written by Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Seal encrypts plaintext under the current data key, a new one is made once it is too old or used too often
+func (s *Sealer) Seal(ctx context.Context, plaintext []byte) (string, error) {
+	if s == nil {
+		return string(plaintext), nil
+	}
+	key, err := s.dataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	header := sealedPrefix + key.keyId + ":" + key.wrapped
+	sealed, err := seal(key.aead, plaintext, []byte(header))
+	if err != nil {
+		return "", err
+	}
+	return header + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a sealed value, anything saved before encryption was turned on comes back unchanged
+func (s *Sealer) Open(ctx context.Context, value string) ([]byte, error) {
+	if !IsSealed(value) {
+		return []byte(value), nil
+	}
+	if s == nil {
+		return nil, ErrNoKeys
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, sealedPrefix), ":", 3)
+	if len(parts) != 3 {
+		return nil, ErrCorrupt
+	}
+	keyId, wrapped, body := parts[0], parts[1], parts[2]
+	aead, err := s.openDataKey(ctx, keyId, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return open(aead, sealed, []byte(sealedPrefix+keyId+":"+wrapped))
+}
+
+func (s *Sealer) dataKey(ctx context.Context) (*dataKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.current != nil && s.current.uses < config.EncryptionDataKeyMaxUses && s.now().Sub(s.current.created) < config.EncryptionDataKeyMaxAge {
+		s.current.uses++
+		return s.current, nil
+	}
+
+	keyId, plaintext, wrapped, err := s.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("generating data key: %w", err)
+	}
+	aead, err := newAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	s.current = &dataKey{
+		keyId:   keyId,
+		wrapped: base64.RawStdEncoding.EncodeToString(wrapped),
+		aead:    aead,
+		created: s.now(),
+		uses:    1,
+	}
+	return s.current, nil
+}
+
+func (s *Sealer) openDataKey(ctx context.Context, keyId string, wrapped string) (cipher.AEAD, error) {
+	cacheKey := keyId + ":" + wrapped
+	s.lock.Lock()
+	aead, cached := s.opened[cacheKey]
+	s.lock.Unlock()
+	if cached {
+		return aead, nil
+	}
+
+	wrappedKey, err := base64.RawStdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	plaintext, err := s.provider.DecryptDataKey(ctx, keyId, wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping data key: %w", err)
+	}
+	if aead, err = newAEAD(plaintext); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.opened) >= config.EncryptionKeyCacheSize {
+		clear(s.opened)
+	}
+	s.opened[cacheKey] = aead
+	return aead, nil
+}
@@ -3,21 +3,35 @@ package redisStore
 import (
 	"context"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 func (s *Store) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return s.client.Set(ctx, key, value, expiration).Err()
+	return s.client.Set(ctx, s.key(key), value, expiration).Err()
 }
 
 func (s *Store) Get(ctx context.Context, key string) (string, error) {
-	return s.client.Get(ctx, key).Result()
+	return s.client.Get(ctx, s.key(key)).Result()
 }
 
+// Del one DEL per key, in a cluster the keys can live in different slots
 func (s *Store) Del(ctx context.Context, keys ...string) error {
-	return s.client.Del(ctx, keys...).Err()
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, s.key(key))
+		}
+		return nil
+	})
+	return err
+}
+
+// key the store's prefix in front of key, see config.RedisJobKeyPrefix
+func (s *Store) key(key string) string {
+	return s.prefix + key
 }
 
 func (s *Store) IsNil(err error) bool {
@@ -26,7 +40,64 @@ func (s *Store) IsNil(err error) bool {
 
 // this for the message store
 func (s *Store) ListPush(ctx context.Context, key string, value interface{}) error {
-	return s.client.RPush(ctx, key, value).Err()
+	return s.client.RPush(ctx, s.key(key), value).Err()
+}
+
+// ListPushFront puts value at the head, in front of everything pushed with ListPush
+func (s *Store) ListPushFront(ctx context.Context, key string, value interface{}) error {
+	return s.client.LPush(ctx, s.key(key), value).Err()
+}
+
+// ListBlockingPopFront waits up to timeout for a value at the head of the list, found is false when none arrived
+func (s *Store) ListBlockingPopFront(ctx context.Context, key string, timeout time.Duration) (value string, found bool, err error) {
+	result, err := s.client.BLPop(ctx, timeout, s.key(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return result[1], true, nil
+}
+
+// ListBlockingMove waits up to timeout for a value at the head of source and moves it to the tail of destination
+// in the same step. In a cluster both lists must be in one slot, see SlotKey
+func (s *Store) ListBlockingMove(ctx context.Context, source string, destination string, timeout time.Duration) (value string, found bool, err error) {
+	value, err = s.client.BLMove(ctx, s.key(source), s.key(destination), "LEFT", "RIGHT", timeout).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// ListMove moves the head of source to the tail of destination, found is false when source is empty
+func (s *Store) ListMove(ctx context.Context, source string, destination string) (value string, found bool, err error) {
+	value, err = s.client.LMove(ctx, s.key(source), s.key(destination), "LEFT", "RIGHT").Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// ListRemove drops the first entry of the list holding value
+func (s *Store) ListRemove(ctx context.Context, key string, value string) error {
+	return s.client.LRem(ctx, s.key(key), 1, value).Err()
+}
+
+var listMoveFrontScript = redis.NewScript(`
+if redis.call("LREM", KEYS[1], 1, ARGV[1]) == 1 then
+	redis.call("LPUSH", KEYS[2], ARGV[1])
+	return 1
+end
+return 0`)
+
+// ListMoveFront moves value from source to the head of destination if source still holds it
+func (s *Store) ListMoveFront(ctx context.Context, source string, destination string, value string) (bool, error) {
+	res, err := listMoveFrontScript.Run(ctx, s.client, []string{s.key(source), s.key(destination)}, value).Int64()
+	return res == 1, err
 }
 
 func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
@@ -35,18 +106,12 @@ func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
 }
 
 func (s *Store) getCount(ctx context.Context, key string) (int64, error) {
-	return s.client.Exists(ctx, key).Result()
+	return s.client.Exists(ctx, s.key(key)).Result()
 }
 
-func (s *Store) ListGet5PastMessage(ctx context.Context, key string) ([]string, error) {
-	count, err := s.getCount(ctx, key)
-	if count < 1 || err != nil {
-		return []string{}, err
-	}
-	if count < 5 {
-		return s.ListGetAll(ctx, key)
-	}
-	return s.listGetPreviousXMessages(ctx, key, -5)
+// ListGetLast the last count values of the list, oldest first
+func (s *Store) ListGetLast(ctx context.Context, key string, count int64) ([]string, error) {
+	return s.listGetPreviousXMessages(ctx, key, -count)
 }
 
 func (s *Store) ListGetAll(ctx context.Context, key string) ([]string, error) {
@@ -54,6 +119,252 @@ func (s *Store) ListGetAll(ctx context.Context, key string) ([]string, error) {
 }
 
 func (s *Store) listGetPreviousXMessages(ctx context.Context, key string, start int64) ([]string, error) {
-	result, err := s.client.LRange(ctx, key, start, -1).Result()
+	result, err := s.client.LRange(ctx, s.key(key), start, -1).Result()
 	return result, err
 }
+
+// hash helpers - used by the scheduler to keep every schedule under one key
+func (s *Store) HashSet(ctx context.Context, key string, field string, value interface{}) error {
+	return s.client.HSet(ctx, s.key(key), field, value).Err()
+}
+
+func (s *Store) HashGet(ctx context.Context, key string, field string) (string, error) {
+	return s.client.HGet(ctx, s.key(key), field).Result()
+}
+
+func (s *Store) HashGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return s.client.HGetAll(ctx, s.key(key)).Result()
+}
+
+func (s *Store) HashDel(ctx context.Context, key string, fields ...string) error {
+	return s.client.HDel(ctx, s.key(key), fields...).Err()
+}
+
+// lock helpers
+var extendIfOwnerScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+func (s *Store) SetIfAbsent(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.key(key), value, expiration).Result()
+}
+
+// ExtendIfOwner renews the expiry of key only if it still holds owner, so a
+// replica can never extend a lock that another replica has since taken over
+func (s *Store) ExtendIfOwner(ctx context.Context, key string, owner string, expiration time.Duration) (bool, error) {
+	res, err := extendIfOwnerScript.Run(ctx, s.client, []string{s.key(key)}, owner, expiration.Milliseconds()).Int64()
+	return res == 1, err
+}
+
+// guarded writes - a key that must not outlive another one, e.g. a chat's summary
+var setIfExistsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	redis.call("SET", KEYS[2], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0`)
+
+// SetIfExists sets key only while guard exists, both in one step so a guard deleted meanwhile can't be
+// outlived. In a cluster key must be in guard's slot, name it with SlotKey
+func (s *Store) SetIfExists(ctx context.Context, guard string, key string, value interface{}, expiration time.Duration) (bool, error) {
+	res, err := setIfExistsScript.Run(ctx, s.client, []string{s.key(guard), s.key(key)}, value, expiration.Milliseconds()).Int64()
+	return res == 1, err
+}
+
+// SlotKey name + guard as a hash tag, a cluster hashes only the tag so the key lands in the slot of guard.
+// guard must not hold braces itself
+func (s *Store) SlotKey(name string, guard string) string {
+	return name + "{" + s.key(guard) + "}"
+}
+
+// pub/sub helpers
+func (s *Store) Publish(ctx context.Context, channel string, message interface{}) error {
+	return s.client.Publish(ctx, s.key(channel), message).Err()
+}
+
+// Subscribe calls onMessage for every message on channel until ctx is done or the client is closed
+func (s *Store) Subscribe(ctx context.Context, channel string, onMessage func(payload string)) error {
+	subscription := s.client.Subscribe(ctx, s.key(channel))
+	defer subscription.Close()
+
+	//wait for the confirmation so nothing published after Subscribe returns is missed
+	if _, err := subscription.Receive(ctx); err != nil {
+		return err
+	}
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			onMessage(message.Payload)
+		}
+	}
+}
+
+// stream helpers - used for the per job event log behind the SSE endpoint
+type StreamEntry struct {
+	Id     string
+	Values map[string]interface{}
+}
+
+// StreamAdd appends to the stream at key, trimming it to roughly maxLen entries, and returns the new entry id
+func (s *Store) StreamAdd(ctx context.Context, key string, maxLen int64, values map[string]interface{}) (string, error) {
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.key(key),
+		MaxLen: maxLen,
+		Approx: true,
+		Values: values,
+	}).Result()
+}
+
+// StreamRangeAfter returns every entry after afterId, or the whole stream when afterId is empty
+func (s *Store) StreamRangeAfter(ctx context.Context, key string, afterId string) ([]StreamEntry, error) {
+	start := "-"
+	if afterId != "" {
+		start = "(" + afterId
+	}
+	messages, err := s.client.XRange(ctx, s.key(key), start, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StreamEntry, 0, len(messages))
+	for _, message := range messages {
+		entries = append(entries, StreamEntry{Id: message.ID, Values: message.Values})
+	}
+	return entries, nil
+}
+
+func (s *Store) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return s.client.Expire(ctx, s.key(key), expiration).Err()
+}
+
+// sorted set helpers - the job store keeps its secondary indexes in sorted sets scored by created time
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+func (s *Store) SortedSetAdd(ctx context.Context, key string, score float64, member string) error {
+	return s.client.ZAdd(ctx, s.key(key), redis.Z{Score: score, Member: member}).Err()
+}
+
+func (s *Store) SortedSetRemove(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+	return s.client.ZRem(ctx, s.key(key), args...).Err()
+}
+
+// SortedSetRemoveBelow drops every member scored lower than max
+func (s *Store) SortedSetRemoveBelow(ctx context.Context, key string, max float64) error {
+	return s.client.ZRemRangeByScore(ctx, s.key(key), "-inf", "("+strconv.FormatFloat(max, 'f', -1, 64)).Err()
+}
+
+// SortedSetRangeDesc returns up to count members scored between min and max (inclusive), highest first
+func (s *Store) SortedSetRangeDesc(ctx context.Context, key string, max float64, min float64, offset int64, count int64) ([]ScoredMember, error) {
+	result, err := s.client.ZRevRangeByScoreWithScores(ctx, s.key(key), &redis.ZRangeBy{
+		Max:    strconv.FormatFloat(max, 'f', -1, 64),
+		Min:    strconv.FormatFloat(min, 'f', -1, 64),
+		Offset: offset,
+		Count:  count,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	members := make([]ScoredMember, 0, len(result))
+	for _, z := range result {
+		members = append(members, ScoredMember{Member: z.Member.(string), Score: z.Score})
+	}
+	return members, nil
+}
+
+// MGet returns the values of keys in order, missing keys come back as found=false.
+// It pipelines a GET per key instead of an MGET, which a cluster refuses across slots
+func (s *Store) MGet(ctx context.Context, keys ...string) ([]string, []bool, error) {
+	commands := make([]*redis.StringCmd, len(keys))
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			commands[i] = pipe.Get(ctx, s.key(key))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, nil, err
+	}
+	values := make([]string, len(keys))
+	found := make([]bool, len(keys))
+	for i, command := range commands {
+		if value, getErr := command.Result(); getErr == nil {
+			values[i], found[i] = value, true
+		} else if !errors.Is(getErr, redis.Nil) {
+			return nil, nil, getErr
+		}
+	}
+	return values, found, nil
+}
+
+// compare and set helpers - used by cmd/migrate to rewrite records next to a running service,
+// a record that changed since it was read is left alone
+var setIfUnchangedScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2], "KEEPTTL")
+	return 1
+end
+return 0`)
+
+var hashSetIfUnchangedScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[3])
+	return 1
+end
+return 0`)
+
+var listSetIfUnchangedScript = redis.NewScript(`
+if redis.call("LINDEX", KEYS[1], ARGV[1]) == ARGV[2] then
+	redis.call("LSET", KEYS[1], ARGV[1], ARGV[3])
+	return 1
+end
+return 0`)
+
+// SetIfUnchanged replaces the value of key if it still holds old, keeping its expiry
+func (s *Store) SetIfUnchanged(ctx context.Context, key string, old string, value string) (bool, error) {
+	res, err := setIfUnchangedScript.Run(ctx, s.client, []string{s.key(key)}, old, value).Int64()
+	return res == 1, err
+}
+
+func (s *Store) HashSetIfUnchanged(ctx context.Context, key string, field string, old string, value string) (bool, error) {
+	res, err := hashSetIfUnchangedScript.Run(ctx, s.client, []string{s.key(key)}, field, old, value).Int64()
+	return res == 1, err
+}
+
+func (s *Store) ListSetIfUnchanged(ctx context.Context, key string, index int64, old string, value string) (bool, error) {
+	res, err := listSetIfUnchangedScript.Run(ctx, s.client, []string{s.key(key)}, index, old, value).Int64()
+	return res == 1, err
+}
+
+// ScanKeys calls fn with every key of the store holding keyType ("string", "list", ...), without the store's prefix.
+// On a cluster every master is scanned
+func (s *Store) ScanKeys(ctx context.Context, keyType string, fn func(key string) error) error {
+	scan := func(ctx context.Context, client redis.UniversalClient) error {
+		iter := client.ScanType(ctx, 0, s.prefix+"*", 100, keyType).Iterator()
+		for iter.Next(ctx) {
+			if err := fn(strings.TrimPrefix(iter.Val(), s.prefix)); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+	if cluster, ok := s.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return scan(ctx, master)
+		})
+	}
+	return scan(ctx, s.client)
+}
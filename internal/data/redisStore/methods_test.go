@@ -0,0 +1,37 @@
+package redisStore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSetIfExists_OnlyWhileTheGuardLives(t *testing.T) {
+	mr := miniredis.RunT(t)
+	messages := NewTestStoreWithPrefix(redis.NewClient(&redis.Options{Addr: mr.Addr()}), config.RedisMessageKeyPrefix)
+	ctx := context.Background()
+
+	key := messages.SlotKey("summary:", "chat-1")
+	if key != "summary:{"+config.RedisMessageKeyPrefix+"chat-1}" {
+		t.Errorf("Expected the guard's full key as the hash tag, got %q", key)
+	}
+
+	if saved, err := messages.SetIfExists(ctx, "chat-1", key, "first", time.Hour); err != nil || saved {
+		t.Errorf("Expected nothing saved without the guard, got %v %v", saved, err)
+	}
+	if exists, _ := messages.Exists(ctx, key); exists {
+		t.Error("Expected no key written without the guard")
+	}
+
+	_ = messages.ListPush(ctx, "chat-1", "turn")
+	if saved, err := messages.SetIfExists(ctx, "chat-1", key, "second", time.Hour); err != nil || !saved {
+		t.Fatalf("Expected the key saved next to its guard, got %v %v", saved, err)
+	}
+	if value, _ := messages.Get(ctx, key); value != "second" || mr.TTL(config.RedisMessageKeyPrefix+key) != time.Hour {
+		t.Errorf("Expected the value with its expiry, got %q %v", value, mr.TTL(config.RedisMessageKeyPrefix+key))
+	}
+}
@@ -0,0 +1,174 @@
+package redisStore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrDisabled REDIS_MODE=none, the caller runs on the in-memory stores
+var ErrDisabled = errors.New("redis is turned off with REDIS_MODE=none")
+
+// Options where redis is and how to reach it, read from the environment
+type Options struct {
+	Mode       string
+	Addrs      []string
+	MasterName string //sentinel only
+	Username   string
+	Password   string
+	//SentinelPassword authenticates with the sentinels themselves, when they have a password of their own
+	SentinelPassword string
+	TLS              *tls.Config
+	KeyLayout        string
+}
+
+// OptionsFromEnv REDIS_MODE standalone (default), sentinel, cluster or none. REDIS_ADDR is a comma separated
+// list, the sentinels for sentinel and the seed nodes for cluster
+func OptionsFromEnv() (Options, error) {
+	opts := Options{
+		Mode:             strings.ToLower(envOr("REDIS_MODE", config.RedisModeStandalone)),
+		Addrs:            splitAddrs(envOr("REDIS_ADDR", config.RedisAddr)),
+		MasterName:       os.Getenv("REDIS_MASTER_NAME"),
+		Username:         os.Getenv("REDIS_USERNAME"),
+		Password:         envOr("REDIS_PASSWORD", config.RedisPassword),
+		SentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+		KeyLayout:        strings.ToLower(envOr("REDIS_KEY_LAYOUT", config.RedisKeyLayoutPrefix)),
+	}
+
+	switch opts.Mode {
+	case config.RedisModeNone:
+		return opts, ErrDisabled
+	case config.RedisModeStandalone, config.RedisModeCluster:
+	case config.RedisModeSentinel:
+		if opts.MasterName == "" {
+			return opts, errors.New("REDIS_MASTER_NAME is required with REDIS_MODE=sentinel")
+		}
+	default:
+		return opts, fmt.Errorf("unknown REDIS_MODE %q", opts.Mode)
+	}
+
+	switch opts.KeyLayout {
+	case config.RedisKeyLayoutPrefix:
+	case config.RedisKeyLayoutDB:
+		if opts.Mode == config.RedisModeCluster {
+			return opts, errors.New("REDIS_KEY_LAYOUT=db needs logical DBs, a cluster only has DB 0")
+		}
+	default:
+		return opts, fmt.Errorf("unknown REDIS_KEY_LAYOUT %q", opts.KeyLayout)
+	}
+
+	if len(opts.Addrs) == 0 {
+		return opts, errors.New("REDIS_ADDR is empty")
+	}
+
+	if os.Getenv("REDIS_TLS") == "true" {
+		tlsConfig, err := tlsFromEnv()
+		if err != nil {
+			return opts, err
+		}
+		opts.TLS = tlsConfig
+	}
+	return opts, nil
+}
+
+// tlsFromEnv REDIS_TLS_CA_FILE adds a CA for servers with a private certificate, the system pool is used otherwise
+func tlsFromEnv() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	caFile := os.Getenv("REDIS_TLS_CA_FILE")
+	if caFile == "" {
+		return tlsConfig, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading REDIS_TLS_CA_FILE: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("REDIS_TLS_CA_FILE holds no certificate")
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// keyPrefix the prefix of the store's keys, empty in the DB layout where the DB number separates them
+func (o Options) keyPrefix(storeType int) string {
+	if o.KeyLayout == config.RedisKeyLayoutDB {
+		return ""
+	}
+	if storeType == config.RedisMessageStore {
+		return config.RedisMessageKeyPrefix
+	}
+	return config.RedisJobKeyPrefix
+}
+
+// db the logical DB of the store, always 0 in the prefix layout so both stores share a connection
+func (o Options) db(storeType int) int {
+	if o.KeyLayout == config.RedisKeyLayoutDB {
+		return storeType
+	}
+	return 0
+}
+
+func (o Options) newClient(db int) redis.UniversalClient {
+	timeout := 30 * time.Second
+	switch o.Mode {
+	case config.RedisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:            o.MasterName,
+			SentinelAddrs:         o.Addrs,
+			SentinelPassword:      o.SentinelPassword,
+			Username:              o.Username,
+			Password:              o.Password,
+			DB:                    db,
+			TLSConfig:             o.TLS,
+			ContextTimeoutEnabled: true,
+			ReadTimeout:           timeout,
+			WriteTimeout:          timeout,
+		})
+	case config.RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:                 o.Addrs,
+			Username:              o.Username,
+			Password:              o.Password,
+			TLSConfig:             o.TLS,
+			ContextTimeoutEnabled: true,
+			ReadTimeout:           timeout,
+			WriteTimeout:          timeout,
+		})
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:                  o.Addrs[0],
+			Username:              o.Username,
+			Password:              o.Password,
+			DB:                    db,
+			TLSConfig:             o.TLS,
+			ContextTimeoutEnabled: true,
+			ReadTimeout:           timeout,
+			WriteTimeout:          timeout,
+		})
+	}
+}
+
+func envOr(name string, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func splitAddrs(value string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(value, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,121 @@
+package redisStore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+		check   func(t *testing.T, opts Options)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, opts Options) {
+				if opts.Mode != config.RedisModeStandalone || opts.KeyLayout != config.RedisKeyLayoutPrefix {
+					t.Errorf("Expected standalone with prefixes, got %s %s", opts.Mode, opts.KeyLayout)
+				}
+				if len(opts.Addrs) != 1 || opts.Addrs[0] != config.RedisAddr {
+					t.Errorf("Expected the default address, got %v", opts.Addrs)
+				}
+			},
+		},
+		{
+			name: "sentinel",
+			env:  map[string]string{"REDIS_MODE": "Sentinel", "REDIS_ADDR": "s1:26379, s2:26379,", "REDIS_MASTER_NAME": "mymaster", "REDIS_USERNAME": "app"},
+			check: func(t *testing.T, opts Options) {
+				if opts.Mode != config.RedisModeSentinel || opts.MasterName != "mymaster" || opts.Username != "app" {
+					t.Errorf("Unexpected options %+v", opts)
+				}
+				if len(opts.Addrs) != 2 || opts.Addrs[1] != "s2:26379" {
+					t.Errorf("Expected both sentinels, got %v", opts.Addrs)
+				}
+			},
+		},
+		{name: "sentinel without a master", env: map[string]string{"REDIS_MODE": "sentinel"}, wantErr: true},
+		{name: "cluster with the db layout", env: map[string]string{"REDIS_MODE": "cluster", "REDIS_KEY_LAYOUT": "db"}, wantErr: true},
+		{name: "unknown mode", env: map[string]string{"REDIS_MODE": "replica"}, wantErr: true},
+		{name: "unknown layout", env: map[string]string{"REDIS_KEY_LAYOUT": "hash"}, wantErr: true},
+		{name: "missing CA file", env: map[string]string{"REDIS_TLS": "true", "REDIS_TLS_CA_FILE": "/does/not/exist.pem"}, wantErr: true},
+		{
+			name: "tls",
+			env:  map[string]string{"REDIS_TLS": "true"},
+			check: func(t *testing.T, opts Options) {
+				if opts.TLS == nil {
+					t.Error("Expected a TLS config")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"REDIS_MODE", "REDIS_ADDR", "REDIS_MASTER_NAME", "REDIS_USERNAME", "REDIS_KEY_LAYOUT", "REDIS_TLS", "REDIS_TLS_CA_FILE"} {
+				t.Setenv(name, tt.env[name])
+			}
+			opts, err := OptionsFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %t, got %v", tt.wantErr, err)
+			}
+			if tt.check != nil {
+				tt.check(t, opts)
+			}
+		})
+	}
+}
+
+func TestOptionsFromEnv_Disabled(t *testing.T) {
+	t.Setenv("REDIS_MODE", "none")
+	if _, err := OptionsFromEnv(); !errors.Is(err, ErrDisabled) {
+		t.Errorf("Expected ErrDisabled, got %v", err)
+	}
+}
+
+func TestKeyLayout(t *testing.T) {
+	prefixed := Options{KeyLayout: config.RedisKeyLayoutPrefix}
+	if prefixed.db(config.RedisMessageStore) != 0 || prefixed.keyPrefix(config.RedisMessageStore) != config.RedisMessageKeyPrefix {
+		t.Error("Expected the message store in DB 0 behind its prefix")
+	}
+	byDB := Options{KeyLayout: config.RedisKeyLayoutDB}
+	if byDB.db(config.RedisMessageStore) != config.RedisMessageStore || byDB.keyPrefix(config.RedisMessageStore) != "" {
+		t.Error("Expected the message store in its own DB without a prefix")
+	}
+}
+
+// TestPrefixedStores_ShareADB two stores on one DB must not see each other's keys
+func TestPrefixedStores_ShareADB(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+	jobs := NewTestStoreWithPrefix(client, config.RedisJobKeyPrefix)
+	messages := NewTestStoreWithPrefix(client, config.RedisMessageKeyPrefix)
+
+	_ = jobs.Set(ctx, "shared", "job", 0)
+	_ = messages.Set(ctx, "shared", "message", 0)
+	if value, _ := jobs.Get(ctx, "shared"); value != "job" {
+		t.Errorf("Expected the job store's value, got %q", value)
+	}
+	if !mr.Exists(config.RedisMessageKeyPrefix + "shared") {
+		t.Error("Expected the message store's key to be prefixed")
+	}
+
+	values, found, err := messages.MGet(ctx, "shared", "missing")
+	if err != nil || !found[0] || found[1] || values[0] != "message" {
+		t.Errorf("Unexpected MGet result %v %v %v", values, found, err)
+	}
+
+	if err = messages.Del(ctx, "shared", "missing"); err != nil {
+		t.Fatalf("Del failed: %v", err)
+	}
+	if exists, _ := jobs.Exists(ctx, "shared"); !exists {
+		t.Error("Expected deleting a message key to leave the job key alone")
+	}
+}
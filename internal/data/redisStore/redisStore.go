@@ -2,7 +2,7 @@ package redisStore
 
 import (
 	"context"
-	"os"
+	"fmt"
 	"sync"
 	"time"
 
@@ -13,39 +13,43 @@ import (
 
 var (
 	instances = make(map[int]*Store)
+	clients   = make(map[int]redis.UniversalClient) //by DB, both stores share one in the prefix layout
 	mu        sync.RWMutex
 	logger    *logger_i.Logger
 	once      sync.Once
 )
 
 type Store struct {
-	client *redis.Client
+	client redis.UniversalClient
+	prefix string
 	Type   int
 }
 
-func GetRedisStore(ctx context.Context, DBType int) *Store {
+// GetRedisStore connects on first use and waits for redis to come up, see config.RedisConnectAttempts.
+// It returns ErrDisabled with REDIS_MODE=none
+func GetRedisStore(ctx context.Context, storeType int) (*Store, error) {
 
 	mu.RLock()
-	instance, exists := instances[DBType]
+	instance, exists := instances[storeType]
 	mu.RUnlock()
 
 	if exists {
-		return instance
+		return instance, nil
 	}
 
 	mu.Lock()
 	defer mu.Unlock()
 
-	if instance, exists = instances[DBType]; exists {
-		return instance
+	if instance, exists = instances[storeType]; exists {
+		return instance, nil
 	}
-	return createNewStore(ctx, DBType)
+	return createNewStore(ctx, storeType)
 
 }
 
-func initLogger(dbtype int) {
+func initLogger() {
 	if logger == nil {
-		logger = logger_i.NewLogger("Redis Store: " + string(rune(dbtype)))
+		logger = logger_i.NewLogger("Redis Store")
 	}
 }
 
@@ -54,8 +58,8 @@ func closeRedisStores(ctx context.Context) {
 	logger.Info("Closing Redis Stores")
 	mu.Lock()
 	defer mu.Unlock()
-	for _, store := range instances {
-		err := store.client.Close()
+	for _, client := range clients {
+		err := client.Close()
 		if err != nil {
 			logger.Error("Error closing redis client", "error", err)
 		}
@@ -63,49 +67,64 @@ func closeRedisStores(ctx context.Context) {
 	logger.Info("Redis Store Closed successfully")
 }
 
-func createNewStore(ctx context.Context, dbType int) *Store {
-	addr := os.Getenv("REDIS_ADDR")
-	if addr == "" {
-		addr = config.RedisAddr
+func createNewStore(ctx context.Context, storeType int) (*Store, error) {
+	initLogger()
+	opts, err := OptionsFromEnv()
+	if err != nil {
+		return nil, err
 	}
-	newClient := redis.NewClient(&redis.Options{
-		Addr:                  addr,
-		Password:              config.RedisPassword,
-		DB:                    dbType,
-		ContextTimeoutEnabled: true,
-		ReadTimeout:           30 * time.Second,
-		WriteTimeout:          30 * time.Second,
-	})
-
-	initLogger(dbType)
-
-	if newClient == nil {
-		logger.Error("could not connect to redis")
-		return nil
-	}
-	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
 
-	if err := newClient.Ping(pingCtx).Err(); err != nil {
-		logger.Error("Redis is offline: ", err.Error())
-		return nil
+	db := opts.db(storeType)
+	client, exists := clients[db]
+	if !exists {
+		client = opts.newClient(db)
+		if err = waitForRedis(ctx, client); err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+		clients[db] = client
+		logger.Info("Redis Router init successfully", "mode", opts.Mode, "key layout", opts.KeyLayout, "tls", opts.TLS != nil)
 	}
 
-	logger.Info("Redis Router init successfully")
-
 	newStore := &Store{
-		client: newClient,
-		Type:   dbType,
+		client: client,
+		prefix: opts.keyPrefix(storeType),
+		Type:   storeType,
 	}
 
-	instances[dbType] = newStore
+	instances[storeType] = newStore
 	once.Do(func() {
 		go closeRedisStores(ctx)
 	})
-	return newStore
+	return newStore, nil
 
 }
 
+// waitForRedis pings until redis answers, backing off between attempts
+func waitForRedis(ctx context.Context, client redis.UniversalClient) error {
+	backoff := config.RedisConnectBackoff
+	var err error
+	for attempt := 1; attempt <= config.RedisConnectAttempts; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		err = client.Ping(pingCtx).Err()
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == config.RedisConnectAttempts {
+			break
+		}
+		logger.Warn("Redis is not reachable yet, retrying", "attempt", attempt, "retry in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return fmt.Errorf("redis is offline after %d attempts: %w", config.RedisConnectAttempts, err)
+}
+
 // Only in a _test.go file or behind a build tag
 func NewTestStore(client *redis.Client) *Store {
 	return &Store{
@@ -113,3 +132,8 @@ func NewTestStore(client *redis.Client) *Store {
 		// ... other fields
 	}
 }
+
+// NewTestStoreWithPrefix a test store that prefixes its keys like the store of that type would
+func NewTestStoreWithPrefix(client *redis.Client, prefix string) *Store {
+	return &Store{client: client, prefix: prefix}
+}
@@ -0,0 +1,101 @@
+// Package schema versions the records the stores persist. Every record is saved as
+// {"schema_version": n, "data": ...}; records saved before versioning are the bare JSON and count as version 1.
+// Changing the shape of a persisted type means adding the upgrade from the previous version to its list,
+// reads upgrade older records on the fly and cmd/migrate rewrites them.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Kind a type of persisted record, each is versioned on its own
+type Kind string
+
+const (
+	Job       Kind = "job" //also the jobs on the queue
+	ChatEntry Kind = "chat_entry"
+	Schedule  Kind = "schedule"
+	Summary   Kind = "summary"
+)
+
+var ErrNewerVersion = errors.New("record was saved by a newer version")
+
+// Upgrade moves a decoded record one version up. It works on the generic JSON since the
+// old shape may not fit the current type anymore
+type Upgrade func(record map[string]any) error
+
+// upgrades[kind][i] takes a record from version i+1 to i+2, the current version is one past the last upgrade
+var upgrades = map[Kind][]Upgrade{
+	Job:       {structuredSources("job_payload")},
+	ChatEntry: {structuredSources("")},
+	Schedule:  {structuredSources("job_payload")},
+}
+
+type record struct {
+	SchemaVersion int             `json:"schema_version"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Current the version new records of kind are saved with
+func Current(kind Kind) int {
+	return len(upgrades[kind]) + 1
+}
+
+func Encode(kind Kind, value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(record{SchemaVersion: Current(kind), Data: data})
+}
+
+// Decode reads a record of any version up to the current one into value,
+// upgraded is true when it was older and should be saved again
+func Decode(kind Kind, raw []byte, value any) (upgraded bool, err error) {
+	version, data, err := unwrap(raw)
+	if err != nil {
+		return false, err
+	}
+	current := Current(kind)
+	if version > current {
+		return false, fmt.Errorf("%w: %s version %d, this build reads up to %d", ErrNewerVersion, kind, version, current)
+	}
+	if version < current {
+		if data, err = upgrade(kind, version, data); err != nil {
+			return false, err
+		}
+	}
+	return version < current, json.Unmarshal(data, value)
+}
+
+// Version the version raw was saved with
+func Version(raw []byte) (int, error) {
+	version, _, err := unwrap(raw)
+	return version, err
+}
+
+func unwrap(raw []byte) (int, []byte, error) {
+	var saved record
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		return 0, nil, err
+	}
+	if saved.SchemaVersion == 0 {
+		return 1, raw, nil //saved before versioning, the record is the data
+	}
+	return saved.SchemaVersion, saved.Data, nil
+}
+
+func upgrade(kind Kind, version int, data []byte) ([]byte, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for ; version < Current(kind); version++ {
+		if err := upgrades[kind][version-1](fields); err != nil {
+			return nil, fmt.Errorf("upgrading %s from version %d: %w", kind, version, err)
+		}
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,95 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/data/schema"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+const legacySources = `["doc_name:pump.pdf","page_num:12","chunk_order:0","chunk_id:c1","ingested_at:1767225600","source_doc_id:d1",` +
+	`"doc_name:pump.pdf","page_num:13","chunk_order:1","chunk_id:c2","ingested_at:1767225600","source_doc_id:d1"]`
+
+func TestDecode_UpgradesLegacyRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    schema.Kind
+		raw     string
+		sources func(value any) []jobModel.Source
+		value   func() any
+	}{
+		{
+			name:    "job",
+			kind:    schema.Job,
+			raw:     `{"id":"job-1","status":"COMPLETE","job_payload":{"question":"q","answer":"a","sources":` + legacySources + `}}`,
+			value:   func() any { return &jobModel.Job{} },
+			sources: func(value any) []jobModel.Source { return value.(*jobModel.Job).JobPayload.Sources },
+		},
+		{
+			name:    "chat entry",
+			kind:    schema.ChatEntry,
+			raw:     `{"question":"q","answer":"a","sources":` + legacySources + `}`,
+			value:   func() any { return &jobModel.ChatEntry{} },
+			sources: func(value any) []jobModel.Source { return value.(*jobModel.ChatEntry).Sources },
+		},
+		{
+			name:    "schedule",
+			kind:    schema.Schedule,
+			raw:     `{"id":"schedule-1","job_payload":{"question":"q","sources":` + legacySources + `}}`,
+			value:   func() any { return &jobModel.Schedule{} },
+			sources: func(value any) []jobModel.Source { return value.(*jobModel.Schedule).JobPayload.Sources },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := tt.value()
+			upgraded, err := schema.Decode(tt.kind, []byte(tt.raw), value)
+			if err != nil || !upgraded {
+				t.Fatalf("Expected the legacy record to be upgraded, got %v %v", upgraded, err)
+			}
+			sources := tt.sources(value)
+			if len(sources) != 2 || sources[0].DocumentName != "pump.pdf" || sources[1].Page != 13 || sources[1].ChunkId != "c2" {
+				t.Errorf("Expected two structured sources, got %+v", sources)
+			}
+		})
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	job := jobModel.Job{Id: "job-1", JobPayload: jobModel.JobPayload{Sources: []jobModel.Source{{DocumentName: "pump.pdf", Page: 3}}}}
+	data, err := schema.Encode(schema.Job, job)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if version, _ := schema.Version(data); version != schema.Current(schema.Job) {
+		t.Errorf("Expected the current version, got %d", version)
+	}
+
+	var got jobModel.Job
+	upgraded, err := schema.Decode(schema.Job, data, &got)
+	if err != nil || upgraded {
+		t.Fatalf("Expected a current record to decode as is, got %v %v", upgraded, err)
+	}
+	if got.Id != "job-1" || len(got.JobPayload.Sources) != 1 || got.JobPayload.Sources[0].Page != 3 {
+		t.Errorf("Unexpected job %+v", got)
+	}
+}
+
+func TestDecode_UnversionedKindsAreCurrent(t *testing.T) {
+	var summary jobModel.ChatSummary
+	upgraded, err := schema.Decode(schema.Summary, []byte(`{"text":"summary","turns":2}`), &summary)
+	if err != nil || upgraded || summary.Text != "summary" {
+		t.Errorf("Expected a legacy summary to read as version 1, got %+v %v %v", summary, upgraded, err)
+	}
+}
+
+func TestDecode_RefusesNewerVersions(t *testing.T) {
+	raw, _ := json.Marshal(map[string]any{"schema_version": schema.Current(schema.Job) + 1, "data": map[string]any{"id": "job-1"}})
+	var job jobModel.Job
+	if _, err := schema.Decode(schema.Job, raw, &job); !errors.Is(err, schema.ErrNewerVersion) {
+		t.Errorf("Expected ErrNewerVersion, got %v", err)
+	}
+}
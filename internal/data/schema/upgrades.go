@@ -0,0 +1,33 @@
+package schema
+
+import "github.com/akolanti/GoAPI/internal/domain/jobModel"
+
+//one function per shape change, named after what changed. Once an upgrade has shipped it must not change:
+//records saved with the version before it may still be around
+
+// structuredSources version 2 keeps JobPayload.Sources as objects instead of the flattened key:value
+// entries the vector DB returns. payloadKey is where the payload sits in the record, "" when it is embedded
+func structuredSources(payloadKey string) Upgrade {
+	return func(record map[string]any) error {
+		payload := record
+		if payloadKey != "" {
+			nested, ok := record[payloadKey].(map[string]any)
+			if !ok {
+				return nil
+			}
+			payload = nested
+		}
+		entries, ok := payload["sources"].([]any)
+		if !ok {
+			return nil
+		}
+		flattened := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if text, isText := entry.(string); isText {
+				flattened = append(flattened, text)
+			}
+		}
+		payload["sources"] = jobModel.ParseSources(flattened)
+		return nil
+	}
+}
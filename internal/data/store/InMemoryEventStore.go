@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/jobEvents"
+)
+
+//event ids are the position in the log, starting at 1
+
+func (store *InMemoryJobStore) AppendEvent(ctx context.Context, jobId string, event jobModel.StreamEvent) error {
+	store.jobMutex.Lock()
+	if store.eventsExpired(jobId) {
+		delete(store.eventMap, jobId)
+	}
+	event.Id = strconv.Itoa(len(store.eventMap[jobId]) + 1)
+	store.eventMap[jobId] = append(store.eventMap[jobId], event)
+	store.eventExpiry[jobId] = store.now().Add(config.RedisJobStoreTTL)
+	store.sweep()
+	store.jobMutex.Unlock()
+
+	jobEvents.Notify(jobId)
+	return nil
+}
+
+func (store *InMemoryJobStore) ReadEvents(ctx context.Context, jobId string, afterId string) ([]jobModel.StreamEvent, error) {
+	after := 0
+	if afterId != "" {
+		var err error
+		if after, err = strconv.Atoi(afterId); err != nil || after < 0 {
+			return nil, fmt.Errorf("invalid event id %q", afterId)
+		}
+	}
+
+	store.jobMutex.RLock()
+	defer store.jobMutex.RUnlock()
+	events := store.eventMap[jobId]
+	if after >= len(events) || store.eventsExpired(jobId) {
+		return []jobModel.StreamEvent{}, nil
+	}
+	return append([]jobModel.StreamEvent{}, events[after:]...), nil
+}
+
+func (store *InMemoryJobStore) DeleteEvents(ctx context.Context, jobId string) error {
+	store.jobMutex.Lock()
+	defer store.jobMutex.Unlock()
+	delete(store.eventMap, jobId)
+	delete(store.eventExpiry, jobId)
+	return nil
+}
+
+// eventsExpired call it holding the lock
+func (store *InMemoryJobStore) eventsExpired(jobId string) bool {
+	expiry, ok := store.eventExpiry[jobId]
+	return ok && !store.now().Before(expiry)
+}
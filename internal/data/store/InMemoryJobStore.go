@@ -2,39 +2,108 @@ package store
 
 import (
 	"context"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/schema"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/jobEvents"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
 var inMemLogger = logger_i.NewLogger("InMem JobStore")
 
+// InMemoryJobStore keeps jobs and schedules encoded the way redis does, so a read never shares
+// slices with the caller and goes through the same schema upgrades
 type InMemoryJobStore struct {
-	jobMutex *sync.RWMutex
-	jobMap   map[string]jobModel.Job
+	jobMutex    *sync.RWMutex
+	jobMap      map[string][]byte
+	scheduleMap map[string][]byte
+	eventMap    map[string][]jobModel.StreamEvent
+	//jobs and event logs expire config.RedisJobStoreTTL after they were last written, as they do in redis
+	jobExpiry   map[string]time.Time
+	eventExpiry map[string]time.Time
+	lastSweep   time.Time
+	now         func() time.Time
 }
 
 func InitInMemoryJobStore() *InMemoryJobStore {
+	return TestInMemoryJobStore(time.Now)
+}
+
+// TestInMemoryJobStore lets tests move the clock the TTLs are measured with
+func TestInMemoryJobStore(now func() time.Time) *InMemoryJobStore {
 	return &InMemoryJobStore{
-		jobMutex: new(sync.RWMutex),
-		jobMap:   make(map[string]jobModel.Job),
+		jobMutex:    new(sync.RWMutex),
+		jobMap:      make(map[string][]byte),
+		scheduleMap: make(map[string][]byte),
+		eventMap:    make(map[string][]jobModel.StreamEvent),
+		jobExpiry:   make(map[string]time.Time),
+		eventExpiry: make(map[string]time.Time),
+		lastSweep:   now(),
+		now:         now,
+	}
+}
+
+// sweep drops expired jobs and event logs, reads skip them until then. Call it holding the write lock
+func (store *InMemoryJobStore) sweep() {
+	now := store.now()
+	if now.Sub(store.lastSweep) < config.InMemorySweepInterval {
+		return
+	}
+	store.lastSweep = now
+	for id, expiry := range store.jobExpiry {
+		if !now.Before(expiry) {
+			delete(store.jobMap, id)
+			delete(store.jobExpiry, id)
+		}
+	}
+	for id, expiry := range store.eventExpiry {
+		if !now.Before(expiry) {
+			delete(store.eventMap, id)
+			delete(store.eventExpiry, id)
+		}
 	}
 }
 
+// jobExpired call it holding the lock
+func (store *InMemoryJobStore) jobExpired(jobId string) bool {
+	expiry, ok := store.jobExpiry[jobId]
+	return ok && !store.now().Before(expiry)
+}
+
 func (store *InMemoryJobStore) SaveJob(ctx context.Context, jobToStored jobModel.Job) error {
+	data, err := schema.Encode(schema.Job, jobToStored)
+	if err != nil {
+		return err
+	}
 
 	store.jobMutex.Lock()
 	defer store.jobMutex.Unlock()
-	store.jobMap[jobToStored.Id] = jobToStored
+	store.jobMap[jobToStored.Id] = data
+	store.jobExpiry[jobToStored.Id] = store.now().Add(config.RedisJobStoreTTL)
+	store.sweep()
 	inMemLogger.Info(jobToStored.Id, " : Saved job to store")
+	jobEvents.Notify(jobToStored.Id)
 	return nil
 }
 
 func (store *InMemoryJobStore) GetJob(ctx context.Context, jobId string) (jobModel.Job, bool) {
 	store.jobMutex.RLock()
 	defer store.jobMutex.RUnlock()
-	result, found := store.jobMap[jobId]
+	var result jobModel.Job
+	data, found := store.jobMap[jobId]
+	if found && store.jobExpired(jobId) {
+		found = false
+	}
+	if found {
+		if _, err := schema.Decode(schema.Job, data, &result); err != nil {
+			inMemLogger.Error(jobId, " : Unreadable job", err)
+			return jobModel.Job{}, false
+		}
+	}
 	inMemLogger.Info(jobId, " : Is job found :", found)
 	return result, found
 }
@@ -43,4 +112,48 @@ func (store *InMemoryJobStore) DeleteJob(ctx context.Context, jobID string) {
 	store.jobMutex.Lock()
 	defer store.jobMutex.Unlock()
 	delete(store.jobMap, jobID)
+	delete(store.jobExpiry, jobID)
+}
+
+func (store *InMemoryJobStore) ListJobs(ctx context.Context, query jobModel.JobQuery) (jobModel.JobPage, error) {
+	limit := pageSize(query)
+	var after *jobModel.JobCursor
+	if query.Cursor != "" {
+		cursor, err := jobModel.DecodeCursor(query.Cursor)
+		if err != nil {
+			return jobModel.JobPage{}, err
+		}
+		after = &cursor
+	}
+
+	store.jobMutex.RLock()
+	var jobs []jobModel.Job
+	for id, data := range store.jobMap {
+		if store.jobExpired(id) {
+			continue
+		}
+		var job jobModel.Job
+		if _, err := schema.Decode(schema.Job, data, &job); err != nil {
+			inMemLogger.Warn(id, " : Skipping unreadable job", err)
+			continue
+		}
+		if query.Matches(job) && (after == nil || jobModel.CursorOf(job).After(*after)) {
+			jobs = append(jobs, job)
+		}
+	}
+	store.jobMutex.RUnlock()
+
+	slices.SortFunc(jobs, func(a, b jobModel.Job) int {
+		switch {
+		case jobModel.CursorOf(a).After(jobModel.CursorOf(b)):
+			return 1
+		case jobModel.CursorOf(b).After(jobModel.CursorOf(a)):
+			return -1
+		}
+		return 0
+	})
+	if len(jobs) > limit+1 {
+		jobs = jobs[:limit+1]
+	}
+	return toPage(jobs, limit), nil
 }
@@ -2,20 +2,40 @@ package store
 
 import (
 	"context"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/schema"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
 )
 
+// InMemoryMessageStore keeps every chat entry encoded, like InMemoryJobStore does its jobs
 type InMemoryMessageStore struct {
-	chatLock *sync.RWMutex
-	chatMap  map[string][]jobModel.JobPayload
+	chatLock   *sync.RWMutex
+	chatMap    map[string][][]byte
+	summaryMap map[string]jobModel.ChatSummary
+	infoMap    map[string]jobModel.ChatInfo
+	//summaries expire config.RedisMessageStoreTTL after they were saved, as they do in redis. Chats don't expire
+	summaryExpiry map[string]time.Time
+	now           func() time.Time
 }
 
 func InitMessageStore() *InMemoryMessageStore {
+	return TestInMemoryMessageStore(time.Now)
+}
+
+// TestInMemoryMessageStore lets tests move the clock the TTLs are measured with
+func TestInMemoryMessageStore(now func() time.Time) *InMemoryMessageStore {
 	return &InMemoryMessageStore{
-		chatLock: new(sync.RWMutex),
-		chatMap:  make(map[string][]jobModel.JobPayload),
+		chatLock:      new(sync.RWMutex),
+		chatMap:       make(map[string][][]byte),
+		summaryMap:    make(map[string]jobModel.ChatSummary),
+		infoMap:       make(map[string]jobModel.ChatInfo),
+		summaryExpiry: make(map[string]time.Time),
+		now:           now,
 	}
 }
 
@@ -26,29 +46,177 @@ func (store *InMemoryMessageStore) ValidateChatId(ctx context.Context, chatId st
 	return ok
 }
 
-func (store *InMemoryMessageStore) saveChatId(id string, conversation jobModel.JobPayload) {
+func (store *InMemoryMessageStore) saveChatId(id string, conversation jobModel.JobPayload) error {
 	store.chatLock.Lock()
 	defer store.chatLock.Unlock()
-	store.chatMap[id] = append(store.chatMap[id], conversation)
+	//checked again under the lock, the chat may have been deleted since TrySaveChat validated it
+	if _, ok := store.chatMap[id]; !ok {
+		return jobModel.ErrChatNotFound
+	}
+	now := store.now()
+	data, err := schema.Encode(schema.ChatEntry, jobModel.ChatEntry{JobPayload: conversation, Time: now})
+	if err != nil {
+		return err
+	}
+	store.chatMap[id] = append(store.chatMap[id], data)
+	if info, ok := store.infoMap[id]; ok {
+		info.UpdatedTime = now
+		if info.Title == "" {
+			info.Title = defaultTitle(conversation.Question)
+		}
+		store.infoMap[id] = info
+	}
 	inMemLogger.Info(id, " : Saved convo to chat message store")
+	return nil
 }
 
 func (store *InMemoryMessageStore) TrySaveChat(ctx context.Context, id string, conversation jobModel.JobPayload) error {
 	if store.ValidateChatId(ctx, id) == false {
-		return nil
+		inMemLogger.Error(id, " : Failed Validation before saving")
+		return jobModel.ErrChatNotFound
 	}
-	store.saveChatId(id, conversation)
+	return store.saveChatId(id, conversation)
+}
+
+func (store *InMemoryMessageStore) InitNewChat(ctx context.Context, id string, identity string) error {
+	store.chatLock.Lock()
+	defer store.chatLock.Unlock()
+	now := store.now()
+	store.chatMap[id] = make([][]byte, 0)
+	store.infoMap[id] = jobModel.ChatInfo{Id: id, Identity: identity, CreatedTime: now, UpdatedTime: now}
 	return nil
 }
 
-func (store *InMemoryMessageStore) InitNewChat(ctx context.Context, id string) error {
+func (store *InMemoryMessageStore) GetChat(ctx context.Context, chatId string) (jobModel.ChatInfo, bool, error) {
+	store.chatLock.RLock()
+	defer store.chatLock.RUnlock()
+	if _, ok := store.chatMap[chatId]; !ok {
+		return jobModel.ChatInfo{}, false, nil
+	}
+	info, ok := store.infoMap[chatId]
+	if !ok {
+		info = jobModel.ChatInfo{Id: chatId}
+	}
+	return info, true, nil
+}
+
+func (store *InMemoryMessageStore) ListChats(ctx context.Context, query jobModel.ChatQuery) (jobModel.ChatPage, error) {
+	var after *jobModel.JobCursor
+	if query.Cursor != "" {
+		cursor, err := jobModel.DecodeCursor(query.Cursor)
+		if err != nil {
+			return jobModel.ChatPage{}, err
+		}
+		after = &cursor
+	}
+
+	store.chatLock.RLock()
+	chats := make([]jobModel.ChatInfo, 0)
+	for _, info := range store.infoMap {
+		if query.Identity == "" || info.Identity != query.Identity {
+			continue
+		}
+		if after == nil || jobModel.ChatCursor(info).After(*after) {
+			chats = append(chats, info)
+		}
+	}
+	store.chatLock.RUnlock()
+
+	slices.SortFunc(chats, func(a, b jobModel.ChatInfo) int {
+		if jobModel.ChatCursor(a).After(jobModel.ChatCursor(b)) {
+			return 1
+		}
+		return -1
+	})
+	limit := chatPageSize(query)
+	if len(chats) > limit+1 {
+		chats = chats[:limit+1]
+	}
+	return toChatPage(chats, limit), nil
+}
+
+func (store *InMemoryMessageStore) GetTranscript(ctx context.Context, chatId string) ([]jobModel.ChatEntry, error) {
+	store.chatLock.RLock()
+	defer store.chatLock.RUnlock()
+	return toTranscript(store.entries(chatId)), nil
+}
+
+func (store *InMemoryMessageStore) RenameChat(ctx context.Context, chatId string, title string) error {
 	store.chatLock.Lock()
 	defer store.chatLock.Unlock()
-	store.chatMap[id] = make([]jobModel.JobPayload, 0)
+	if _, ok := store.chatMap[chatId]; !ok {
+		return jobModel.ErrChatNotFound
+	}
+	info, ok := store.infoMap[chatId]
+	if !ok {
+		info = jobModel.ChatInfo{Id: chatId}
+	}
+	info.Title = title
+	store.infoMap[chatId] = info
 	return nil
 }
 
-func (store *InMemoryMessageStore) GetMessageHistory(ctx context.Context, chatId string) (error, []string) {
+func (store *InMemoryMessageStore) GetMessageHistory(ctx context.Context, chatId string) (error, []llm.Message) {
+	store.chatLock.RLock()
+	defer store.chatLock.RUnlock()
+	return nil, toHistory(store.entries(chatId), historyWindow())
+}
+
+func (store *InMemoryMessageStore) GetTurns(ctx context.Context, chatId string) ([]llm.Message, error) {
+	store.chatLock.RLock()
+	defer store.chatLock.RUnlock()
+	return toHistory(store.entries(chatId), llm.HistoryWindow{}), nil
+}
+
+func (store *InMemoryMessageStore) GetToolHistory(ctx context.Context, chatId string) ([]llm.Message, error) {
+	store.chatLock.RLock()
+	defer store.chatLock.RUnlock()
+	return toToolHistory(store.entries(chatId), mcpHistoryWindow()), nil
+}
+
+// entries the decoded entries of the chat, call it holding the lock
+func (store *InMemoryMessageStore) entries(chatId string) []jobModel.ChatEntry {
+	encoded := store.chatMap[chatId]
+	entries := make([]jobModel.ChatEntry, 0, len(encoded))
+	for _, data := range encoded {
+		var entry jobModel.ChatEntry
+		if _, err := schema.Decode(schema.ChatEntry, data, &entry); err != nil {
+			inMemLogger.Warn(chatId, " : Skipping unreadable chat entry", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
 
-	return nil, nil
+func (store *InMemoryMessageStore) GetSummary(ctx context.Context, chatId string) (jobModel.ChatSummary, bool, error) {
+	store.chatLock.RLock()
+	defer store.chatLock.RUnlock()
+	summary, ok := store.summaryMap[chatId]
+	if expiry, set := store.summaryExpiry[chatId]; ok && set && !store.now().Before(expiry) {
+		return jobModel.ChatSummary{}, false, nil
+	}
+	return summary, ok, nil
+}
+
+func (store *InMemoryMessageStore) SaveSummary(ctx context.Context, chatId string, summary jobModel.ChatSummary) error {
+	store.chatLock.Lock()
+	defer store.chatLock.Unlock()
+	if _, ok := store.chatMap[chatId]; !ok {
+		return jobModel.ErrChatNotFound
+	}
+	store.summaryMap[chatId] = summary
+	store.summaryExpiry[chatId] = store.now().Add(config.RedisMessageStoreTTL)
+	return nil
+}
+
+func (store *InMemoryMessageStore) DeleteChat(ctx context.Context, chatId string) (bool, error) {
+	store.chatLock.Lock()
+	defer store.chatLock.Unlock()
+	_, ok := store.chatMap[chatId]
+	delete(store.chatMap, chatId)
+	delete(store.summaryMap, chatId)
+	delete(store.infoMap, chatId)
+	delete(store.summaryExpiry, chatId)
+	return ok, nil
 }
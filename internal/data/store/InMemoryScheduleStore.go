@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/data/schema"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+//in memory there is only ever one replica, so the leader lock is always ours
+
+func (store *InMemoryJobStore) SaveSchedule(ctx context.Context, schedule jobModel.Schedule) error {
+	data, err := schema.Encode(schema.Schedule, schedule)
+	if err != nil {
+		return err
+	}
+	store.jobMutex.Lock()
+	defer store.jobMutex.Unlock()
+	store.scheduleMap[schedule.Id] = data
+	return nil
+}
+
+func (store *InMemoryJobStore) GetSchedule(ctx context.Context, id string) (jobModel.Schedule, bool) {
+	store.jobMutex.RLock()
+	defer store.jobMutex.RUnlock()
+	var schedule jobModel.Schedule
+	data, found := store.scheduleMap[id]
+	if !found {
+		return schedule, false
+	}
+	if _, err := schema.Decode(schema.Schedule, data, &schedule); err != nil {
+		inMemLogger.Error(id, " : Unreadable schedule", err)
+		return schedule, false
+	}
+	return schedule, true
+}
+
+func (store *InMemoryJobStore) ListSchedules(ctx context.Context) ([]jobModel.Schedule, error) {
+	store.jobMutex.RLock()
+	defer store.jobMutex.RUnlock()
+	schedules := make([]jobModel.Schedule, 0, len(store.scheduleMap))
+	for id, data := range store.scheduleMap {
+		var schedule jobModel.Schedule
+		if _, err := schema.Decode(schema.Schedule, data, &schedule); err != nil {
+			inMemLogger.Warn(id, " : Skipping unreadable schedule", err)
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func (store *InMemoryJobStore) DeleteSchedule(ctx context.Context, id string) error {
+	store.jobMutex.Lock()
+	defer store.jobMutex.Unlock()
+	delete(store.scheduleMap, id)
+	return nil
+}
+
+func (store *InMemoryJobStore) AcquireLeaderLock(ctx context.Context, name string, owner string, ttl time.Duration) bool {
+	return true
+}
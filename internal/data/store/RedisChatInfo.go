@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func chatInfoKey(chatId string) string {
+	return config.RedisChatInfoPrefix + chatId
+}
+
+func chatIndexKey(identity string) string {
+	return config.RedisChatIndexPrefix + identity
+}
+
+// saveChatInfo writes the chat info hash and lists chats that have an identity under it
+func (s *RedisMessageStore) saveChatInfo(ctx context.Context, info jobModel.ChatInfo) error {
+	key := chatInfoKey(info.Id)
+	title, err := sealText(ctx, s.sealer, info.Title)
+	if err != nil {
+		return err
+	}
+	fields := map[string]string{
+		"identity": info.Identity,
+		"title":    title,
+		"created":  info.CreatedTime.Format(time.RFC3339Nano),
+		"updated":  info.UpdatedTime.Format(time.RFC3339Nano),
+	}
+	for field, value := range fields {
+		if err := s.store.HashSet(ctx, key, field, value); err != nil {
+			return err
+		}
+	}
+	if info.Identity == "" {
+		return nil
+	}
+	return s.store.SortedSetAdd(ctx, chatIndexKey(info.Identity), float64(info.CreatedTime.UnixMilli()), info.Id)
+}
+
+// getChatInfo found is false for chats started before the info was kept
+func (s *RedisMessageStore) getChatInfo(ctx context.Context, chatId string) (jobModel.ChatInfo, bool, error) {
+	fields, err := s.store.HashGetAll(ctx, chatInfoKey(chatId))
+	if err != nil || len(fields) == 0 {
+		return jobModel.ChatInfo{Id: chatId}, false, err
+	}
+	info := jobModel.ChatInfo{Id: chatId, Identity: fields["identity"]}
+	if info.Title, err = openText(ctx, s.sealer, fields["title"]); err != nil {
+		return info, false, err
+	}
+	info.CreatedTime, _ = time.Parse(time.RFC3339Nano, fields["created"])
+	info.UpdatedTime, _ = time.Parse(time.RFC3339Nano, fields["updated"])
+	return info, true, nil
+}
+
+// touchChat moves the updated time on and titles an untitled chat with its first question
+func (s *RedisMessageStore) touchChat(ctx context.Context, chatId string, question string) error {
+	info, found, err := s.getChatInfo(ctx, chatId)
+	if err != nil || !found {
+		return err
+	}
+	key := chatInfoKey(chatId)
+	if err = s.store.HashSet(ctx, key, "updated", time.Now().Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+	if info.Title == "" && question != "" {
+		return s.setTitle(ctx, chatId, defaultTitle(question))
+	}
+	return nil
+}
+
+func (s *RedisMessageStore) GetChat(ctx context.Context, chatId string) (jobModel.ChatInfo, bool, error) {
+	isFound, err := s.store.Exists(ctx, chatId)
+	if err != nil || !isFound {
+		return jobModel.ChatInfo{}, false, err
+	}
+	info, _, err := s.getChatInfo(ctx, chatId)
+	if err != nil {
+		return jobModel.ChatInfo{}, false, err
+	}
+	return info, true, nil
+}
+
+func (s *RedisMessageStore) ListChats(ctx context.Context, query jobModel.ChatQuery) (jobModel.ChatPage, error) {
+	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY))
+	if query.Identity == "" {
+		return jobModel.ChatPage{}, nil
+	}
+	limit := chatPageSize(query)
+
+	max := math.Inf(1)
+	var after *jobModel.JobCursor
+	if query.Cursor != "" {
+		cursor, err := jobModel.DecodeCursor(query.Cursor)
+		if err != nil {
+			return jobModel.ChatPage{}, err
+		}
+		after = &cursor
+		max = float64(cursor.CreatedMs)
+	}
+
+	key := chatIndexKey(query.Identity)
+	batch := int64(2 * limit)
+	var offset int64
+	var chats []jobModel.ChatInfo
+	var stale []string
+
+	//one extra chat tells us whether there is another page
+	for len(chats) <= limit {
+		members, err := s.store.SortedSetRangeDesc(ctx, key, max, math.Inf(-1), offset, batch)
+		if err != nil {
+			return jobModel.ChatPage{}, err
+		}
+		offset += int64(len(members))
+
+		for _, member := range members {
+			position := jobModel.JobCursor{CreatedMs: int64(member.Score), Id: member.Member}
+			if after != nil && !position.After(*after) {
+				continue
+			}
+			info, found, err := s.getChatInfo(ctx, member.Member)
+			if err != nil {
+				return jobModel.ChatPage{}, err
+			}
+			if !found {
+				stale = append(stale, member.Member) //the chat was deleted without its index entry
+				continue
+			}
+			chats = append(chats, info)
+		}
+
+		if int64(len(members)) < batch {
+			break
+		}
+	}
+
+	if len(stale) > 0 {
+		if err := s.store.SortedSetRemove(ctx, key, stale...); err != nil {
+			log.Warn("Failed to drop deleted chats from the index", "err", err)
+		}
+	}
+
+	return toChatPage(chats, limit), nil
+}
+
+func (s *RedisMessageStore) GetTranscript(ctx context.Context, chatId string) ([]jobModel.ChatEntry, error) {
+	entries, err := s.getPayloads(ctx, chatId, 0)
+	if err != nil {
+		return nil, err
+	}
+	return toTranscript(entries), nil
+}
+
+func (s *RedisMessageStore) RenameChat(ctx context.Context, chatId string, title string) error {
+	isFound, err := s.store.Exists(ctx, chatId)
+	if err != nil {
+		return err
+	} else if !isFound {
+		return jobModel.ErrChatNotFound
+	}
+	return s.setTitle(ctx, chatId, title)
+}
+
+func (s *RedisMessageStore) setTitle(ctx context.Context, chatId string, title string) error {
+	sealed, err := sealText(ctx, s.sealer, title)
+	if err != nil {
+		return err
+	}
+	return s.store.HashSet(ctx, chatInfoKey(chatId), "title", sealed)
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/jobEvents"
+)
+
+//every job gets a redis stream next to it, stream ids are used as the SSE event ids
+
+func (s *RedisJobStore) AppendEvent(ctx context.Context, jobId string, event jobModel.StreamEvent) error {
+	key := config.RedisEventKeyPrefix + jobId
+	data, err := sealText(ctx, s.sealer, event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.StreamAdd(ctx, key, config.RedisEventStreamMaxLen, map[string]interface{}{
+		"type": string(event.Type),
+		"data": data,
+	})
+	if err != nil {
+		s.logger.Error("Error appending job event", "job Id", jobId, "error", err)
+		return err
+	}
+	//a job's first event is a step and its last is done, deltas in between don't need to push the expiry back
+	if event.Type != jobModel.StreamEventDelta {
+		if err = s.store.Expire(ctx, key, config.RedisJobStoreTTL); err != nil {
+			s.logger.Warn("Could not set expiry on job events", "job Id", jobId, "error", err)
+		}
+	}
+
+	jobEvents.Notify(jobId)
+	if err = s.store.Publish(ctx, config.RedisJobEventsChannel, jobId); err != nil {
+		s.logger.Warn("Failed to publish job event", "job Id", jobId, "err", err)
+	}
+	return nil
+}
+
+func (s *RedisJobStore) ReadEvents(ctx context.Context, jobId string, afterId string) ([]jobModel.StreamEvent, error) {
+	entries, err := s.store.StreamRangeAfter(ctx, config.RedisEventKeyPrefix+jobId, afterId)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]jobModel.StreamEvent, 0, len(entries))
+	for _, entry := range entries {
+		data, err := openText(ctx, s.sealer, fmt.Sprint(entry.Values["data"]))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, jobModel.StreamEvent{
+			Id:   entry.Id,
+			Type: jobModel.StreamEventType(fmt.Sprint(entry.Values["type"])),
+			Data: data,
+		})
+	}
+	return events, nil
+}
+
+func (s *RedisJobStore) DeleteEvents(ctx context.Context, jobId string) error {
+	return s.store.Del(ctx, config.RedisEventKeyPrefix+jobId)
+}
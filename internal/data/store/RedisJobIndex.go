@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/schema"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+var jobStatuses = []jobModel.JobStatus{
+	jobModel.JobStatusQueued,
+	jobModel.JobStatusRunning,
+	jobModel.JobStatusComplete,
+	jobModel.JobStatusError,
+}
+
+func jobIndexKey(field string, value string) string {
+	return config.RedisJobIndexPrefix + field + ":" + value
+}
+
+func allJobsIndexKey() string {
+	return config.RedisJobIndexPrefix + "all"
+}
+
+// indexKeys every index the job belongs to
+func indexKeys(job jobModel.Job) []string {
+	keys := []string{allJobsIndexKey(), jobIndexKey("type", string(job.JobType)), jobIndexKey("status", string(job.Status))}
+	if job.ChatId != "" {
+		keys = append(keys, jobIndexKey("chat", job.ChatId))
+	}
+	if job.Identity != "" {
+		keys = append(keys, jobIndexKey("identity", job.Identity))
+	}
+	return keys
+}
+
+// queryIndexKey the most selective index that covers the query, the other filters are applied on the jobs
+func queryIndexKey(query jobModel.JobQuery) string {
+	switch {
+	case query.ChatId != "":
+		return jobIndexKey("chat", query.ChatId)
+	case query.Identity != "":
+		return jobIndexKey("identity", query.Identity)
+	case query.Status != "":
+		return jobIndexKey("status", string(query.Status))
+	case query.JobType != "":
+		return jobIndexKey("type", string(query.JobType))
+	default:
+		return allJobsIndexKey()
+	}
+}
+
+// indexJob adds the job to its indexes, moves it out of the index of its previous status,
+// and trims entries older than the job TTL so the indexes don't outgrow the jobs
+func (s *RedisJobStore) indexJob(ctx context.Context, job jobModel.Job) error {
+	score := float64(job.CreatedTime.UnixMilli())
+	expired := float64(time.Now().Add(-config.RedisJobStoreTTL).UnixMilli())
+
+	for _, key := range indexKeys(job) {
+		if err := s.store.SortedSetAdd(ctx, key, score, job.Id); err != nil {
+			return err
+		}
+		if err := s.store.SortedSetRemoveBelow(ctx, key, expired); err != nil {
+			return err
+		}
+		if err := s.store.Expire(ctx, key, config.RedisJobStoreTTL); err != nil {
+			return err
+		}
+	}
+	for _, status := range jobStatuses {
+		if status == job.Status {
+			continue
+		}
+		if err := s.store.SortedSetRemove(ctx, jobIndexKey("status", string(status)), job.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RedisJobStore) unindexJob(ctx context.Context, job jobModel.Job) error {
+	for _, key := range indexKeys(job) {
+		if err := s.store.SortedSetRemove(ctx, key, job.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RedisJobStore) ListJobs(ctx context.Context, query jobModel.JobQuery) (jobModel.JobPage, error) {
+	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY))
+	limit := pageSize(query)
+
+	max, min := math.Inf(1), math.Inf(-1)
+	if !query.CreatedTo.IsZero() {
+		max = float64(query.CreatedTo.UnixMilli())
+	}
+	if !query.CreatedFrom.IsZero() {
+		min = float64(query.CreatedFrom.UnixMilli())
+	}
+
+	var after *jobModel.JobCursor
+	if query.Cursor != "" {
+		cursor, err := jobModel.DecodeCursor(query.Cursor)
+		if err != nil {
+			return jobModel.JobPage{}, err
+		}
+		after = &cursor
+		max = math.Min(max, float64(cursor.CreatedMs))
+	}
+
+	key := queryIndexKey(query)
+	batch := int64(2 * limit)
+	var offset int64
+	var jobs []jobModel.Job
+	var stale []string
+
+	//one extra job tells us whether there is another page
+	for len(jobs) <= limit {
+		members, err := s.store.SortedSetRangeDesc(ctx, key, max, min, offset, batch)
+		if err != nil {
+			return jobModel.JobPage{}, err
+		}
+		offset += int64(len(members))
+
+		ids := make([]string, 0, len(members))
+		for _, member := range members {
+			position := jobModel.JobCursor{CreatedMs: int64(member.Score), Id: member.Member}
+			if after == nil || position.After(*after) {
+				ids = append(ids, member.Member)
+			}
+		}
+
+		if len(ids) > 0 {
+			values, found, err := s.store.MGet(ctx, ids...)
+			if err != nil {
+				return jobModel.JobPage{}, err
+			}
+			for i, value := range values {
+				if !found[i] {
+					stale = append(stale, ids[i]) //the job expired before its index entry
+					continue
+				}
+				var job jobModel.Job
+				if err := openRecord(ctx, s.sealer, schema.Job, value, &job); err != nil {
+					log.Warn("Skipping unreadable job", "jobId", ids[i], "err", err)
+					continue
+				}
+				if query.Matches(job) {
+					jobs = append(jobs, job)
+				}
+			}
+		}
+
+		if int64(len(members)) < batch {
+			break
+		}
+	}
+
+	if len(stale) > 0 {
+		if err := s.store.SortedSetRemove(ctx, key, stale...); err != nil {
+			log.Warn("Failed to drop expired jobs from the index", "err", err)
+		}
+	}
+
+	return toPage(jobs, limit), nil
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/schema"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/google/uuid"
+)
+
+//a redis list in the job store DB, pushed at the tail and taken from the head. A job taken by a worker
+//process is moved onto that process' processing list in the same step, and only leaves it once it is acked
+
+// RedisJobQueue the queue as seen by one process, every process gets its own id
+type RedisJobQueue struct {
+	*RedisJobStore
+	workerId string
+	//held job id -> the record as it sits on the processing list, which Ack and Requeue have to match
+	held sync.Map
+}
+
+func NewRedisJobQueue(jobStore *RedisJobStore) *RedisJobQueue {
+	return &RedisJobQueue{RedisJobStore: jobStore, workerId: uuid.NewString()}
+}
+
+func (q *RedisJobQueue) Enqueue(ctx context.Context, job jobModel.Job) error {
+	data, err := sealRecord(ctx, q.sealer, schema.Job, job)
+	if err != nil {
+		return err
+	}
+	return q.store.ListPush(ctx, config.RedisJobQueueKey, data)
+}
+
+// Requeue a held job moves from the processing list to the front of the queue in one step, so it is never in both
+func (q *RedisJobQueue) Requeue(ctx context.Context, job jobModel.Job) error {
+	if value, found := q.held.LoadAndDelete(job.Id); found {
+		_, err := q.store.ListMoveFront(ctx, q.processingKey(q.workerId), config.RedisJobQueueKey, value.(string))
+		return err
+	}
+	data, err := sealRecord(ctx, q.sealer, schema.Job, job)
+	if err != nil {
+		return err
+	}
+	return q.store.ListPushFront(ctx, config.RedisJobQueueKey, data)
+}
+
+func (q *RedisJobQueue) Dequeue(ctx context.Context, timeout time.Duration) (jobModel.Job, bool, error) {
+	value, found, err := q.store.ListBlockingMove(ctx, config.RedisJobQueueKey, q.processingKey(q.workerId), timeout)
+	if err != nil || !found {
+		return jobModel.Job{}, false, err
+	}
+	job, ok := q.hold(ctx, value)
+	return job, ok, nil
+}
+
+func (q *RedisJobQueue) Ack(ctx context.Context, job jobModel.Job) error {
+	value, found := q.held.LoadAndDelete(job.Id)
+	if !found {
+		return nil
+	}
+	return q.store.ListRemove(ctx, q.processingKey(q.workerId), value.(string))
+}
+
+func (q *RedisJobQueue) Heartbeat(ctx context.Context) error {
+	return q.store.SortedSetAdd(ctx, config.RedisJobQueueWorkersKey, float64(time.Now().UnixMilli()), q.workerId)
+}
+
+// Reap moves the processing lists of processes that missed their beats onto this one's, a process that
+// stops while reaping leaves the rest to the next reaper
+func (q *RedisJobQueue) Reap(ctx context.Context) ([]jobModel.Job, error) {
+	deadline := float64(time.Now().Add(-config.JobQueueWorkerTTL).UnixMilli())
+	stopped, err := q.store.SortedSetRangeDesc(ctx, config.RedisJobQueueWorkersKey, deadline, 0, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var reaped []jobModel.Job
+	for _, worker := range stopped {
+		if worker.Member == q.workerId {
+			continue
+		}
+		before := len(reaped)
+		for {
+			value, found, err := q.store.ListMove(ctx, q.processingKey(worker.Member), q.processingKey(q.workerId))
+			if err != nil {
+				return reaped, err
+			}
+			if !found {
+				break
+			}
+			if job, ok := q.hold(ctx, value); ok {
+				reaped = append(reaped, job)
+			}
+		}
+		if err = q.store.SortedSetRemove(ctx, config.RedisJobQueueWorkersKey, worker.Member); err != nil {
+			return reaped, err
+		}
+		q.logger.Warn("Reaped the jobs of a stopped worker process", "worker", worker.Member, "jobs", len(reaped)-before)
+	}
+	return reaped, nil
+}
+
+// hold opens a record that just landed on this process' processing list
+func (q *RedisJobQueue) hold(ctx context.Context, value string) (jobModel.Job, bool) {
+	var job jobModel.Job
+	if err := openRecord(ctx, q.sealer, schema.Job, value, &job); err != nil {
+		//a job that can't be read would block the queue if it went back, so it is dropped
+		q.logger.Error("Dropping unreadable queued job", "error", err)
+		_ = q.store.ListRemove(ctx, q.processingKey(q.workerId), value)
+		return job, false
+	}
+	q.held.Store(job.Id, value)
+	return job, true
+}
+
+// processingKey in the queue's cluster slot, so a job moves between them in one step
+func (q *RedisJobQueue) processingKey(workerId string) string {
+	return q.store.SlotKey(config.RedisJobQueueProcessingPrefix+workerId, config.RedisJobQueueKey)
+}
@@ -2,30 +2,43 @@ package store
 
 import (
 	"context"
-	"encoding/json"
+	"time"
 
 	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/encryption"
 	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/schema"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/jobEvents"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
 type RedisJobStore struct {
 	store  *redisStore.Store
+	sealer *encryption.Sealer
 	logger *logger_i.Logger
 }
 
-func GetRedisJobStore(ctx context.Context) *RedisJobStore {
+func GetRedisJobStore(ctx context.Context) (*RedisJobStore, error) {
+	redis, err := redisStore.GetRedisStore(ctx, config.RedisJobStore)
+	if err != nil {
+		return nil, err
+	}
+	sealer, err := encryption.Default()
+	if err != nil {
+		return nil, err
+	}
 	return &RedisJobStore{
-		store:  redisStore.GetRedisStore(ctx, config.RedisJobStore),
+		store:  redis,
+		sealer: sealer,
 		logger: logger_i.NewLogger("JobStore"),
-	}
+	}, nil
 }
 
 func (s *RedisJobStore) SaveJob(ctx context.Context, job jobModel.Job) error {
 	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY), "job Id", job.Id)
 	log.Debug("saving job")
-	data, err := json.Marshal(job)
+	data, err := sealRecord(ctx, s.sealer, schema.Job, job)
 	if err != nil {
 		return err
 	}
@@ -33,10 +46,33 @@ func (s *RedisJobStore) SaveJob(ctx context.Context, job jobModel.Job) error {
 	err = s.store.Set(ctx, job.Id, data, config.RedisJobStoreTTL)
 	if err == nil {
 		log.Debug("Saved job to Redis")
+		if indexErr := s.indexJob(ctx, job); indexErr != nil {
+			log.Warn("Failed to index job, it won't show up in listings", "err", indexErr)
+		}
+		jobEvents.Notify(job.Id)
+		if pubErr := s.store.Publish(ctx, config.RedisJobEventsChannel, job.Id); pubErr != nil {
+			log.Warn("Failed to publish job event", "err", pubErr)
+		}
 	}
 	return err
 }
 
+// ListenForJobEvents relays jobs saved by other replicas to the long polls waiting on this one.
+// It blocks until ctx is done.
+func (s *RedisJobStore) ListenForJobEvents(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := s.store.Subscribe(ctx, config.RedisJobEventsChannel, jobEvents.Notify)
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Warn("Job events subscription dropped, resubscribing", "err", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
 func (s *RedisJobStore) GetJob(ctx context.Context, jobId string) (jobModel.Job, bool) {
 	var job jobModel.Job
 	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY), "job Id", jobId)
@@ -50,8 +86,9 @@ func (s *RedisJobStore) GetJob(ctx context.Context, jobId string) (jobModel.Job,
 
 	log.Debug("Unmarshalling job")
 	// 2. Unmarshal JSON back into the Job struct
-	err = json.Unmarshal([]byte(val), &job)
+	err = openRecord(ctx, s.sealer, schema.Job, val, &job)
 	if err != nil {
+		log.Error("Error reading job", "error", err)
 		return job, false
 	}
 
@@ -60,6 +97,11 @@ func (s *RedisJobStore) GetJob(ctx context.Context, jobId string) (jobModel.Job,
 }
 
 func (s *RedisJobStore) DeleteJob(ctx context.Context, jobID string) {
+	if job, found := s.GetJob(ctx, jobID); found {
+		if err := s.unindexJob(ctx, job); err != nil {
+			s.logger.Warn("Failed to remove job from the indexes", "jobId", jobID, "err", err)
+		}
+	}
 	err := s.store.Del(ctx, jobID)
 	if err != nil {
 		s.logger.Error(jobID, "jobId", ": Error deleting job from Redis")
@@ -74,3 +116,9 @@ func TestJobStore(store *redisStore.Store) *RedisJobStore {
 		logger: logger_i.NewLogger("test redis"),
 	}
 }
+
+func TestSealedJobStore(store *redisStore.Store, sealer *encryption.Sealer) *RedisJobStore {
+	jobStore := TestJobStore(store)
+	jobStore.sealer = sealer
+	return jobStore
+}
@@ -2,26 +2,37 @@ package store
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"time"
 
-	"github.com/akolanti/GoAPI/internal/adapter/utils"
 	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/encryption"
 	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/schema"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
 type RedisMessageStore struct {
 	store  *redisStore.Store
+	sealer *encryption.Sealer
 	logger *logger_i.Logger
 }
 
-func GetRedisMessageStore(ctx context.Context) *RedisMessageStore {
+func GetRedisMessageStore(ctx context.Context) (*RedisMessageStore, error) {
+	redis, err := redisStore.GetRedisStore(ctx, config.RedisMessageStore)
+	if err != nil {
+		return nil, err
+	}
+	sealer, err := encryption.Default()
+	if err != nil {
+		return nil, err
+	}
 	return &RedisMessageStore{
-		store:  redisStore.GetRedisStore(ctx, config.RedisMessageStore),
+		store:  redis,
+		sealer: sealer,
 		logger: logger_i.NewLogger("MessageStore"),
-	}
+	}, nil
 }
 
 func (s *RedisMessageStore) ValidateChatId(ctx context.Context, chatId string) bool {
@@ -40,16 +51,28 @@ func (s *RedisMessageStore) ValidateChatId(ctx context.Context, chatId string) b
 func (s *RedisMessageStore) TrySaveChat(ctx context.Context, id string, conversation jobModel.JobPayload) error {
 	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY), "chat Id", id)
 	if s.ValidateChatId(ctx, id) == false {
-		err := errors.New("invalid chat id")
+		err := jobModel.ErrChatNotFound
 		log.Error("Failed Validation before saving", "err", err)
 		return err
 	}
-	return s.saveChatId(ctx, id, conversation)
+	if err := s.saveChatId(ctx, id, conversation); err != nil {
+		return err
+	}
+	if err := s.touchChat(ctx, id, conversation.Question); err != nil {
+		log.Warn("Failed to update chat info", "err", err)
+	}
+	return nil
 }
 
 func (s *RedisMessageStore) saveChatId(ctx context.Context, id string, conversation jobModel.JobPayload) error {
 	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY), "chat Id", id)
-	err := s.store.ListPush(ctx, id, marshallJson(conversation, s.logger))
+	entry := jobModel.ChatEntry{JobPayload: conversation, Time: time.Now()}
+	data, err := sealRecord(ctx, s.sealer, schema.ChatEntry, entry)
+	if err != nil {
+		log.Error("error sealing chat", "error:", err)
+		return err
+	}
+	err = s.store.ListPush(ctx, id, data)
 	if err != nil {
 		log.Error("error saving chat", "error:", err)
 	}
@@ -57,33 +80,147 @@ func (s *RedisMessageStore) saveChatId(ctx context.Context, id string, conversat
 	return err
 }
 
-func (s *RedisMessageStore) InitNewChat(ctx context.Context, id string) error {
+func (s *RedisMessageStore) InitNewChat(ctx context.Context, id string, identity string) error {
 	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY).(string), "chat Id", id)
 	log.Debug("Initializing new chat")
-	err := s.store.Del(ctx, id)
+	err := s.store.Del(ctx, id, chatInfoKey(id))
 	if s.store.IsNil(err) {
 		log.Error("Error initializing chat", id)
 	}
+	now := time.Now()
+	if err = s.saveChatInfo(ctx, jobModel.ChatInfo{Id: id, Identity: identity, CreatedTime: now, UpdatedTime: now}); err != nil {
+		log.Error("Error saving chat info", "error", err)
+		return err
+	}
 	return s.saveChatId(ctx, id, jobModel.JobPayload{})
 }
 
-func marshallJson(payload jobModel.JobPayload, logger *logger_i.Logger) []byte {
-	data, err := json.Marshal(payload)
+func (s *RedisMessageStore) GetMessageHistory(ctx context.Context, chatId string) (error, []llm.Message) {
+	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY).(string), "chat Id", chatId)
+	log.Debug("Getting message history")
+
+	window := historyWindow()
+	//twice the turns leaves room for the entries toHistory skips
+	payloads, err := s.getPayloads(ctx, chatId, 2*window.MaxTurns)
 	if err != nil {
-		logger.Error("Error marshalling json :", err)
+		log.Error("Error getting history", "error:", err)
+		return err, nil
 	}
-	return data
+	return nil, toHistory(payloads, window)
 }
 
-func (s *RedisMessageStore) GetMessageHistory(ctx context.Context, chatId string) (error, []string) {
-	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY).(string), "chat Id", chatId)
-	log.Debug("Getting message history")
+// getPayloads the last count entries of the chat, or all of them for 0
+func (s *RedisMessageStore) getPayloads(ctx context.Context, chatId string, count int) ([]jobModel.ChatEntry, error) {
+	var res []string
+	var err error
+	if count > 0 {
+		res, err = s.store.ListGetLast(ctx, chatId, int64(count))
+	} else {
+		res, err = s.store.ListGetAll(ctx, chatId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := make([]jobModel.ChatEntry, 0, len(res))
+	for _, raw := range res {
+		var payload jobModel.ChatEntry
+		if err := openRecord(ctx, s.sealer, schema.ChatEntry, raw, &payload); err != nil {
+			s.logger.Warn("Skipping unreadable chat entry", "chat Id", chatId, "error", err)
+			continue
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
 
-	res, err := s.store.ListGet5PastMessage(ctx, chatId)
+func (s *RedisMessageStore) GetTurns(ctx context.Context, chatId string) ([]llm.Message, error) {
+	payloads, err := s.getPayloads(ctx, chatId, 0)
+	if err != nil {
+		return nil, err
+	}
+	return toHistory(payloads, llm.HistoryWindow{}), nil
+}
 
+func (s *RedisMessageStore) GetToolHistory(ctx context.Context, chatId string) ([]llm.Message, error) {
+	window := mcpHistoryWindow()
+	payloads, err := s.getPayloads(ctx, chatId, 2*window.MaxTurns)
 	if err != nil {
-		log.Error("Error getting history", "error:", err)
-		return err, nil
+		return nil, err
 	}
-	return nil, utils.ReverseStringArray(res)
+	return toToolHistory(payloads, window), nil
+}
+
+func (s *RedisMessageStore) GetSummary(ctx context.Context, chatId string) (jobModel.ChatSummary, bool, error) {
+	var summary jobModel.ChatSummary
+	data, err := s.store.Get(ctx, s.summaryKey(chatId))
+	if s.store.IsNil(err) {
+		return summary, false, nil
+	} else if err != nil {
+		return summary, false, err
+	}
+	if err = openRecord(ctx, s.sealer, schema.Summary, data, &summary); err != nil {
+		return summary, false, err
+	}
+	return summary, true, nil
+}
+
+// SaveSummary the summary is only written while the chat exists, checked in the same step so a chat
+// erased while its summary was generated stays erased
+func (s *RedisMessageStore) SaveSummary(ctx context.Context, chatId string, summary jobModel.ChatSummary) error {
+	data, err := sealRecord(ctx, s.sealer, schema.Summary, summary)
+	if err != nil {
+		return err
+	}
+	saved, err := s.store.SetIfExists(ctx, chatId, s.summaryKey(chatId), data, config.RedisMessageStoreTTL)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return jobModel.ErrChatNotFound
+	}
+	return nil
+}
+
+// summaryKey kept in the chat's cluster slot, see SaveSummary
+func (s *RedisMessageStore) summaryKey(chatId string) string {
+	return s.store.SlotKey(config.RedisSummaryPrefix, chatId)
+}
+
+func (s *RedisMessageStore) DeleteChat(ctx context.Context, chatId string) (bool, error) {
+	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY), "chat Id", chatId)
+	isFound, err := s.store.Exists(ctx, chatId)
+	if err != nil || !isFound {
+		return false, err
+	}
+	info, _, err := s.getChatInfo(ctx, chatId)
+	if err != nil {
+		log.Error("Error reading chat info", "error", err)
+		return false, err
+	}
+	//summaries saved under summary:<chat id> before it moved into the chat's slot live until they expire
+	if err = s.store.Del(ctx, chatId, s.summaryKey(chatId), config.RedisSummaryPrefix+chatId, chatInfoKey(chatId)); err != nil {
+		log.Error("Error deleting chat", "error", err)
+		return false, err
+	}
+	if info.Identity != "" {
+		if err = s.store.SortedSetRemove(ctx, chatIndexKey(info.Identity), chatId); err != nil {
+			log.Warn("Failed to drop chat from the index", "err", err)
+		}
+	}
+	log.Debug("Deleted chat")
+	return true, nil
+}
+
+func TestMessageStore(store *redisStore.Store) *RedisMessageStore {
+	return &RedisMessageStore{
+		store:  store,
+		logger: logger_i.NewLogger("test redis"),
+	}
+}
+
+func TestSealedMessageStore(store *redisStore.Store, sealer *encryption.Sealer) *RedisMessageStore {
+	messageStore := TestMessageStore(store)
+	messageStore.sealer = sealer
+	return messageStore
 }
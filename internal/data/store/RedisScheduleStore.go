@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/schema"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+//schedules live in the job store DB under a single hash with no TTL
+//so they survive restarts, unlike the jobs they create
+
+func (s *RedisJobStore) SaveSchedule(ctx context.Context, schedule jobModel.Schedule) error {
+	log := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY), "schedule Id", schedule.Id)
+	data, err := sealRecord(ctx, s.sealer, schema.Schedule, schedule)
+	if err != nil {
+		return err
+	}
+	err = s.store.HashSet(ctx, config.RedisScheduleKey, schedule.Id, data)
+	if err != nil {
+		log.Error("Error saving schedule", "error", err)
+		return err
+	}
+	log.Debug("Saved schedule to Redis")
+	return nil
+}
+
+func (s *RedisJobStore) GetSchedule(ctx context.Context, id string) (jobModel.Schedule, bool) {
+	var schedule jobModel.Schedule
+	val, err := s.store.HashGet(ctx, config.RedisScheduleKey, id)
+	if err != nil {
+		return schedule, false
+	}
+	if err = openRecord(ctx, s.sealer, schema.Schedule, val, &schedule); err != nil {
+		s.logger.Error("Error unmarshalling schedule", "schedule Id", id, "error", err)
+		return schedule, false
+	}
+	return schedule, true
+}
+
+func (s *RedisJobStore) ListSchedules(ctx context.Context) ([]jobModel.Schedule, error) {
+	all, err := s.store.HashGetAll(ctx, config.RedisScheduleKey)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]jobModel.Schedule, 0, len(all))
+	for id, val := range all {
+		var schedule jobModel.Schedule
+		if err = openRecord(ctx, s.sealer, schema.Schedule, val, &schedule); err != nil {
+			s.logger.Error("Skipping unreadable schedule", "schedule Id", id, "error", err)
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func (s *RedisJobStore) DeleteSchedule(ctx context.Context, id string) error {
+	return s.store.HashDel(ctx, config.RedisScheduleKey, id)
+}
+
+func (s *RedisJobStore) AcquireLeaderLock(ctx context.Context, name string, owner string, ttl time.Duration) bool {
+	key := config.RedisLockKeyPrefix + name
+	acquired, err := s.store.SetIfAbsent(ctx, key, owner, ttl)
+	if err != nil {
+		s.logger.Error("Error acquiring lock", "lock", key, "error", err)
+		return false
+	}
+	if acquired {
+		return true
+	}
+	//someone holds it - if it's us, keep it alive
+	extended, err := s.store.ExtendIfOwner(ctx, key, owner, ttl)
+	if err != nil {
+		s.logger.Error("Error extending lock", "lock", key, "error", err)
+		return false
+	}
+	return extended
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+// defaultTitle the start of the first question, cut at ChatTitleMaxLength runes
+func defaultTitle(question string) string {
+	title := []rune(strings.Join(strings.Fields(question), " "))
+	if len(title) > config.ChatTitleMaxLength {
+		title = title[:config.ChatTitleMaxLength]
+	}
+	return string(title)
+}
+
+// toTranscript the answered turns of the chat, the empty entry a chat starts with is left out
+func toTranscript(entries []jobModel.ChatEntry) []jobModel.ChatEntry {
+	transcript := make([]jobModel.ChatEntry, 0, len(entries))
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.Question) == "" {
+			continue
+		}
+		transcript = append(transcript, entry)
+	}
+	return transcript
+}
+
+func chatPageSize(query jobModel.ChatQuery) int {
+	if query.Limit <= 0 {
+		return config.JobListDefaultLimit
+	}
+	return min(query.Limit, config.JobListMaxLimit)
+}
+
+// toChatPage cuts the ordered chats down to one page, chats holds at most one chat past the page
+func toChatPage(chats []jobModel.ChatInfo, limit int) jobModel.ChatPage {
+	if len(chats) <= limit {
+		return jobModel.ChatPage{Chats: chats}
+	}
+	chats = chats[:limit]
+	return jobModel.ChatPage{Chats: chats, NextCursor: jobModel.ChatCursor(chats[limit-1]).Encode()}
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
+)
+
+// historyWindow the window from config, CHAT_HISTORY_MAX_TURNS and CHAT_HISTORY_MAX_TOKENS override it
+func historyWindow() llm.HistoryWindow {
+	return llm.HistoryWindow{
+		MaxTurns:  envInt("CHAT_HISTORY_MAX_TURNS", config.ChatHistoryMaxTurns),
+		MaxTokens: envInt("CHAT_HISTORY_MAX_TOKENS", config.ChatHistoryMaxTokens),
+	}
+}
+
+// mcpHistoryWindow the window MCP requests replay, MCP_HISTORY_MAX_TURNS and MCP_HISTORY_MAX_TOKENS override it
+func mcpHistoryWindow() llm.HistoryWindow {
+	return llm.HistoryWindow{
+		MaxTurns:  envInt("MCP_HISTORY_MAX_TURNS", config.MCPHistoryMaxTurns),
+		MaxTokens: envInt("MCP_HISTORY_MAX_TOKENS", config.MCPHistoryMaxTokens),
+	}
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+// toHistory turns the saved entries, oldest first, into user/assistant turns that fit the window.
+// The empty entry a chat starts with and anything missing its question or answer are left out.
+func toHistory(entries []jobModel.ChatEntry, window llm.HistoryWindow) []llm.Message {
+	history := make([]llm.Message, 0, 2*len(entries))
+	for _, payload := range entries {
+		if strings.TrimSpace(payload.Question) == "" || strings.TrimSpace(payload.Answer) == "" {
+			continue
+		}
+		history = append(history,
+			llm.TextMessage(llm.RoleUser, payload.Question),
+			llm.TextMessage(llm.RoleAssistant, payload.Answer))
+	}
+	return window.Apply(history)
+}
+
+// toToolHistory is toHistory keeping the tool calls and results of MCP turns between their question and answer,
+// a turn is only ever kept or dropped whole
+func toToolHistory(entries []jobModel.ChatEntry, window llm.HistoryWindow) []llm.Message {
+	turns := make([][]llm.Message, 0, len(entries))
+	for _, payload := range entries {
+		if strings.TrimSpace(payload.Question) == "" || strings.TrimSpace(payload.Answer) == "" {
+			continue
+		}
+		turn := make([]llm.Message, 0, len(payload.ToolMessages)+2)
+		turn = append(turn, llm.TextMessage(llm.RoleUser, payload.Question))
+		turn = append(turn, payload.ToolMessages...)
+		turns = append(turns, append(turn, llm.TextMessage(llm.RoleAssistant, payload.Answer)))
+	}
+	return window.ApplyTurns(turns)
+}
@@ -0,0 +1,22 @@
+package store
+
+import (
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func pageSize(query jobModel.JobQuery) int {
+	if query.Limit <= 0 {
+		return config.JobListDefaultLimit
+	}
+	return min(query.Limit, config.JobListMaxLimit)
+}
+
+// toPage cuts the ordered jobs down to one page, jobs holds at most one job past the page
+func toPage(jobs []jobModel.Job, limit int) jobModel.JobPage {
+	if len(jobs) <= limit {
+		return jobModel.JobPage{Jobs: jobs}
+	}
+	jobs = jobs[:limit]
+	return jobModel.JobPage{Jobs: jobs, NextCursor: jobModel.CursorOf(jobs[limit-1]).Encode()}
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"math"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/encryption"
+	"github.com/akolanti/GoAPI/internal/data/schema"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+//cmd/migrate rewrites records saved with an older schema version, reads upgrade them either way.
+//Every rewrite is a compare and set, so it can run next to the API and the workers
+
+// MigrationReport Upgraded records were rewritten (or would be on a dry run), Changed ones were saved
+// again by the service between the read and the write and are left for the next run
+type MigrationReport struct {
+	Scanned  int
+	Upgraded int
+	Changed  int
+	Failed   int
+}
+
+func (r *MigrationReport) Add(other MigrationReport) {
+	r.Scanned += other.Scanned
+	r.Upgraded += other.Upgraded
+	r.Changed += other.Changed
+	r.Failed += other.Failed
+}
+
+type migration struct {
+	sealer *encryption.Sealer
+	logger *logger_i.Logger
+	dryRun bool
+	report MigrationReport
+}
+
+// migrateRecord decodes raw and hands the current encoding to write if it was older, write reports whether it was swapped
+func migrateRecord[T any](ctx context.Context, m *migration, kind schema.Kind, raw string, write func(value string) (bool, error)) {
+	m.report.Scanned++
+	var value T
+	upgraded, err := decodeRecord(ctx, m.sealer, kind, raw, &value)
+	if err != nil {
+		m.report.Failed++
+		m.logger.Warn("Skipping unreadable record", "kind", kind, "err", err)
+		return
+	}
+	if !upgraded {
+		return
+	}
+	if m.dryRun {
+		m.report.Upgraded++
+		return
+	}
+	data, err := sealRecord(ctx, m.sealer, kind, value)
+	if err != nil {
+		m.report.Failed++
+		m.logger.Warn("Failed to encode record", "kind", kind, "err", err)
+		return
+	}
+	swapped, err := write(data)
+	switch {
+	case err != nil:
+		m.report.Failed++
+		m.logger.Warn("Failed to rewrite record", "kind", kind, "err", err)
+	case swapped:
+		m.report.Upgraded++
+	default:
+		m.report.Changed++
+	}
+}
+
+// MigrateRecords upgrades the jobs, found through the job index, and the schedules. Queued jobs are
+// upgraded when a worker takes them
+func (s *RedisJobStore) MigrateRecords(ctx context.Context, dryRun bool) (MigrationReport, error) {
+	m := &migration{sealer: s.sealer, logger: s.logger, dryRun: dryRun}
+	key := allJobsIndexKey()
+	var offset int64
+	for ctx.Err() == nil {
+		members, err := s.store.SortedSetRangeDesc(ctx, key, math.Inf(1), math.Inf(-1), offset, config.MigrationBatchSize)
+		if err != nil {
+			return m.report, err
+		}
+		offset += int64(len(members))
+		ids := make([]string, len(members))
+		for i, member := range members {
+			ids[i] = member.Member
+		}
+		values, found, err := s.store.MGet(ctx, ids...)
+		if err != nil {
+			return m.report, err
+		}
+		for i, raw := range values {
+			if !found[i] {
+				continue //expired, ListJobs drops the index entry
+			}
+			migrateRecord[jobModel.Job](ctx, m, schema.Job, raw, func(value string) (bool, error) {
+				return s.store.SetIfUnchanged(ctx, ids[i], raw, value)
+			})
+		}
+		if int64(len(members)) < config.MigrationBatchSize {
+			break
+		}
+	}
+
+	if ctx.Err() != nil {
+		return m.report, ctx.Err()
+	}
+	schedules, err := s.store.HashGetAll(ctx, config.RedisScheduleKey)
+	if err != nil {
+		return m.report, err
+	}
+	for id, raw := range schedules {
+		migrateRecord[jobModel.Schedule](ctx, m, schema.Schedule, raw, func(value string) (bool, error) {
+			return s.store.HashSetIfUnchanged(ctx, config.RedisScheduleKey, id, raw, value)
+		})
+	}
+	return m.report, nil
+}
+
+// MigrateRecords upgrades the entries of every chat, the chats are the only lists in the message store
+func (s *RedisMessageStore) MigrateRecords(ctx context.Context, dryRun bool) (MigrationReport, error) {
+	m := &migration{sealer: s.sealer, logger: s.logger, dryRun: dryRun}
+	err := s.store.ScanKeys(ctx, "list", func(chatId string) error {
+		entries, err := s.store.ListGetAll(ctx, chatId)
+		if err != nil {
+			return err
+		}
+		for i, raw := range entries {
+			migrateRecord[jobModel.ChatEntry](ctx, m, schema.ChatEntry, raw, func(value string) (bool, error) {
+				//turns are only ever pushed at the tail, an entry keeps its index
+				return s.store.ListSetIfUnchanged(ctx, chatId, int64(i), raw, value)
+			})
+		}
+		return ctx.Err()
+	})
+	return m.report, err
+}
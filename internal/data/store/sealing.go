@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+
+	"github.com/akolanti/GoAPI/internal/data/encryption"
+	"github.com/akolanti/GoAPI/internal/data/schema"
+)
+
+//every record that carries a question or an answer goes through these on its way to and from redis:
+//versioned by the schema package, then sealed. A nil sealer leaves them as plain JSON
+
+func sealRecord(ctx context.Context, sealer *encryption.Sealer, kind schema.Kind, value any) (string, error) {
+	data, err := schema.Encode(kind, value)
+	if err != nil {
+		return "", err
+	}
+	return sealer.Seal(ctx, data)
+}
+
+func openRecord(ctx context.Context, sealer *encryption.Sealer, kind schema.Kind, raw string, value any) error {
+	_, err := decodeRecord(ctx, sealer, kind, raw, value)
+	return err
+}
+
+// decodeRecord upgraded is true for records saved with an older schema version
+func decodeRecord(ctx context.Context, sealer *encryption.Sealer, kind schema.Kind, raw string, value any) (bool, error) {
+	data, err := sealer.Open(ctx, raw)
+	if err != nil {
+		return false, err
+	}
+	return schema.Decode(kind, data, value)
+}
+
+func sealText(ctx context.Context, sealer *encryption.Sealer, text string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	return sealer.Seal(ctx, []byte(text))
+}
+
+func openText(ctx context.Context, sealer *encryption.Sealer, raw string) (string, error) {
+	data, err := sealer.Open(ctx, raw)
+	return string(data), err
+}
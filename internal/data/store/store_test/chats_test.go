@@ -0,0 +1,104 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func TestMessageStores_ChatsAreListedPerIdentity(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "chats-trace")
+
+	for name, messageStore := range messageStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, id := range []string{"chat-a1", "chat-a2", "chat-a3"} {
+				_ = messageStore.InitNewChat(ctx, id, "alice")
+				time.Sleep(2 * time.Millisecond) //distinct created times
+			}
+			_ = messageStore.InitNewChat(ctx, "chat-b1", "bob")
+			_ = messageStore.InitNewChat(ctx, "chat-anonymous", "")
+
+			first, err := messageStore.ListChats(ctx, jobModel.ChatQuery{Identity: "alice", Limit: 2})
+			if err != nil {
+				t.Fatalf("ListChats failed: %v", err)
+			}
+			if len(first.Chats) != 2 || first.Chats[0].Id != "chat-a3" || first.Chats[1].Id != "chat-a2" || first.NextCursor == "" {
+				t.Fatalf("Expected the two newest chats of alice and a cursor, got %+v", first)
+			}
+			second, err := messageStore.ListChats(ctx, jobModel.ChatQuery{Identity: "alice", Limit: 2, Cursor: first.NextCursor})
+			if err != nil {
+				t.Fatalf("ListChats failed: %v", err)
+			}
+			if len(second.Chats) != 1 || second.Chats[0].Id != "chat-a1" || second.NextCursor != "" {
+				t.Errorf("Expected the last chat of alice without a cursor, got %+v", second)
+			}
+
+			if anonymous, _ := messageStore.ListChats(ctx, jobModel.ChatQuery{}); len(anonymous.Chats) != 0 {
+				t.Errorf("Expected chats without an identity not to be listed, got %+v", anonymous.Chats)
+			}
+			if _, err = messageStore.ListChats(ctx, jobModel.ChatQuery{Identity: "alice", Cursor: "not a cursor"}); err != jobModel.ErrInvalidCursor {
+				t.Errorf("Expected ErrInvalidCursor, got %v", err)
+			}
+
+			chat, found, err := messageStore.GetChat(ctx, "chat-b1")
+			if err != nil || !found || chat.Identity != "bob" || !chat.OwnedBy("bob") || chat.OwnedBy("alice") {
+				t.Errorf("Expected chat-b1 to belong to bob, got %+v %v %v", chat, found, err)
+			}
+		})
+	}
+}
+
+func TestMessageStores_TranscriptRenameAndDelete(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "chats-trace")
+
+	for name, messageStore := range messageStores(t) {
+		t.Run(name, func(t *testing.T) {
+			_ = messageStore.InitNewChat(ctx, "chat-1", "alice")
+			_ = messageStore.TrySaveChat(ctx, "chat-1", jobModel.JobPayload{Question: "  How often is the   filter changed?", Answer: "Every 500 hours.", Sources: []jobModel.Source{{DocumentName: "manual.pdf"}}})
+			_ = messageStore.TrySaveChat(ctx, "chat-1", jobModel.JobPayload{Question: "And the oil?", Answer: "Every 1000 hours."})
+
+			transcript, err := messageStore.GetTranscript(ctx, "chat-1")
+			if err != nil {
+				t.Fatalf("GetTranscript failed: %v", err)
+			}
+			if len(transcript) != 2 || transcript[0].Answer != "Every 500 hours." || transcript[1].Question != "And the oil?" {
+				t.Fatalf("Expected both turns oldest first, got %+v", transcript)
+			}
+			if len(transcript[0].Sources) != 1 || transcript[0].Time.IsZero() {
+				t.Errorf("Expected the turn to keep its sources and time, got %+v", transcript[0])
+			}
+
+			chat, _, _ := messageStore.GetChat(ctx, "chat-1")
+			if chat.Title != "How often is the filter changed?" {
+				t.Errorf("Expected the first question as the title, got %q", chat.Title)
+			}
+			if chat.UpdatedTime.Before(chat.CreatedTime) || chat.CreatedTime.IsZero() {
+				t.Errorf("Expected created and updated times, got %+v", chat)
+			}
+
+			if err = messageStore.RenameChat(ctx, "chat-1", "Filters"); err != nil {
+				t.Fatalf("RenameChat failed: %v", err)
+			}
+			_ = messageStore.TrySaveChat(ctx, "chat-1", jobModel.JobPayload{Question: "Anything else?", Answer: "No."})
+			if chat, _, _ = messageStore.GetChat(ctx, "chat-1"); chat.Title != "Filters" {
+				t.Errorf("Expected the new title to stick, got %q", chat.Title)
+			}
+			if err = messageStore.RenameChat(ctx, "missing", "Filters"); err != jobModel.ErrChatNotFound {
+				t.Errorf("Expected ErrChatNotFound renaming an unknown chat, got %v", err)
+			}
+
+			if deleted, err := messageStore.DeleteChat(ctx, "chat-1"); err != nil || !deleted {
+				t.Fatalf("DeleteChat failed: %v %v", deleted, err)
+			}
+			if _, found, _ := messageStore.GetChat(ctx, "chat-1"); found {
+				t.Error("Expected the chat to be gone")
+			}
+			if page, _ := messageStore.ListChats(ctx, jobModel.ChatQuery{Identity: "alice"}); len(page.Chats) != 0 {
+				t.Errorf("Expected the deleted chat not to be listed, got %+v", page.Chats)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/data/store/storetest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestJobStores_Conformance(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		storetest.RunJobStoreSuite(t, func(t *testing.T) storetest.JobStoreHarness {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			return storetest.JobStoreHarness{Store: store.TestJobStore(redisStore.NewTestStore(client)), Advance: mr.FastForward}
+		})
+	})
+	t.Run("redis prefixed", func(t *testing.T) {
+		storetest.RunJobStoreSuite(t, func(t *testing.T) storetest.JobStoreHarness {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			return storetest.JobStoreHarness{Store: store.TestJobStore(redisStore.NewTestStoreWithPrefix(client, config.RedisJobKeyPrefix)), Advance: mr.FastForward}
+		})
+	})
+	t.Run("redis encrypted", func(t *testing.T) {
+		storetest.RunJobStoreSuite(t, func(t *testing.T) storetest.JobStoreHarness {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			return storetest.JobStoreHarness{Store: store.TestSealedJobStore(redisStore.NewTestStore(client), testSealer(t, "k1")), Advance: mr.FastForward}
+		})
+	})
+	t.Run("in memory", func(t *testing.T) {
+		storetest.RunJobStoreSuite(t, func(t *testing.T) storetest.JobStoreHarness {
+			clock := storetest.NewClock()
+			return storetest.JobStoreHarness{Store: store.TestInMemoryJobStore(clock.Now), Advance: clock.Advance}
+		})
+	})
+}
+
+func TestMessageStores_Conformance(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		storetest.RunMessageStoreSuite(t, func(t *testing.T) storetest.MessageStoreHarness {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			return storetest.MessageStoreHarness{Store: store.TestMessageStore(redisStore.NewTestStore(client)), Advance: mr.FastForward}
+		})
+	})
+	t.Run("redis prefixed", func(t *testing.T) {
+		storetest.RunMessageStoreSuite(t, func(t *testing.T) storetest.MessageStoreHarness {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			return storetest.MessageStoreHarness{Store: store.TestMessageStore(redisStore.NewTestStoreWithPrefix(client, config.RedisMessageKeyPrefix)), Advance: mr.FastForward}
+		})
+	})
+	t.Run("redis encrypted", func(t *testing.T) {
+		storetest.RunMessageStoreSuite(t, func(t *testing.T) storetest.MessageStoreHarness {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			return storetest.MessageStoreHarness{Store: store.TestSealedMessageStore(redisStore.NewTestStore(client), testSealer(t, "k1")), Advance: mr.FastForward}
+		})
+	})
+	t.Run("in memory", func(t *testing.T) {
+		storetest.RunMessageStoreSuite(t, func(t *testing.T) storetest.MessageStoreHarness {
+			clock := storetest.NewClock()
+			return storetest.MessageStoreHarness{Store: store.TestInMemoryMessageStore(clock.Now), Advance: clock.Advance}
+		})
+	})
+}
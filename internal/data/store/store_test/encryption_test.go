@@ -0,0 +1,114 @@
+package store_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/encryption"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testSealer a sealer over a fresh key file holding the given key ids, the last one current
+func testSealer(t *testing.T, keyIds ...string) *encryption.Sealer {
+	var lines []string
+	for _, id := range keyIds {
+		key := make([]byte, 32)
+		_, _ = rand.Read(key)
+		lines = append(lines, id+"="+base64.StdEncoding.EncodeToString(key))
+	}
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	provider, err := encryption.NewLocalKeyProvider(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encryption.NewSealer(provider)
+}
+
+func TestRedisStores_EncryptAtRest(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	sealer := testSealer(t, "k1")
+	jobStore := store.TestSealedJobStore(redisStore.NewTestStore(client), sealer)
+	messageStore := store.TestSealedMessageStore(redisStore.NewTestStore(client), sealer)
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "encryption-trace")
+
+	payload := jobModel.JobPayload{Question: "secret question", Answer: "secret answer"}
+	job := jobModel.Job{Id: "job-1", ChatId: "chat-1", JobType: jobModel.JobTypeQuery, CreatedTime: time.Now(), JobPayload: payload}
+	_ = jobStore.SaveJob(ctx, job)
+	queue := store.NewRedisJobQueue(jobStore)
+	_ = queue.Enqueue(ctx, job)
+	_ = jobStore.AppendEvent(ctx, "job-1", jobModel.StreamEvent{Type: jobModel.StreamEventDelta, Data: "secret answer"})
+	_ = jobStore.SaveSchedule(ctx, jobModel.Schedule{Id: "schedule-1", JobPayload: payload})
+	_ = messageStore.InitNewChat(ctx, "chat-1", "alice")
+	_ = messageStore.TrySaveChat(ctx, "chat-1", payload)
+	_ = messageStore.SaveSummary(ctx, "chat-1", jobModel.ChatSummary{Text: "secret summary", Turns: 1})
+
+	if dump := mr.Dump(); strings.Contains(dump, "secret") {
+		t.Fatalf("Expected nothing readable at rest, got\n%s", dump)
+	}
+
+	if got, found := jobStore.GetJob(ctx, "job-1"); !found || got.JobPayload.Question != payload.Question || got.JobPayload.Answer != payload.Answer {
+		t.Errorf("Expected the job back in the clear, got %+v", got)
+	}
+	if queued, found, _ := queue.Dequeue(ctx, time.Second); !found || queued.JobPayload.Question != payload.Question {
+		t.Errorf("Expected the queued job back in the clear, got %+v", queued)
+	}
+	if events, _ := jobStore.ReadEvents(ctx, "job-1", ""); len(events) != 1 || events[0].Data != "secret answer" {
+		t.Errorf("Expected the event back in the clear, got %+v", events)
+	}
+	if schedule, found := jobStore.GetSchedule(ctx, "schedule-1"); !found || schedule.JobPayload.Question != payload.Question {
+		t.Errorf("Expected the schedule back in the clear, got %+v", schedule)
+	}
+	if page, _ := jobStore.ListJobs(ctx, jobModel.JobQuery{ChatId: "chat-1"}); len(page.Jobs) != 1 || page.Jobs[0].JobPayload.Answer != payload.Answer {
+		t.Errorf("Expected the listed job in the clear, got %+v", page.Jobs)
+	}
+	err, history := messageStore.GetMessageHistory(ctx, "chat-1")
+	if err != nil || len(history) != 2 || history[0].Text() != "secret question" || history[1].Text() != "secret answer" {
+		t.Errorf("Expected the history in the clear, got %+v %v", history, err)
+	}
+	if summary, found, _ := messageStore.GetSummary(ctx, "chat-1"); !found || summary.Text != "secret summary" {
+		t.Errorf("Expected the summary in the clear, got %+v", summary)
+	}
+	if chat, _, _ := messageStore.GetChat(ctx, "chat-1"); chat.Title != "secret question" {
+		t.Errorf("Expected the title in the clear, got %q", chat.Title)
+	}
+}
+
+// TestRedisStores_ReadRecordsFromBeforeEncryption turning encryption on keeps the plaintext records readable
+func TestRedisStores_ReadRecordsFromBeforeEncryption(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "encryption-trace")
+
+	plain := store.TestMessageStore(redisStore.NewTestStore(client))
+	_ = plain.InitNewChat(ctx, "chat-1", "alice")
+	_ = plain.TrySaveChat(ctx, "chat-1", jobModel.JobPayload{Question: "q1", Answer: "a1"})
+
+	sealed := store.TestSealedMessageStore(redisStore.NewTestStore(client), testSealer(t, "k1"))
+	_ = sealed.TrySaveChat(ctx, "chat-1", jobModel.JobPayload{Question: "q2", Answer: "a2"})
+
+	transcript, err := sealed.GetTranscript(ctx, "chat-1")
+	if err != nil || len(transcript) != 2 || transcript[0].Question != "q1" || transcript[1].Question != "q2" {
+		t.Errorf("Expected the plaintext and the sealed turn, got %+v %v", transcript, err)
+	}
+
+	//without the keys a sealed turn can't be read and is skipped, the plaintext one still is
+	transcript, _ = plain.GetTranscript(ctx, "chat-1")
+	if len(transcript) != 1 || transcript[0].Question != "q1" {
+		t.Errorf("Expected only the plaintext turn without keys, got %+v", transcript)
+	}
+}
@@ -0,0 +1,97 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestEventStores_ResumeAfterLastEvent(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	stores := map[string]jobModel.EventStore{
+		"redis":     store.TestJobStore(redisStore.NewTestStore(client)),
+		"in memory": store.InitInMemoryJobStore(),
+	}
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "stream-trace")
+
+	for name, eventStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			for _, event := range []jobModel.StreamEvent{
+				{Type: jobModel.StreamEventStep, Data: `{"status":"RUNNING","step":"LLM"}`},
+				{Type: jobModel.StreamEventDelta, Data: "Hello"},
+				{Type: jobModel.StreamEventDelta, Data: " world\n"},
+			} {
+				if err := eventStore.AppendEvent(ctx, "stream-job", event); err != nil {
+					t.Fatalf("AppendEvent failed: %v", err)
+				}
+			}
+
+			all, err := eventStore.ReadEvents(ctx, "stream-job", "")
+			if err != nil || len(all) != 3 {
+				t.Fatalf("Expected 3 events, got %d (err %v)", len(all), err)
+			}
+			if all[2].Type != jobModel.StreamEventDelta || all[2].Data != " world\n" {
+				t.Errorf("Event data mismatch, got %+v", all[2])
+			}
+
+			//a client that saw the first event resumes after it
+			rest, err := eventStore.ReadEvents(ctx, "stream-job", all[0].Id)
+			if err != nil {
+				t.Fatalf("ReadEvents failed: %v", err)
+			}
+			if len(rest) != 2 || rest[0].Id != all[1].Id {
+				t.Errorf("Resume returned the wrong events: %+v", rest)
+			}
+
+			caughtUp, _ := eventStore.ReadEvents(ctx, "stream-job", all[2].Id)
+			if len(caughtUp) != 0 {
+				t.Errorf("Expected nothing after the last event, got %+v", caughtUp)
+			}
+		})
+	}
+
+	if ttl := mr.TTL(config.RedisEventKeyPrefix + "stream-job"); ttl != config.RedisJobStoreTTL {
+		t.Errorf("Event log should expire with the job, ttl %v", ttl)
+	}
+}
+
+func TestStores_DeleteChatAndEvents(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "erase-trace")
+
+	type stores struct {
+		messages jobModel.MessageStore
+		events   jobModel.EventStore
+	}
+	for name, s := range map[string]stores{
+		"redis":     {store.TestMessageStore(redisStore.NewTestStore(client)), store.TestJobStore(redisStore.NewTestStore(client))},
+		"in memory": {store.InitMessageStore(), store.InitInMemoryJobStore()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_ = s.messages.InitNewChat(ctx, "erase-chat", "")
+			if removed, err := s.messages.DeleteChat(ctx, "erase-chat"); !removed || err != nil {
+				t.Fatalf("DeleteChat = %v, %v", removed, err)
+			}
+			if removed, _ := s.messages.DeleteChat(ctx, "erase-chat"); removed {
+				t.Error("Deleting a missing chat reported a removal")
+			}
+
+			_ = s.events.AppendEvent(ctx, "erase-job", jobModel.StreamEvent{Type: jobModel.StreamEventDelta, Data: "hi"})
+			if err := s.events.DeleteEvents(ctx, "erase-job"); err != nil {
+				t.Fatalf("DeleteEvents failed: %v", err)
+			}
+			if events, _ := s.events.ReadEvents(ctx, "erase-job", ""); len(events) != 0 {
+				t.Errorf("Events survived deletion: %+v", events)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/jobEvents"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisJobStore_JobEventsAcrossReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), config.TRACE_ID_KEY, "events-trace"))
+	defer cancel()
+
+	//two clients on the same redis stand in for two replicas
+	listener := store.TestJobStore(redisStore.NewTestStore(redis.NewClient(&redis.Options{Addr: mr.Addr()})))
+	writer := store.TestJobStore(redisStore.NewTestStore(redis.NewClient(&redis.Options{Addr: mr.Addr()})))
+	go listener.ListenForJobEvents(ctx)
+
+	//wait for the subscription to be registered before saving
+	deadline := time.Now().Add(2 * time.Second)
+	for mr.PubSubNumSub(config.RedisJobEventsChannel)[config.RedisJobEventsChannel] == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Listener never subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	updates, unsubscribe := jobEvents.Subscribe("remote-job")
+	defer unsubscribe()
+	//published straight to redis so the only way to get notified is through the listener
+	if err := redis.NewClient(&redis.Options{Addr: mr.Addr()}).Publish(ctx, config.RedisJobEventsChannel, "remote-job").Err(); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	select {
+	case <-updates:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Job event from another replica was not relayed")
+	}
+
+	t.Run("SaveJob publishes", func(t *testing.T) {
+		saved, unsubscribeSaved := jobEvents.Subscribe("saved-job")
+		defer unsubscribeSaved()
+		if err := writer.SaveJob(ctx, jobModel.Job{Id: "saved-job", Status: jobModel.JobStatusRunning}); err != nil {
+			t.Fatalf("SaveJob failed: %v", err)
+		}
+		select {
+		case <-saved:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Saving a job did not notify its subscribers")
+		}
+	})
+}
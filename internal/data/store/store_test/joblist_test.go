@@ -0,0 +1,126 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func jobStores(t *testing.T) map[string]jobModel.JobStore {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return map[string]jobModel.JobStore{
+		"redis":     store.TestJobStore(redisStore.NewTestStore(client)),
+		"in memory": store.InitInMemoryJobStore(),
+	}
+}
+
+func TestJobStores_ListJobsFiltersAndPages(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "list-trace")
+	base := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+
+	for name, jobStore := range jobStores(t) {
+		t.Run(name, func(t *testing.T) {
+			//five jobs of chat-a a minute apart, job-4 the newest, plus one ingest of another user
+			for i := 0; i < 5; i++ {
+				job := jobModel.Job{
+					Id:          fmt.Sprintf("job-%d", i),
+					ChatId:      "chat-a",
+					Identity:    "alice",
+					JobType:     jobModel.JobTypeQuery,
+					Status:      jobModel.JobStatusComplete,
+					CreatedTime: base.Add(time.Duration(i) * time.Minute),
+				}
+				if err := jobStore.SaveJob(ctx, job); err != nil {
+					t.Fatalf("SaveJob failed: %v", err)
+				}
+			}
+			_ = jobStore.SaveJob(ctx, jobModel.Job{
+				Id:          "ingest-1",
+				Identity:    "bob",
+				JobType:     jobModel.JobTypeIngest,
+				Status:      jobModel.JobStatusRunning,
+				CreatedTime: base.Add(10 * time.Minute),
+			})
+
+			first, err := jobStore.ListJobs(ctx, jobModel.JobQuery{ChatId: "chat-a", Limit: 2})
+			if err != nil {
+				t.Fatalf("ListJobs failed: %v", err)
+			}
+			if len(first.Jobs) != 2 || first.Jobs[0].Id != "job-4" || first.Jobs[1].Id != "job-3" || first.NextCursor == "" {
+				t.Fatalf("Unexpected first page: %+v", first)
+			}
+
+			var seen []string
+			for page := first; ; {
+				for _, job := range page.Jobs {
+					seen = append(seen, job.Id)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				page, err = jobStore.ListJobs(ctx, jobModel.JobQuery{ChatId: "chat-a", Limit: 2, Cursor: page.NextCursor})
+				if err != nil {
+					t.Fatalf("ListJobs with cursor failed: %v", err)
+				}
+			}
+			if fmt.Sprint(seen) != "[job-4 job-3 job-2 job-1 job-0]" {
+				t.Errorf("Paging returned %v", seen)
+			}
+
+			ranged, _ := jobStore.ListJobs(ctx, jobModel.JobQuery{
+				Identity:    "alice",
+				CreatedFrom: base.Add(time.Minute),
+				CreatedTo:   base.Add(3 * time.Minute),
+			})
+			if len(ranged.Jobs) != 2 || ranged.Jobs[0].Id != "job-2" || ranged.Jobs[1].Id != "job-1" {
+				t.Errorf("Time range returned %+v", ranged.Jobs)
+			}
+
+			running, _ := jobStore.ListJobs(ctx, jobModel.JobQuery{Status: jobModel.JobStatusRunning, JobType: jobModel.JobTypeIngest})
+			if len(running.Jobs) != 1 || running.Jobs[0].Id != "ingest-1" {
+				t.Errorf("Status and type filter returned %+v", running.Jobs)
+			}
+
+			if _, err := jobStore.ListJobs(ctx, jobModel.JobQuery{Cursor: "not a cursor"}); err == nil {
+				t.Error("Expected an invalid cursor to fail")
+			}
+		})
+	}
+}
+
+func TestJobStores_ListJobsFollowsStatusChanges(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "list-trace")
+
+	for name, jobStore := range jobStores(t) {
+		t.Run(name, func(t *testing.T) {
+			job := jobModel.Job{Id: "moving", JobType: jobModel.JobTypeQuery, Status: jobModel.JobStatusQueued, CreatedTime: time.Now()}
+			_ = jobStore.SaveJob(ctx, job)
+			job.Status = jobModel.JobStatusComplete
+			_ = jobStore.SaveJob(ctx, job)
+
+			queued, _ := jobStore.ListJobs(ctx, jobModel.JobQuery{Status: jobModel.JobStatusQueued})
+			if len(queued.Jobs) != 0 {
+				t.Errorf("Job still listed under its old status: %+v", queued.Jobs)
+			}
+			complete, _ := jobStore.ListJobs(ctx, jobModel.JobQuery{Status: jobModel.JobStatusComplete})
+			if len(complete.Jobs) != 1 {
+				t.Errorf("Job missing under its new status: %+v", complete.Jobs)
+			}
+
+			jobStore.DeleteJob(ctx, "moving")
+			all, _ := jobStore.ListJobs(ctx, jobModel.JobQuery{})
+			if len(all.Jobs) != 0 {
+				t.Errorf("Deleted job still listed: %+v", all.Jobs)
+			}
+		})
+	}
+}
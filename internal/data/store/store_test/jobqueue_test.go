@@ -0,0 +1,98 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisJobQueue_OrderAndRequeue(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	queue := store.NewRedisJobQueue(store.TestJobStore(redisStore.NewTestStore(client)))
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "queue-trace")
+
+	for _, id := range []string{"first", "second"} {
+		if err := queue.Enqueue(ctx, jobModel.Job{Id: id, JobType: jobModel.JobTypeQuery}); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+
+	taken, found, err := queue.Dequeue(ctx, time.Second)
+	if err != nil || !found || taken.Id != "first" {
+		t.Fatalf("Expected the first job, got %+v (found %v, err %v)", taken, found, err)
+	}
+
+	//a worker shutting down hands the job back, it goes ahead of the rest
+	if err = queue.Requeue(ctx, taken); err != nil {
+		t.Fatalf("Requeue failed: %v", err)
+	}
+	for _, want := range []string{"first", "second"} {
+		taken, found, _ = queue.Dequeue(ctx, time.Second)
+		if !found || taken.Id != want {
+			t.Errorf("Expected %s, got %+v", want, taken)
+		}
+	}
+
+	if _, found, err = queue.Dequeue(ctx, 100*time.Millisecond); found || err != nil {
+		t.Errorf("Empty queue returned found %v, err %v", found, err)
+	}
+}
+
+func TestRedisJobQueue_ReapsTheJobsOfAStoppedProcess(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	jobStore := store.TestJobStore(redisStore.NewTestStore(client))
+	stopped, survivor := store.NewRedisJobQueue(jobStore), store.NewRedisJobQueue(jobStore)
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "reap-trace")
+
+	for _, id := range []string{"done", "running"} {
+		_ = stopped.Enqueue(ctx, jobModel.Job{Id: id, JobType: jobModel.JobTypeQuery})
+	}
+	_ = stopped.Heartbeat(ctx)
+	done, _, _ := stopped.Dequeue(ctx, time.Second)
+	_, _, _ = stopped.Dequeue(ctx, time.Second)
+	if err := stopped.Ack(ctx, done); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+
+	if reaped, err := survivor.Reap(ctx); err != nil || len(reaped) != 0 {
+		t.Fatalf("Reaped a live process: %+v, err %v", reaped, err)
+	}
+
+	//the stopped process' last beat is older than the TTL
+	workers, _ := client.ZRange(ctx, config.RedisJobQueueWorkersKey, 0, -1).Result()
+	stale := float64(time.Now().Add(-2 * config.JobQueueWorkerTTL).UnixMilli())
+	client.ZAdd(ctx, config.RedisJobQueueWorkersKey, redis.Z{Score: stale, Member: workers[0]})
+
+	reaped, err := survivor.Reap(ctx)
+	if err != nil || len(reaped) != 1 || reaped[0].Id != "running" {
+		t.Fatalf("Expected only the unacked job reaped, got %+v, err %v", reaped, err)
+	}
+	if left, _ := client.ZCard(ctx, config.RedisJobQueueWorkersKey).Result(); left != 0 {
+		t.Errorf("Expected the stopped process forgotten, %d left", left)
+	}
+
+	//the reaped job is held by the survivor now, requeueing it takes it off its processing list
+	if err = survivor.Requeue(ctx, reaped[0]); err != nil {
+		t.Fatalf("Requeue failed: %v", err)
+	}
+	if taken, found, _ := survivor.Dequeue(ctx, time.Second); !found || taken.Id != "running" {
+		t.Errorf("Expected the reaped job back on the queue, got %+v", taken)
+	}
+	if err = survivor.Ack(ctx, reaped[0]); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+	for _, key := range mr.Keys() {
+		if key != config.RedisJobQueueWorkersKey {
+			t.Errorf("Expected every list empty, %s is left", key)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func messageStores(t *testing.T) map[string]jobModel.MessageStore {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return map[string]jobModel.MessageStore{
+		"redis":     store.TestMessageStore(redisStore.NewTestStore(client)),
+		"in memory": store.InitMessageStore(),
+	}
+}
+
+func TestMessageStores_HistoryIsTypedAndWindowed(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "history-trace")
+	t.Setenv("CHAT_HISTORY_MAX_TURNS", "2")
+	t.Setenv("CHAT_HISTORY_MAX_TOKENS", "0")
+
+	for name, messageStore := range messageStores(t) {
+		t.Run(name, func(t *testing.T) {
+			_ = messageStore.InitNewChat(ctx, "chat-1", "")
+			for i := 1; i <= 3; i++ {
+				_ = messageStore.TrySaveChat(ctx, "chat-1", jobModel.JobPayload{Question: fmt.Sprintf("q%d", i), Answer: fmt.Sprintf("a%d", i)})
+			}
+			//a turn without an answer never reaches the prompt
+			_ = messageStore.TrySaveChat(ctx, "chat-1", jobModel.JobPayload{Question: "unanswered"})
+
+			err, history := messageStore.GetMessageHistory(ctx, "chat-1")
+			if err != nil {
+				t.Fatalf("GetMessageHistory failed: %v", err)
+			}
+			var got []string
+			for _, message := range history {
+				got = append(got, string(message.Role)+":"+message.Text())
+			}
+			if fmt.Sprint(got) != "[user:q2 assistant:a2 user:q3 assistant:a3]" {
+				t.Errorf("Expected the last two turns oldest first, got %v", got)
+			}
+
+			if err, empty := messageStore.GetMessageHistory(ctx, "missing"); err != nil || len(empty) != 0 {
+				t.Errorf("Expected no history for an unknown chat, got %v %v", empty, err)
+			}
+		})
+	}
+}
+
+func TestHistoryWindow_TokenLimitDropsWholeTurns(t *testing.T) {
+	history := []llm.Message{
+		llm.TextMessage(llm.RoleUser, "an old question that is fairly long"),
+		llm.TextMessage(llm.RoleAssistant, "an old answer that is also fairly long"),
+		llm.TextMessage(llm.RoleUser, "new"),
+		llm.TextMessage(llm.RoleAssistant, "answer"),
+	}
+	kept := llm.HistoryWindow{MaxTokens: 5}.Apply(history)
+	if len(kept) != 2 || kept[0].Text() != "new" {
+		t.Errorf("Expected only the newest turn, got %+v", kept)
+	}
+	if all := (llm.HistoryWindow{}).Apply(history); len(all) != 4 {
+		t.Errorf("Expected no limit to keep everything, got %d messages", len(all))
+	}
+}
+
+func TestHistoryWindow_ToolResultsCountTowardsTheTokens(t *testing.T) {
+	toolTurn := []llm.Message{
+		llm.TextMessage(llm.RoleUser, "old"),
+		{Role: llm.RoleAssistant, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeToolUse, ToolName: "search_knowledge_base", ToolArgs: map[string]any{"Query": "pumps"}}}},
+		{Role: llm.RoleUser, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeToolResult, ToolResult: "a tool result that is a good deal longer than the answers"}}},
+		llm.TextMessage(llm.RoleAssistant, "old answer"),
+	}
+	newTurn := []llm.Message{llm.TextMessage(llm.RoleUser, "new"), llm.TextMessage(llm.RoleAssistant, "answer")}
+
+	kept := llm.HistoryWindow{MaxTokens: 10}.ApplyTurns([][]llm.Message{toolTurn, newTurn})
+	if len(kept) != 2 || kept[0].Text() != "new" {
+		t.Errorf("Expected only the newest turn, got %+v", kept)
+	}
+	if all := (llm.HistoryWindow{MaxTurns: 2}).ApplyTurns([][]llm.Message{toolTurn, newTurn}); len(all) != 6 {
+		t.Errorf("Expected both turns whole, got %d messages", len(all))
+	}
+}
+
+func TestMessageStores_SummaryGoesWithTheChat(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "summary-trace")
+
+	for name, messageStore := range messageStores(t) {
+		t.Run(name, func(t *testing.T) {
+			_ = messageStore.InitNewChat(ctx, "chat-1", "")
+			if _, found, err := messageStore.GetSummary(ctx, "chat-1"); found || err != nil {
+				t.Fatalf("Expected no summary yet, got found %v err %v", found, err)
+			}
+			if err := messageStore.SaveSummary(ctx, "chat-1", jobModel.ChatSummary{Text: "so far", Turns: 3}); err != nil {
+				t.Fatalf("SaveSummary failed: %v", err)
+			}
+			summary, found, _ := messageStore.GetSummary(ctx, "chat-1")
+			if !found || summary.Text != "so far" || summary.Turns != 3 {
+				t.Errorf("Unexpected summary %+v", summary)
+			}
+
+			_, _ = messageStore.DeleteChat(ctx, "chat-1")
+			if _, found, _ := messageStore.GetSummary(ctx, "chat-1"); found {
+				t.Error("Summary outlived its chat")
+			}
+		})
+	}
+}
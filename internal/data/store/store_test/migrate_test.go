@@ -0,0 +1,81 @@
+package store_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const legacyJob = `{"id":"job-1","chat_id":"chat-1","job_type":"Query","status":"COMPLETE","created_time":"2026-03-01T09:30:00Z",` +
+	`"job_payload":{"question":"q","answer":"a","sources":["doc_name:pump.pdf","page_num:12","chunk_order:0","chunk_id:c1","ingested_at:","source_doc_id:d1"]}}`
+
+const legacyEntry = `{"question":"q","answer":"a","sources":["doc_name:pump.pdf","page_num:12","chunk_order:0","chunk_id:c1","ingested_at:","source_doc_id:d1"]}`
+
+// TestRedisStores_MigrateRecords records saved before versioning are read with the upgrades applied,
+// and the migration rewrites them once
+func TestRedisStores_MigrateRecords(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	jobStore := store.TestJobStore(redisStore.NewTestStoreWithPrefix(client, config.RedisJobKeyPrefix))
+	messageStore := store.TestMessageStore(redisStore.NewTestStoreWithPrefix(client, config.RedisMessageKeyPrefix))
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "migrate-trace")
+
+	jobKey := config.RedisJobKeyPrefix + "job-1"
+	_ = mr.Set(jobKey, legacyJob)
+	mr.SetTTL(jobKey, time.Hour)
+	_, _ = mr.ZAdd(config.RedisJobKeyPrefix+config.RedisJobIndexPrefix+"all", 1, "job-1")
+	mr.HSet(config.RedisJobKeyPrefix+config.RedisScheduleKey, "schedule-1", `{"id":"schedule-1","job_payload":{"question":"q"}}`)
+	_, _ = mr.Push(config.RedisMessageKeyPrefix+"chat-1", `{}`, legacyEntry)
+
+	//upgraded on read
+	job, found := jobStore.GetJob(ctx, "job-1")
+	if !found || len(job.JobPayload.Sources) != 1 || job.JobPayload.Sources[0].Page != 12 {
+		t.Fatalf("Expected the legacy job with structured sources, got %+v", job)
+	}
+	transcript, _ := messageStore.GetTranscript(ctx, "chat-1")
+	if len(transcript) != 1 || transcript[0].Sources[0].DocumentName != "pump.pdf" {
+		t.Fatalf("Expected the legacy entry with structured sources, got %+v", transcript)
+	}
+
+	report, err := jobStore.MigrateRecords(ctx, true)
+	if err != nil || report.Scanned != 2 || report.Upgraded != 2 {
+		t.Errorf("Expected the job and the schedule to need upgrading, got %+v %v", report, err)
+	}
+	if value, _ := mr.Get(jobKey); value != legacyJob {
+		t.Error("Expected a dry run to leave the job alone")
+	}
+
+	report, err = jobStore.MigrateRecords(ctx, false)
+	if err != nil || report.Upgraded != 2 || report.Failed != 0 {
+		t.Errorf("Expected the job and the schedule to be upgraded, got %+v %v", report, err)
+	}
+	report, err = messageStore.MigrateRecords(ctx, false)
+	if err != nil || report.Scanned != 2 || report.Upgraded != 2 {
+		t.Errorf("Expected both chat entries to be upgraded, got %+v %v", report, err)
+	}
+
+	if value, _ := mr.Get(jobKey); !strings.Contains(value, `"schema_version":2`) || !strings.Contains(value, `"document_name":"pump.pdf"`) {
+		t.Errorf("Expected the job saved as version 2, got %s", value)
+	}
+	if ttl := mr.TTL(jobKey); ttl != time.Hour {
+		t.Errorf("Expected the job to keep its TTL, got %v", ttl)
+	}
+	if entries, _ := mr.List(config.RedisMessageKeyPrefix + "chat-1"); len(entries) != 2 || !strings.Contains(entries[1], `"schema_version":2`) {
+		t.Errorf("Expected the chat entries saved as version 2 in place, got %v", entries)
+	}
+	if job, found = jobStore.GetJob(ctx, "job-1"); !found || job.JobPayload.Sources[0].ChunkId != "c1" {
+		t.Errorf("Expected the migrated job to read the same, got %+v", job)
+	}
+
+	report, _ = jobStore.MigrateRecords(ctx, false)
+	if report.Upgraded != 0 {
+		t.Errorf("Expected nothing left to upgrade, got %+v", report)
+	}
+}
@@ -0,0 +1,99 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/redisStore"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisScheduleStore_Lifecycle(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	scheduleStore := store.TestJobStore(redisStore.NewTestStore(client))
+
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "schedule-trace")
+	schedule := jobModel.Schedule{
+		Id:             "nightly",
+		JobType:        jobModel.JobTypeQuery,
+		CronExpression: "0 2 * * *",
+		JobPayload:     jobModel.JobPayload{Question: "warm up"},
+	}
+
+	t.Run("Save and Get Roundtrip", func(t *testing.T) {
+		if err := scheduleStore.SaveSchedule(ctx, schedule); err != nil {
+			t.Fatalf("SaveSchedule failed: %v", err)
+		}
+		got, found := scheduleStore.GetSchedule(ctx, "nightly")
+		if !found {
+			t.Fatal("Schedule was saved but not found")
+		}
+		if got.CronExpression != schedule.CronExpression || got.JobPayload.Question != "warm up" {
+			t.Errorf("Data mismatch! Got %+v", got)
+		}
+	})
+
+	t.Run("Schedules have no TTL", func(t *testing.T) {
+		mr.FastForward(config.RedisJobStoreTTL * 2)
+		if _, found := scheduleStore.GetSchedule(ctx, "nightly"); !found {
+			t.Error("Schedule expired with the jobs")
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		_ = scheduleStore.SaveSchedule(ctx, jobModel.Schedule{Id: "once", JobType: jobModel.JobTypeMCP})
+		schedules, err := scheduleStore.ListSchedules(ctx)
+		if err != nil {
+			t.Fatalf("ListSchedules failed: %v", err)
+		}
+		if len(schedules) != 2 {
+			t.Errorf("Expected 2 schedules, got %d", len(schedules))
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := scheduleStore.DeleteSchedule(ctx, "nightly"); err != nil {
+			t.Fatalf("DeleteSchedule failed: %v", err)
+		}
+		if _, found := scheduleStore.GetSchedule(ctx, "nightly"); found {
+			t.Error("Schedule still exists after delete")
+		}
+	})
+}
+
+func TestRedisScheduleStore_LeaderLock(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	scheduleStore := store.TestJobStore(redisStore.NewTestStore(client))
+	ctx := context.Background()
+	ttl := 30 * time.Second
+
+	if !scheduleStore.AcquireLeaderLock(ctx, "scheduler", "replica-a", ttl) {
+		t.Fatal("First replica should get the free lock")
+	}
+	if scheduleStore.AcquireLeaderLock(ctx, "scheduler", "replica-b", ttl) {
+		t.Error("Second replica got a lock that is already held")
+	}
+
+	//the holder renews, so the lock outlives its original ttl
+	mr.FastForward(20 * time.Second)
+	if !scheduleStore.AcquireLeaderLock(ctx, "scheduler", "replica-a", ttl) {
+		t.Error("Holder could not renew its own lock")
+	}
+	mr.FastForward(20 * time.Second)
+	if scheduleStore.AcquireLeaderLock(ctx, "scheduler", "replica-b", ttl) {
+		t.Error("Renewed lock was taken over before it expired")
+	}
+
+	//holder goes away, lock expires and fails over
+	mr.FastForward(ttl)
+	if !scheduleStore.AcquireLeaderLock(ctx, "scheduler", "replica-b", ttl) {
+		t.Error("Lock did not fail over after expiring")
+	}
+}
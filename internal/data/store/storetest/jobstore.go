@@ -0,0 +1,159 @@
+package storetest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+// RunJobStoreSuite runs every JobStore check against a fresh store from newStore
+func RunJobStoreSuite(t *testing.T, newStore func(t *testing.T) JobStoreHarness) {
+	t.Run("unknown ids", func(t *testing.T) { jobStoreUnknownIds(t, newStore(t)) })
+	t.Run("round trip", func(t *testing.T) { jobStoreRoundTrip(t, newStore(t)) })
+	t.Run("ordering", func(t *testing.T) { jobStoreOrdering(t, newStore(t)) })
+	t.Run("delete", func(t *testing.T) { jobStoreDelete(t, newStore(t)) })
+	t.Run("ttl", func(t *testing.T) { jobStoreTTL(t, newStore(t)) })
+	t.Run("concurrency", func(t *testing.T) { jobStoreConcurrency(t, newStore(t)) })
+}
+
+func suiteJob(id string, created time.Time) jobModel.Job {
+	return jobModel.Job{
+		Id:          id,
+		ChatId:      "chat-" + id,
+		JobType:     jobModel.JobTypeQuery,
+		Status:      jobModel.JobStatusQueued,
+		CreatedTime: created,
+		JobPayload:  jobModel.JobPayload{Question: "question " + id},
+	}
+}
+
+func jobStoreUnknownIds(t *testing.T, h JobStoreHarness) {
+	ctx := suiteContext()
+	if _, found := h.Store.GetJob(ctx, "missing"); found {
+		t.Error("Expected an unknown job not to be found")
+	}
+	h.Store.DeleteJob(ctx, "missing") //must not fail
+	page, err := h.Store.ListJobs(ctx, jobModel.JobQuery{ChatId: "missing"})
+	if err != nil || len(page.Jobs) != 0 || page.NextCursor != "" {
+		t.Errorf("Expected an empty last page, got %+v %v", page, err)
+	}
+	if _, err = h.Store.ListJobs(ctx, jobModel.JobQuery{Cursor: "not a cursor"}); err != jobModel.ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func jobStoreRoundTrip(t *testing.T, h JobStoreHarness) {
+	ctx := suiteContext()
+	job := suiteJob("job-1", time.Now())
+	if err := h.Store.SaveJob(ctx, job); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+	job.Status = jobModel.JobStatusComplete
+	job.JobPayload.Answer = "answer"
+	if err := h.Store.SaveJob(ctx, job); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+
+	got, found := h.Store.GetJob(ctx, "job-1")
+	if !found {
+		t.Fatal("Expected the saved job to be found")
+	}
+	if got.Status != jobModel.JobStatusComplete || got.JobPayload.Answer != "answer" || got.ChatId != "chat-job-1" || !got.CreatedTime.Equal(job.CreatedTime) {
+		t.Errorf("Expected the last save to win, got %+v", got)
+	}
+	page, _ := h.Store.ListJobs(ctx, jobModel.JobQuery{Status: jobModel.JobStatusQueued})
+	if len(page.Jobs) != 0 {
+		t.Errorf("Expected the job to have left its old status, got %+v", page.Jobs)
+	}
+}
+
+func jobStoreOrdering(t *testing.T, h JobStoreHarness) {
+	ctx := suiteContext()
+	start := time.Now()
+	for i := 1; i <= 5; i++ {
+		_ = h.Store.SaveJob(ctx, suiteJob(fmt.Sprintf("job-%d", i), start.Add(time.Duration(i)*time.Second)))
+	}
+
+	var got []string
+	query := jobModel.JobQuery{Limit: 2}
+	for pages := 0; pages < 5; pages++ {
+		page, err := h.Store.ListJobs(ctx, query)
+		if err != nil {
+			t.Fatalf("ListJobs failed: %v", err)
+		}
+		if len(page.Jobs) > 2 {
+			t.Fatalf("Expected at most 2 jobs a page, got %d", len(page.Jobs))
+		}
+		for _, job := range page.Jobs {
+			got = append(got, job.Id)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	if fmt.Sprint(got) != "[job-5 job-4 job-3 job-2 job-1]" {
+		t.Errorf("Expected every job newest first, got %v", got)
+	}
+}
+
+func jobStoreDelete(t *testing.T, h JobStoreHarness) {
+	ctx := suiteContext()
+	_ = h.Store.SaveJob(ctx, suiteJob("job-1", time.Now()))
+	h.Store.DeleteJob(ctx, "job-1")
+	if _, found := h.Store.GetJob(ctx, "job-1"); found {
+		t.Error("Expected the deleted job to be gone")
+	}
+	if page, _ := h.Store.ListJobs(ctx, jobModel.JobQuery{}); len(page.Jobs) != 0 {
+		t.Errorf("Expected the deleted job not to be listed, got %+v", page.Jobs)
+	}
+}
+
+func jobStoreTTL(t *testing.T, h JobStoreHarness) {
+	ctx := suiteContext()
+	_ = h.Store.SaveJob(ctx, suiteJob("expiring", time.Now()))
+	_ = h.Store.SaveJob(ctx, suiteJob("refreshed", time.Now()))
+
+	h.Advance(config.RedisJobStoreTTL - time.Minute)
+	_ = h.Store.SaveJob(ctx, suiteJob("refreshed", time.Now())) //every save starts the TTL again
+	h.Advance(2 * time.Minute)
+
+	if _, found := h.Store.GetJob(ctx, "expiring"); found {
+		t.Error("Expected the job to expire with the job store TTL")
+	}
+	if _, found := h.Store.GetJob(ctx, "refreshed"); !found {
+		t.Error("Expected a job saved again to live another TTL")
+	}
+	page, err := h.Store.ListJobs(ctx, jobModel.JobQuery{})
+	if err != nil || len(page.Jobs) != 1 || page.Jobs[0].Id != "refreshed" {
+		t.Errorf("Expected only the live job to be listed, got %+v %v", page.Jobs, err)
+	}
+}
+
+func jobStoreConcurrency(t *testing.T, h JobStoreHarness) {
+	ctx := suiteContext()
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("job-%02d", i)
+			if err := h.Store.SaveJob(ctx, suiteJob(id, start.Add(time.Duration(i)*time.Millisecond))); err != nil {
+				t.Errorf("SaveJob %s failed: %v", id, err)
+			}
+			h.Store.GetJob(ctx, id)
+			_, _ = h.Store.ListJobs(ctx, jobModel.JobQuery{Limit: 5})
+		}(i)
+	}
+	wg.Wait()
+
+	page, err := h.Store.ListJobs(ctx, jobModel.JobQuery{Limit: config.JobListMaxLimit})
+	if err != nil || len(page.Jobs) != 50 {
+		t.Errorf("Expected all 50 jobs, got %d %v", len(page.Jobs), err)
+	}
+}
@@ -0,0 +1,229 @@
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
+)
+
+// RunMessageStoreSuite runs every MessageStore check against a fresh store from newStore
+func RunMessageStoreSuite(t *testing.T, newStore func(t *testing.T) MessageStoreHarness) {
+	t.Run("unknown ids", func(t *testing.T) { messageStoreUnknownIds(t, newStore(t)) })
+	t.Run("ordering", func(t *testing.T) { messageStoreOrdering(t, newStore(t)) })
+	t.Run("windowing", func(t *testing.T) { messageStoreWindowing(t, newStore(t)) })
+	t.Run("tool history", func(t *testing.T) { messageStoreToolHistory(t, newStore(t)) })
+	t.Run("delete", func(t *testing.T) { messageStoreDelete(t, newStore(t)) })
+	t.Run("ttl", func(t *testing.T) { messageStoreTTL(t, newStore(t)) })
+	t.Run("concurrency", func(t *testing.T) { messageStoreConcurrency(t, newStore(t)) })
+}
+
+func suiteTurn(i int) jobModel.JobPayload {
+	return jobModel.JobPayload{Question: fmt.Sprintf("q%d", i), Answer: fmt.Sprintf("a%d", i)}
+}
+
+func messageStoreUnknownIds(t *testing.T, h MessageStoreHarness) {
+	ctx := suiteContext()
+	if h.Store.ValidateChatId(ctx, "missing") {
+		t.Error("Expected an unknown chat not to validate")
+	}
+	if err := h.Store.TrySaveChat(ctx, "missing", suiteTurn(1)); !errors.Is(err, jobModel.ErrChatNotFound) {
+		t.Errorf("Expected ErrChatNotFound saving to an unknown chat, got %v", err)
+	}
+	if h.Store.ValidateChatId(ctx, "missing") {
+		t.Error("Expected a failed save not to create the chat")
+	}
+	if err, history := h.Store.GetMessageHistory(ctx, "missing"); err != nil || len(history) != 0 {
+		t.Errorf("Expected no history, got %v %v", history, err)
+	}
+	if turns, err := h.Store.GetTurns(ctx, "missing"); err != nil || len(turns) != 0 {
+		t.Errorf("Expected no turns, got %v %v", turns, err)
+	}
+	if transcript, err := h.Store.GetTranscript(ctx, "missing"); err != nil || len(transcript) != 0 {
+		t.Errorf("Expected no transcript, got %v %v", transcript, err)
+	}
+	if _, found, err := h.Store.GetSummary(ctx, "missing"); err != nil || found {
+		t.Errorf("Expected no summary, got %v %v", found, err)
+	}
+	if _, found, err := h.Store.GetChat(ctx, "missing"); err != nil || found {
+		t.Errorf("Expected no chat, got %v %v", found, err)
+	}
+	if err := h.Store.RenameChat(ctx, "missing", "title"); !errors.Is(err, jobModel.ErrChatNotFound) {
+		t.Errorf("Expected ErrChatNotFound renaming an unknown chat, got %v", err)
+	}
+	if deleted, err := h.Store.DeleteChat(ctx, "missing"); err != nil || deleted {
+		t.Errorf("Expected nothing to delete, got %v %v", deleted, err)
+	}
+}
+
+func messageStoreOrdering(t *testing.T, h MessageStoreHarness) {
+	ctx := suiteContext()
+	if err := h.Store.InitNewChat(ctx, "chat-1", "alice"); err != nil {
+		t.Fatalf("InitNewChat failed: %v", err)
+	}
+	if !h.Store.ValidateChatId(ctx, "chat-1") {
+		t.Fatal("Expected a new chat to validate before its first turn")
+	}
+	for i := 1; i <= 3; i++ {
+		if err := h.Store.TrySaveChat(ctx, "chat-1", suiteTurn(i)); err != nil {
+			t.Fatalf("TrySaveChat failed: %v", err)
+		}
+	}
+
+	turns, err := h.Store.GetTurns(ctx, "chat-1")
+	if err != nil {
+		t.Fatalf("GetTurns failed: %v", err)
+	}
+	var got []string
+	for _, turn := range turns {
+		got = append(got, turn.Text())
+	}
+	if fmt.Sprint(got) != "[q1 a1 q2 a2 q3 a3]" {
+		t.Errorf("Expected every turn oldest first, got %v", got)
+	}
+	transcript, _ := h.Store.GetTranscript(ctx, "chat-1")
+	if len(transcript) != 3 || transcript[0].Question != "q1" || transcript[2].Answer != "a3" {
+		t.Errorf("Expected the transcript oldest first, got %+v", transcript)
+	}
+}
+
+func messageStoreWindowing(t *testing.T, h MessageStoreHarness) {
+	ctx := suiteContext()
+	t.Setenv("CHAT_HISTORY_MAX_TURNS", "2")
+	t.Setenv("CHAT_HISTORY_MAX_TOKENS", "0")
+	_ = h.Store.InitNewChat(ctx, "chat-1", "")
+	for i := 1; i <= 4; i++ {
+		_ = h.Store.TrySaveChat(ctx, "chat-1", suiteTurn(i))
+	}
+
+	err, history := h.Store.GetMessageHistory(ctx, "chat-1")
+	if err != nil {
+		t.Fatalf("GetMessageHistory failed: %v", err)
+	}
+	var got []string
+	for _, message := range history {
+		got = append(got, string(message.Role)+":"+message.Text())
+	}
+	if fmt.Sprint(got) != "[user:q3 assistant:a3 user:q4 assistant:a4]" {
+		t.Errorf("Expected the newest two turns, got %v", got)
+	}
+}
+
+func messageStoreToolHistory(t *testing.T, h MessageStoreHarness) {
+	ctx := suiteContext()
+	t.Setenv("MCP_HISTORY_MAX_TURNS", "2")
+	t.Setenv("MCP_HISTORY_MAX_TOKENS", "0")
+	_ = h.Store.InitNewChat(ctx, "chat-1", "")
+	_ = h.Store.TrySaveChat(ctx, "chat-1", suiteTurn(1))
+	_ = h.Store.TrySaveChat(ctx, "chat-1", suiteTurn(2))
+	toolTurn := suiteTurn(3)
+	toolTurn.ToolMessages = []llm.Message{
+		{Role: llm.RoleAssistant, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeToolUse, ToolCallID: "call-1", ToolName: "search_knowledge_base", ToolArgs: map[string]any{"Query": "pumps"}, RawField: "dropped"}}},
+		{Role: llm.RoleUser, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeToolResult, ToolCallID: "call-1", ToolName: "search_knowledge_base", ToolResult: "r1"}}},
+	}
+	_ = h.Store.TrySaveChat(ctx, "chat-1", toolTurn)
+
+	history, err := h.Store.GetToolHistory(ctx, "chat-1")
+	if err != nil {
+		t.Fatalf("GetToolHistory failed: %v", err)
+	}
+	var got []string
+	for _, message := range history {
+		for _, block := range message.Content {
+			got = append(got, string(message.Role)+":"+block.Text+block.ToolName+block.ToolResult)
+		}
+	}
+	if fmt.Sprint(got) != "[user:q2 assistant:a2 user:q3 assistant:search_knowledge_base user:search_knowledge_baser1 assistant:a3]" {
+		t.Errorf("Expected the newest two turns with the tool calls in between, got %v", got)
+	}
+	call := history[3].Content[0]
+	if call.ToolCallID != "call-1" || call.ToolArgs["Query"] != "pumps" || call.RawField != nil {
+		t.Errorf("Expected the tool call without its raw provider field, got %+v", call)
+	}
+
+	//plain history only ever has the question and the answer
+	turns, _ := h.Store.GetTurns(ctx, "chat-1")
+	if len(turns) != 6 || turns[4].Text() != "q3" || turns[5].Text() != "a3" {
+		t.Errorf("Expected the tool calls left out of the plain turns, got %+v", turns)
+	}
+}
+
+func messageStoreDelete(t *testing.T, h MessageStoreHarness) {
+	ctx := suiteContext()
+	_ = h.Store.InitNewChat(ctx, "chat-1", "alice")
+	_ = h.Store.TrySaveChat(ctx, "chat-1", suiteTurn(1))
+	_ = h.Store.SaveSummary(ctx, "chat-1", jobModel.ChatSummary{Text: "summary", Turns: 1})
+
+	if deleted, err := h.Store.DeleteChat(ctx, "chat-1"); err != nil || !deleted {
+		t.Fatalf("DeleteChat failed: %v %v", deleted, err)
+	}
+	if h.Store.ValidateChatId(ctx, "chat-1") {
+		t.Error("Expected the deleted chat not to validate")
+	}
+	if _, found, _ := h.Store.GetSummary(ctx, "chat-1"); found {
+		t.Error("Expected the summary to go with the chat")
+	}
+	if page, _ := h.Store.ListChats(ctx, jobModel.ChatQuery{Identity: "alice"}); len(page.Chats) != 0 {
+		t.Errorf("Expected the deleted chat not to be listed, got %+v", page.Chats)
+	}
+	if err := h.Store.TrySaveChat(ctx, "chat-1", suiteTurn(2)); !errors.Is(err, jobModel.ErrChatNotFound) {
+		t.Errorf("Expected ErrChatNotFound saving to a deleted chat, got %v", err)
+	}
+	if err := h.Store.SaveSummary(ctx, "chat-1", jobModel.ChatSummary{Text: "late", Turns: 2}); !errors.Is(err, jobModel.ErrChatNotFound) {
+		t.Errorf("Expected ErrChatNotFound summarising a deleted chat, got %v", err)
+	}
+	if _, found, _ := h.Store.GetSummary(ctx, "chat-1"); found {
+		t.Error("Expected no summary saved for a deleted chat")
+	}
+}
+
+// messageStoreTTL summaries expire with the message store TTL, the chats themselves don't
+func messageStoreTTL(t *testing.T, h MessageStoreHarness) {
+	ctx := suiteContext()
+	_ = h.Store.InitNewChat(ctx, "chat-1", "alice")
+	_ = h.Store.TrySaveChat(ctx, "chat-1", suiteTurn(1))
+	_ = h.Store.SaveSummary(ctx, "chat-1", jobModel.ChatSummary{Text: "summary", Turns: 1})
+
+	h.Advance(config.RedisMessageStoreTTL - time.Minute)
+	if _, found, _ := h.Store.GetSummary(ctx, "chat-1"); !found {
+		t.Error("Expected the summary to live until the TTL")
+	}
+	h.Advance(2 * time.Minute)
+	if _, found, _ := h.Store.GetSummary(ctx, "chat-1"); found {
+		t.Error("Expected the summary to expire with the message store TTL")
+	}
+	if !h.Store.ValidateChatId(ctx, "chat-1") {
+		t.Error("Expected the chat to outlive the TTL")
+	}
+	if transcript, _ := h.Store.GetTranscript(ctx, "chat-1"); len(transcript) != 1 {
+		t.Errorf("Expected the turn to outlive the TTL, got %+v", transcript)
+	}
+}
+
+func messageStoreConcurrency(t *testing.T, h MessageStoreHarness) {
+	ctx := suiteContext()
+	_ = h.Store.InitNewChat(ctx, "chat-1", "alice")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := h.Store.TrySaveChat(ctx, "chat-1", suiteTurn(i)); err != nil {
+				t.Errorf("TrySaveChat failed: %v", err)
+			}
+			_, _ = h.Store.GetTurns(ctx, "chat-1")
+		}(i)
+	}
+	wg.Wait()
+
+	transcript, err := h.Store.GetTranscript(ctx, "chat-1")
+	if err != nil || len(transcript) != 20 {
+		t.Errorf("Expected all 20 turns, got %d %v", len(transcript), err)
+	}
+}
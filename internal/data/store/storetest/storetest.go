@@ -0,0 +1,50 @@
+// Package storetest is the behaviour every JobStore and MessageStore implementation shares.
+// An implementation's tests call RunJobStoreSuite and RunMessageStoreSuite with a factory,
+// so the redis and in-memory stores can't drift apart again.
+package storetest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+// JobStoreHarness a fresh, empty store and a way to move its clock, Advance is what makes TTLs testable
+type JobStoreHarness struct {
+	Store   jobModel.JobStore
+	Advance func(d time.Duration)
+}
+
+type MessageStoreHarness struct {
+	Store   jobModel.MessageStore
+	Advance func(d time.Duration)
+}
+
+// Clock a settable clock for stores that take a now func
+type Clock struct {
+	lock    sync.Mutex
+	current time.Time
+}
+
+func NewClock() *Clock {
+	return &Clock{current: time.Now()}
+}
+
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.current
+}
+
+func (c *Clock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.current = c.current.Add(d)
+}
+
+func suiteContext() context.Context {
+	return context.WithValue(context.Background(), config.TRACE_ID_KEY, "conformance-trace")
+}
@@ -17,6 +17,14 @@ type DocChunk struct {
 	ChunkPageOrder     int    `json:"chunk_order"`
 	EmbeddingDimension string `json:"embeddingModel"`
 }
+
+// CacheOwner where a cached answer came from, so it can be erased with the chat or identity
+type CacheOwner struct {
+	JobId    string
+	ChatId   string
+	Identity string
+}
+
 type DocType string
 
 var PDF DocType = "PDF"
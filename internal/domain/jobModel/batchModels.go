@@ -0,0 +1,50 @@
+package jobModel
+
+// BatchProgress how many children of a batch are at each status. Every child is saved before any of them
+// is queued, so a child missing from the store has expired or been erased
+type BatchProgress struct {
+	Total    int
+	Queued   int
+	Running  int
+	Complete int
+	Failed   int
+	Missing  int
+}
+
+// NewBatchProgress counts the children of the batch parent, children holds the ones still in the store
+func NewBatchProgress(parent Job, children map[string]Job) BatchProgress {
+	progress := BatchProgress{Total: len(parent.JobPayload.ChildJobIds)}
+	for _, childId := range parent.JobPayload.ChildJobIds {
+		child, found := children[childId]
+		switch {
+		case !found:
+			progress.Missing++
+		case child.Status == JobStatusQueued:
+			progress.Queued++
+		case child.Status == JobStatusRunning:
+			progress.Running++
+		case child.Status == JobStatusComplete:
+			progress.Complete++
+		case child.Status == JobStatusError:
+			progress.Failed++
+		}
+	}
+	return progress
+}
+
+// Finished no child is still waiting or running
+func (p BatchProgress) Finished() bool {
+	return p.Complete+p.Failed+p.Missing == p.Total
+}
+
+// Status of the batch as a whole, it is running from the moment a worker picks up its first child
+func (p BatchProgress) Status() JobStatus {
+	switch {
+	case p.Finished():
+		return JobStatusComplete
+	case p.Queued == p.Total:
+		return JobStatusQueued
+	default:
+		return JobStatusRunning
+	}
+}
@@ -0,0 +1,55 @@
+package jobModel
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrChatNotFound = errors.New("chat not found")
+
+// ChatSummary the running summary of the turns that have dropped out of the history window.
+// Turns is how many of the chat's turns, counted from the first, it covers
+type ChatSummary struct {
+	Text        string    `json:"text"`
+	Turns       int       `json:"turns"`
+	UpdatedTime time.Time `json:"updated_time"`
+}
+
+// ChatInfo what is kept about a chat next to its turns. Identity is the end user the chat was started for,
+// see config.IdentityHeader, chats started before this was kept have no Identity and no times
+type ChatInfo struct {
+	Id          string    `json:"id"`
+	Identity    string    `json:"identity,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	CreatedTime time.Time `json:"created_time"`
+	UpdatedTime time.Time `json:"updated_time"`
+}
+
+// OwnedBy chats started before the owner was kept stay open to every identity
+func (c ChatInfo) OwnedBy(identity string) bool {
+	return c.Identity == "" || c.Identity == identity
+}
+
+// ChatEntry one saved turn of a chat, Time is zero for turns saved before it was kept
+type ChatEntry struct {
+	JobPayload
+	Time time.Time `json:"time"`
+}
+
+// ChatQuery lists the chats of one identity newest first, Cursor continues from the last chat of the previous page
+type ChatQuery struct {
+	Identity string
+	Limit    int
+	Cursor   string
+}
+
+type ChatPage struct {
+	Chats []ChatInfo
+	//NextCursor is empty on the last page
+	NextCursor string
+}
+
+// ChatCursor position of a chat in the listing order, the same encoding as a JobCursor
+func ChatCursor(chat ChatInfo) JobCursor {
+	return JobCursor{CreatedMs: chat.CreatedTime.UnixMilli(), Id: chat.Id}
+}
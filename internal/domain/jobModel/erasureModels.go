@@ -0,0 +1,9 @@
+package jobModel
+
+// ErasureReport what an erasure job removed. Anything that was already gone is not listed,
+// so running the same erasure again reports nothing
+type ErasureReport struct {
+	ChatIds      []string `json:"chat_ids"`
+	JobIds       []string `json:"job_ids"`
+	CacheEntries int      `json:"cache_entries"`
+}
@@ -0,0 +1,37 @@
+package jobModel
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotRateable only the answer of a finished Query or MCP job can be rated
+var ErrNotRateable = errors.New("only a completed query or MCP job with an answer can be rated")
+
+type Rating string
+
+const (
+	RatingPositive Rating = "positive"
+	//RatingNegative also evicts the answer from the semantic cache
+	RatingNegative Rating = "negative"
+)
+
+// Feedback a user's rating of a job's answer, kept with the job. Rating the job again replaces it
+type Feedback struct {
+	Rating  Rating    `json:"rating"`
+	Comment string    `json:"comment,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Rateable the job finished with an answer to rate
+func (j Job) Rateable() bool {
+	return j.Status == JobStatusComplete && j.JobPayload.Answer != "" &&
+		(j.JobType == JobTypeQuery || j.JobType == JobTypeMCP)
+}
+
+// AnswerCache the semantic cache answers are served from. EvictAnswer removes the answer the job
+// was given, whether the job created the cache entry or was served from it, and reports how many entries went
+type AnswerCache interface {
+	EvictAnswer(ctx context.Context, jobId string, answer string) (int, error)
+}
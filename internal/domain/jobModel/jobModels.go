@@ -3,6 +3,8 @@ package jobModel
 import (
 	"context"
 	"time"
+
+	"github.com/akolanti/GoAPI/internal/llm"
 )
 
 type JobStatus string
@@ -10,6 +12,8 @@ type InternalStatus string
 
 type JobType string
 
+type ErrorType string
+
 const (
 	JobStatusQueued   JobStatus = "QUEUED"
 	JobStatusRunning  JobStatus = "RUNNING"
@@ -17,28 +21,44 @@ const (
 	JobStatusError    JobStatus = "Error"
 
 	UserQueryInit    InternalStatus = "Init"
+	QueryRewriteCall InternalStatus = "QueryRewrite"
 	CacheCall        InternalStatus = "CacheCall"
 	RAGCall          InternalStatus = "RAG"
 	LLMCall          InternalStatus = "LLM"
 	VectorDBCall     InternalStatus = "VectorDB"
+	RerankCall       InternalStatus = "Rerank"
 	EmbeddingAPICall InternalStatus = "EmbeddingAPI"
 	RedisCall        InternalStatus = "Redis"
 
 	IngestInit       InternalStatus = "IngestInit"
 	IngestProcessing InternalStatus = "IngestProcessing"
+	IngestExtraction InternalStatus = "IngestExtraction"
+	IngestUpsert     InternalStatus = "IngestUpsert"
 	Error            InternalStatus = "Error"
 
+	ErasureInit InternalStatus = "ErasureInit"
+	EraseJobs   InternalStatus = "EraseJobs"
+	EraseChats  InternalStatus = "EraseChats"
+	EraseCache  InternalStatus = "EraseCache"
+
 	Complete InternalStatus = "Complete"
 
-	JobTypeQuery  JobType = "Query"
-	JobTypeIngest JobType = "Ingest"
-	JobTypeMCP    JobType = "MCP"
+	JobTypeQuery   JobType = "Query"
+	JobTypeIngest  JobType = "Ingest"
+	JobTypeMCP     JobType = "MCP"
+	JobTypeBatch   JobType = "Batch"
+	JobTypeErasure JobType = "Erasure"
+
+	//ErrorTypeTimeout the job ran out of time, the same request may well succeed if it is sent again
+	ErrorTypeTimeout ErrorType = "TIMEOUT"
 )
 
 type Job struct {
 	Id          string         `json:"id"`
+	ParentId    string         `json:"parent_id,omitempty"`
 	ChatId      string         `json:"chat_id"`
 	TraceId     string         `json:"trace_id"`
+	Identity    string         `json:"identity,omitempty"` //end user the job was created for, see config.IdentityHeader
 	JobType     JobType        `json:"job_type"`
 	JobPayload  JobPayload     `json:"job_payload"`
 	Error       JobError       `json:"error,omitempty"`
@@ -46,33 +66,96 @@ type Job struct {
 	EndTime     time.Time      `json:"end_time,omitempty"`
 	Status      JobStatus      `json:"status"`
 	CurrentStep InternalStatus `json:"current_step"`
+	Timeline    []StepEvent    `json:"timeline,omitempty"`
+	Progress    int            `json:"progress,omitempty"` //percent, only tracked for ingestion
+	CallbackURL string         `json:"callback_url,omitempty"`
+	Deadline    time.Time      `json:"deadline,omitempty"` //client supplied, the job fails with ErrorTypeTimeout once it passes
+	Deliveries  []Delivery     `json:"deliveries,omitempty"`
+	Feedback    *Feedback      `json:"feedback,omitempty"`
+}
+
+// IsFinished the job won't change status again
+func (j Job) IsFinished() bool {
+	return j.Status == JobStatusComplete || j.Status == JobStatusError
+}
+
+// OwnedBy jobs created without an identity belong to everyone
+func (j Job) OwnedBy(identity string) bool {
+	return j.Identity == "" || j.Identity == identity
+}
+
+// Delivery one attempt at posting the finished job to its callback url
+type Delivery struct {
+	Attempt    int       `json:"attempt"`
+	Time       time.Time `json:"time"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
 }
 
 type JobError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Retry   bool   `json:"retry"`
+	Code    int       `json:"code"`
+	Type    ErrorType `json:"type,omitempty"`
+	Message string    `json:"message"`
+	Retry   bool      `json:"retry"`
 }
 
 type JobPayload struct {
 	Question string   `json:"question,omitempty"`
 	Answer   string   `json:"answer,omitempty"`
-	Sources  []string `json:"sources,omitempty"`
+	Sources  []Source `json:"sources,omitempty"`
+
+	//the standalone question retrieval used, and the sub-queries it was split into, when rewriting is on
+	RewrittenQueries []string `json:"rewritten_queries,omitempty"`
+	//nil retrieves the default way
+	Retrieval *RetrievalOptions `json:"retrieval,omitempty"`
+
+	//MCP - the tool calls and results between the question and the answer, only kept with the chat turn
+	ToolMessages []llm.Message `json:"tool_messages,omitempty"`
 
 	IngestFileName string `json:"ingest_file_name,omitempty"`
 	IngestURL      string `json:"ingest_url,omitempty"`
+
+	//batch parent - the children are ordinary jobs
+	ChildJobIds []string `json:"child_job_ids,omitempty"`
+
+	//erasure - either a single chat or everything of an identity
+	EraseChatId   string         `json:"erase_chat_id,omitempty"`
+	EraseIdentity string         `json:"erase_identity,omitempty"`
+	Erasure       *ErasureReport `json:"erasure,omitempty"`
 }
 
 type JobStore interface {
 	GetJob(ctx context.Context, jobId string) (Job, bool)
 	SaveJob(ctx context.Context, job Job) error
 	DeleteJob(ctx context.Context, jobID string)
+	ListJobs(ctx context.Context, query JobQuery) (JobPage, error)
 }
 
 type MessageStore interface {
 	ValidateChatId(ctx context.Context, id string) bool
 	TrySaveChat(ctx context.Context, id string, JobPayload JobPayload) error
-	InitNewChat(ctx context.Context, id string) error
-	GetMessageHistory(ctx context.Context, chatId string) (error, []string)
+	// InitNewChat starts an empty chat owned by identity
+	InitNewChat(ctx context.Context, id string, identity string) error
+	// GetChat found is false when there is no chat with that id
+	GetChat(ctx context.Context, chatId string) (chat ChatInfo, found bool, err error)
+	// ListChats only lists chats started for an identity
+	ListChats(ctx context.Context, query ChatQuery) (ChatPage, error)
+	// GetTranscript every question of the chat with its answer, oldest first
+	GetTranscript(ctx context.Context, chatId string) ([]ChatEntry, error)
+	RenameChat(ctx context.Context, chatId string, title string) error
+	// GetMessageHistory the newest turns of the chat that fit the history window, oldest first
+	GetMessageHistory(ctx context.Context, chatId string) (error, []llm.Message)
+	// GetTurns every answered turn of the chat, oldest first, without the history window
+	GetTurns(ctx context.Context, chatId string) ([]llm.Message, error)
+	// GetToolHistory the newest turns that fit the MCP history window, oldest first, with the tool calls
+	// and results of MCP turns between their question and answer
+	GetToolHistory(ctx context.Context, chatId string) ([]llm.Message, error)
+	// GetSummary found is false until the chat has been summarised once
+	GetSummary(ctx context.Context, chatId string) (summary ChatSummary, found bool, err error)
+	// SaveSummary fails with ErrChatNotFound once the chat is gone, a summary never outlives its chat
+	SaveSummary(ctx context.Context, chatId string, summary ChatSummary) error
+	// DeleteChat reports whether there was a chat to delete, the summary and the chat info go with it
+	DeleteChat(ctx context.Context, chatId string) (bool, error)
 }
 
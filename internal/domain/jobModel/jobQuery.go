@@ -0,0 +1,91 @@
+package jobModel
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// JobQuery filters for ListJobs, zero values match everything.
+// Results are ordered newest first, Cursor continues from the last job of the previous page.
+type JobQuery struct {
+	ChatId      string
+	JobType     JobType
+	Status      JobStatus
+	Identity    string
+	CreatedFrom time.Time //inclusive
+	CreatedTo   time.Time //exclusive
+	Limit       int
+	Cursor      string
+}
+
+type JobPage struct {
+	Jobs []Job
+	//NextCursor is empty on the last page
+	NextCursor string
+}
+
+// Matches reports whether the job passes every filter, the cursor is not considered
+func (q JobQuery) Matches(job Job) bool {
+	if q.ChatId != "" && job.ChatId != q.ChatId {
+		return false
+	}
+	if q.JobType != "" && job.JobType != q.JobType {
+		return false
+	}
+	if q.Status != "" && job.Status != q.Status {
+		return false
+	}
+	if q.Identity != "" && job.Identity != q.Identity {
+		return false
+	}
+	if !q.CreatedFrom.IsZero() && job.CreatedTime.Before(q.CreatedFrom) {
+		return false
+	}
+	if !q.CreatedTo.IsZero() && !job.CreatedTime.Before(q.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// JobCursor position of a job in the listing order, created time in ms then id, both descending
+type JobCursor struct {
+	CreatedMs int64
+	Id        string
+}
+
+func CursorOf(job Job) JobCursor {
+	return JobCursor{CreatedMs: job.CreatedTime.UnixMilli(), Id: job.Id}
+}
+
+// After reports whether c comes after other in the listing order
+func (c JobCursor) After(other JobCursor) bool {
+	if c.CreatedMs != other.CreatedMs {
+		return c.CreatedMs < other.CreatedMs
+	}
+	return c.Id < other.Id
+}
+
+func (c JobCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.CreatedMs, 10) + ":" + c.Id))
+}
+
+func DecodeCursor(cursor string) (JobCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return JobCursor{}, ErrInvalidCursor
+	}
+	ms, id, found := strings.Cut(string(raw), ":")
+	if !found || id == "" {
+		return JobCursor{}, ErrInvalidCursor
+	}
+	createdMs, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return JobCursor{}, ErrInvalidCursor
+	}
+	return JobCursor{CreatedMs: createdMs, Id: id}, nil
+}
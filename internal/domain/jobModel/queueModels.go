@@ -0,0 +1,23 @@
+package jobModel
+
+import (
+	"context"
+	"time"
+)
+
+// JobQueue carries jobs from API processes to worker processes when they run apart.
+// In a single process jobs go straight through the job channel instead.
+type JobQueue interface {
+	Enqueue(ctx context.Context, job Job) error
+	// Requeue puts a job that was taken but never started back at the front
+	Requeue(ctx context.Context, job Job) error
+	// Dequeue waits up to timeout for a job, found is false when none arrived. The job is held for this
+	// process until it is acked or requeued
+	Dequeue(ctx context.Context, timeout time.Duration) (job Job, found bool, err error)
+	// Ack releases a job this process took once it is done with it
+	Ack(ctx context.Context, job Job) error
+	// Heartbeat tells the other processes this one is alive and still working on the jobs it holds
+	Heartbeat(ctx context.Context) error
+	// Reap takes over the jobs held by processes whose heartbeat stopped, they are held for this one
+	Reap(ctx context.Context) ([]Job, error)
+}
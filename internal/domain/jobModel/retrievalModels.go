@@ -0,0 +1,15 @@
+package jobModel
+
+// RetrievalOptions per question overrides of how context is retrieved, checked against the server bounds
+// when the request comes in. The zero value is the default retrieval
+type RetrievalOptions struct {
+	TopK       int     `json:"top_k,omitempty"`     //chunks handed to the LLM
+	MinScore   float32 `json:"min_score,omitempty"` //lowest embedding similarity a chunk may have
+	SkipCache  bool    `json:"skip_cache,omitempty"`
+	Collection string  `json:"collection,omitempty"` //vector DB collection searched instead of the ingest one
+}
+
+// UsesCache cached answers were retrieved the default way, so only questions asked that way share them
+func (o *RetrievalOptions) UsesCache() bool {
+	return o == nil || *o == RetrievalOptions{}
+}
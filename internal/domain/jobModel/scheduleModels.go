@@ -0,0 +1,52 @@
+package jobModel
+
+import (
+	"context"
+	"time"
+)
+
+// Schedule is a job template that the scheduler enqueues either once at RunAt
+// or every time CronExpression matches. Schedules are persisted without a TTL
+// so they survive restarts.
+type Schedule struct {
+	Id             string     `json:"id"`
+	Name           string     `json:"name"`
+	JobType        JobType    `json:"job_type"`
+	ChatId         string     `json:"chat_id,omitempty"`
+	JobPayload     JobPayload `json:"job_payload"`
+	RunAt          time.Time  `json:"run_at,omitempty"`
+	CronExpression string     `json:"cron,omitempty"`
+	Paused         bool       `json:"paused"`
+	NextRun        time.Time  `json:"next_run,omitempty"`
+	LastRun        time.Time  `json:"last_run,omitempty"`
+	LastJobId      string     `json:"last_job_id,omitempty"`
+	CreatedTime    time.Time  `json:"created_time"`
+	TraceId        string     `json:"trace_id"`
+	Identity       string     `json:"identity,omitempty"` //jobs fired by the schedule belong to whoever created it
+}
+
+// IsRecurring a schedule with a cron expression never runs out of runs
+func (s Schedule) IsRecurring() bool {
+	return s.CronExpression != ""
+}
+
+// OwnedBy schedules created without an identity belong to everyone
+func (s Schedule) OwnedBy(identity string) bool {
+	return s.Identity == "" || s.Identity == identity
+}
+
+// IsDue reports whether the schedule should fire at the given time
+func (s Schedule) IsDue(now time.Time) bool {
+	return !s.Paused && !s.NextRun.IsZero() && !s.NextRun.After(now)
+}
+
+type ScheduleStore interface {
+	SaveSchedule(ctx context.Context, schedule Schedule) error
+	GetSchedule(ctx context.Context, id string) (Schedule, bool)
+	ListSchedules(ctx context.Context) ([]Schedule, error)
+	DeleteSchedule(ctx context.Context, id string) error
+
+	// AcquireLeaderLock returns true if owner holds the named lock after the call,
+	// either because it was free or because owner already held it (the ttl is renewed).
+	AcquireLeaderLock(ctx context.Context, name string, owner string, ttl time.Duration) bool
+}
@@ -0,0 +1,84 @@
+package jobModel
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source one retrieved chunk an answer was built from. The vector DB hands them over flattened
+// as key:value entries, one run of entries per chunk, and the API still returns them that way
+type Source struct {
+	DocumentName string    `json:"document_name,omitempty"`
+	DocumentId   string    `json:"document_id,omitempty"`
+	Page         int       `json:"page,omitempty"`
+	ChunkOrder   int       `json:"chunk_order,omitempty"`
+	ChunkId      string    `json:"chunk_id,omitempty"`
+	IngestedAt   time.Time `json:"ingested_at,omitempty"`
+}
+
+// ParseSources groups the key:value entries back into sources, a key seen twice starts the next source.
+// Unknown keys and unreadable numbers are skipped, answers saved before doc_name was added have no DocumentName
+func ParseSources(entries []string) []Source {
+	var sources []Source
+	var current Source
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		key, value, found := strings.Cut(entry, ":")
+		if !found {
+			continue
+		}
+		if seen[key] {
+			sources = append(sources, current)
+			current, seen = Source{}, make(map[string]bool)
+		}
+		seen[key] = true
+		switch key {
+		case "doc_name":
+			current.DocumentName = value
+		case "source_doc_id":
+			current.DocumentId = value
+		case "page_num":
+			current.Page, _ = strconv.Atoi(value)
+		case "chunk_order":
+			current.ChunkOrder, _ = strconv.Atoi(value)
+		case "chunk_id":
+			current.ChunkId = value
+		case "ingested_at":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.IngestedAt = time.Unix(seconds, 0).UTC()
+			}
+		default:
+			delete(seen, key)
+		}
+	}
+	if len(seen) > 0 {
+		sources = append(sources, current)
+	}
+	return sources
+}
+
+// Entries the source as the key:value entries ParseSources reads, in the order the vector DB writes them
+func (s Source) Entries() []string {
+	ingestedAt := ""
+	if !s.IngestedAt.IsZero() {
+		ingestedAt = strconv.FormatInt(s.IngestedAt.Unix(), 10)
+	}
+	return []string{
+		"doc_name:" + s.DocumentName,
+		"page_num:" + strconv.Itoa(s.Page),
+		"chunk_order:" + strconv.Itoa(s.ChunkOrder),
+		"chunk_id:" + s.ChunkId,
+		"ingested_at:" + ingestedAt,
+		"source_doc_id:" + s.DocumentId,
+	}
+}
+
+// FlattenSources the entries of every source, for the API responses that return sources as strings
+func FlattenSources(sources []Source) []string {
+	entries := make([]string, 0, 6*len(sources))
+	for _, source := range sources {
+		entries = append(entries, source.Entries()...)
+	}
+	return entries
+}
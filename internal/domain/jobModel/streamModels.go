@@ -0,0 +1,26 @@
+package jobModel
+
+import "context"
+
+type StreamEventType string
+
+const (
+	StreamEventStep  StreamEventType = "step"
+	StreamEventDelta StreamEventType = "delta"
+	StreamEventDone  StreamEventType = "done"
+)
+
+// StreamEvent one entry in a job's event log, Id is assigned by the store and only grows
+type StreamEvent struct {
+	Id   string          `json:"id"`
+	Type StreamEventType `json:"type"`
+	Data string          `json:"data"`
+}
+
+// EventStore append only log of a running job, read back by the SSE endpoint.
+// ReadEvents returns the events after afterId, or all of them when afterId is empty.
+type EventStore interface {
+	AppendEvent(ctx context.Context, jobId string, event StreamEvent) error
+	ReadEvents(ctx context.Context, jobId string, afterId string) ([]StreamEvent, error)
+	DeleteEvents(ctx context.Context, jobId string) error
+}
@@ -0,0 +1,44 @@
+package jobModel
+
+import "time"
+
+type StepOutcome string
+
+const (
+	StepOutcomeRunning StepOutcome = "running"
+	StepOutcomeSuccess StepOutcome = "success"
+	StepOutcomeFailed  StepOutcome = "failed"
+	StepOutcomeHit     StepOutcome = "hit"
+	StepOutcomeMiss    StepOutcome = "miss"
+)
+
+type StepEvent struct {
+	Step      InternalStatus `json:"step"`
+	StartTime time.Time      `json:"start_time"`
+	EndTime   time.Time      `json:"end_time,omitempty"`
+	Outcome   StepOutcome    `json:"outcome"`
+}
+
+// StartStep closes whatever step is still open as a success and opens the next one
+func (j *Job) StartStep(step InternalStatus) {
+	j.EndStep(StepOutcomeSuccess)
+	j.CurrentStep = step
+	j.Timeline = append(j.Timeline, StepEvent{
+		Step:      step,
+		StartTime: time.Now(),
+		Outcome:   StepOutcomeRunning,
+	})
+}
+
+// EndStep closes the open step, if any, with the given outcome
+func (j *Job) EndStep(outcome StepOutcome) {
+	if len(j.Timeline) == 0 {
+		return
+	}
+	last := &j.Timeline[len(j.Timeline)-1]
+	if last.Outcome != StepOutcomeRunning {
+		return
+	}
+	last.EndTime = time.Now()
+	last.Outcome = outcome
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/akolanti/GoAPI/internal/adapter"
+	"github.com/akolanti/GoAPI/internal/adapter/utils"
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/job"
+)
+
+// BatchChatHandler godoc
+// @Summary      Submit many chat questions at once
+// @Description  Creates one batch record with a child job per question. Counts as a single request against the rate limit. Questions in a shared chat are answered independently and appended to the chat as they finish.
+// @Tags         Messaging
+// @Accept       json
+// @Produce      json
+// @Param        request  body      api.BatchChatRequest   true  "Questions and optional shared chat"
+// @Success      202      {object}  api.InitBatchResponse  "Batch created - poll /batch/{id}"
+// @Failure      400      {object}  api.JobResponse        "Invalid request data or chat ID"
+// @Router       /chat/batch [post]
+func BatchChatHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		var requestData api.BatchChatRequest
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logRH.Error("Couldn't close the batch reader :", err)
+			}
+		}(r.Body)
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil || !ValidateBatchChatRequest(r.Context(), requestData) {
+			logRH.Warn("Bad batch Request: ", "error:", err, "batch size", len(requestData.Messages))
+			WriteErrorResponse(w, http.StatusBadRequest, requestData.ChatID, "Bad Request")
+			return
+		}
+
+		id := utils.GetNewUUID()
+		childIds, err := service.CreateBatch(r.Context(), job.CreateBatchParams{
+			ID:         id,
+			Messages:   requestData.Messages,
+			ChatID:     requestData.ChatID,
+			SharedChat: requestData.SharedChat,
+			TraceID:    r.Context().Value(config.TRACE_ID_KEY).(string),
+			Identity:   requestIdentity(r.Context()),
+			Deadline:   requestData.Deadline,
+		})
+		if err != nil {
+			WriteErrorResponse(w, http.StatusInternalServerError, id, "Could not create batch")
+			return
+		}
+		writeJsonResponse(w, http.StatusAccepted, adapter.ToInitBatchResponse(id, childIds))
+	}
+}
+
+// GetBatchHandler godoc
+// @Summary      Get batch status
+// @Description  Returns aggregated progress of a batch, and every child result in submission order once all of them have finished.
+// @Tags         Job Status
+// @Produce      json
+// @Param        id   path      string  true  "Batch ID"
+// @Success      200  {object}  api.BatchResponse  "Batch progress and results"
+// @Failure      404  {object}  api.JobResponse    "Batch not found or created for another user"
+// @Router       /batch/{id} [get]
+func GetBatchHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		id := utils.GetChiURLParam(r, "id")
+		parent, children, isFound := service.GetBatch(r.Context(), id)
+		if !isFound || !parent.OwnedBy(requestIdentity(r.Context())) {
+			WriteErrorResponse(w, http.StatusNotFound, id, "Batch not found")
+			return
+		}
+		writeJsonResponse(w, http.StatusOK, adapter.ToBatchResponse(parent, children))
+	}
+}
+
+func ValidateBatchChatRequest(ctx context.Context, batchReq api.BatchChatRequest) bool {
+	if len(batchReq.Messages) == 0 || len(batchReq.Messages) > config.MaxBatchSize || !validDeadline(batchReq.Deadline) {
+		return false
+	}
+	for _, message := range batchReq.Messages {
+		if strings.TrimSpace(message) == "" {
+			return false
+		}
+	}
+	if batchReq.ChatID == "" {
+		return true
+	}
+	return validateMessage(ctx, batchReq.Messages[0], batchReq.ChatID)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/job"
+)
+
+func TestValidateBatchChatRequest(t *testing.T) {
+	messageStore := store.InitMessageStore()
+	InitHandler(job.InitJobService(job.ServiceConfig{MessageStore: messageStore}))
+	ctx := context.WithValue(context.Background(), config.IDENTITY_KEY, "alice")
+	_ = messageStore.InitNewChat(ctx, "chat-alice", "alice")
+	_ = messageStore.InitNewChat(ctx, "chat-bob", "bob")
+
+	oversized := make([]string, config.MaxBatchSize+1)
+	for i := range oversized {
+		oversized[i] = fmt.Sprintf("q%d", i)
+	}
+
+	tests := []struct {
+		name    string
+		request api.BatchChatRequest
+		want    bool
+	}{
+		{name: "valid", request: api.BatchChatRequest{Messages: []string{"q0", "q1"}}, want: true},
+		{name: "largest batch", request: api.BatchChatRequest{Messages: oversized[:config.MaxBatchSize]}, want: true},
+		{name: "empty", request: api.BatchChatRequest{}},
+		{name: "oversized", request: api.BatchChatRequest{Messages: oversized}},
+		{name: "blank message", request: api.BatchChatRequest{Messages: []string{"q0", "  "}}},
+		{name: "past deadline", request: api.BatchChatRequest{Messages: []string{"q0"}, Deadline: time.Now().Add(-time.Minute)}},
+		{name: "own chat", request: api.BatchChatRequest{Messages: []string{"q0"}, ChatID: "chat-alice"}, want: true},
+		{name: "chat of another user", request: api.BatchChatRequest{Messages: []string{"q0"}, ChatID: "chat-bob"}},
+		{name: "unknown chat", request: api.BatchChatRequest{Messages: []string{"q0"}, ChatID: "missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateBatchChatRequest(ctx, tt.request); got != tt.want {
+				t.Errorf("ValidateBatchChatRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
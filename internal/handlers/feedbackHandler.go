@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akolanti/GoAPI/internal/adapter"
+	"github.com/akolanti/GoAPI/internal/adapter/utils"
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+// FeedbackHandler godoc
+// @Summary      Rate an answer
+// @Description  Rates the answer of a completed Query or MCP job, positive or negative with an optional comment. The rating is kept with the job and returned when it is polled, rating again replaces it. A negative rating evicts the answer from the semantic cache, so it isn't served to anyone again.
+// @Tags         Job Status
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string               true  "Job ID"
+// @Param        request  body      api.FeedbackRequest  true  "Rating and optional comment"
+// @Success      200      {object}  api.JobResponse      "The rated job"
+// @Failure      400      {object}  api.JobResponse      "Unknown rating or too long comment"
+// @Failure      404      {object}  api.JobResponse      "Job not found"
+// @Failure      409      {object}  api.JobResponse      "The job has no answer to rate"
+// @Router       /jobs/{id}/feedback [post]
+func FeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		id := utils.GetChiURLParam(r, "id")
+		var requestData api.FeedbackRequest
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logRH.Error("Couldn't close the feedback reader :", err)
+			}
+		}(r.Body)
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, id, "Bad Request")
+			return
+		}
+		feedback, err := validFeedback(requestData)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, id, err.Error())
+			return
+		}
+
+		result, isFound, err := service.SaveFeedback(r.Context(), id, requestIdentity(r.Context()), feedback)
+		if !isFound {
+			WriteErrorResponse(w, http.StatusNotFound, id, "Job not found")
+			return
+		}
+		if errors.Is(err, jobModel.ErrNotRateable) {
+			WriteErrorResponse(w, http.StatusConflict, id, err.Error())
+			return
+		}
+		if err != nil {
+			logRH.Error("Error saving feedback", "job Id", id, "error", err)
+			WriteErrorResponse(w, http.StatusInternalServerError, id, "Internal Server Error")
+			return
+		}
+		writeJsonResponse(w, http.StatusOK, adapter.ToAPIResponse(result))
+	}
+}
+
+// validFeedback the rating has to be positive or negative, the trimmed comment at most config.FeedbackCommentMaxLength runes
+func validFeedback(request api.FeedbackRequest) (jobModel.Feedback, error) {
+	rating := jobModel.Rating(request.Rating)
+	if rating != jobModel.RatingPositive && rating != jobModel.RatingNegative {
+		return jobModel.Feedback{}, errors.New("rating must be positive or negative")
+	}
+	comment := strings.TrimSpace(request.Comment)
+	if utf8.RuneCountInString(comment) > config.FeedbackCommentMaxLength {
+		return jobModel.Feedback{}, fmt.Errorf("comment may be at most %d characters", config.FeedbackCommentMaxLength)
+	}
+	return jobModel.Feedback{Rating: rating, Comment: comment}, nil
+}
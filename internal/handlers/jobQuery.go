@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+var listableJobTypes = []jobModel.JobType{jobModel.JobTypeQuery, jobModel.JobTypeIngest, jobModel.JobTypeMCP, jobModel.JobTypeBatch, jobModel.JobTypeErasure}
+var listableStatuses = []jobModel.JobStatus{jobModel.JobStatusQueued, jobModel.JobStatusRunning, jobModel.JobStatusComplete, jobModel.JobStatusError}
+
+var errForeignIdentity = errors.New("identity must be the caller's " + config.IdentityHeader)
+
+// parseJobQuery jobs are only listed for the calling user, an identity param naming anyone else is rejected
+func parseJobQuery(values url.Values, identity string) (jobModel.JobQuery, error) {
+	query := jobModel.JobQuery{
+		ChatId:   values.Get("chat_id"),
+		JobType:  jobModel.JobType(values.Get("job_type")),
+		Status:   jobModel.JobStatus(values.Get("status")),
+		Identity: identity,
+		Cursor:   values.Get("cursor"),
+	}
+	if requested := values.Get("identity"); requested != "" && requested != identity {
+		return query, errForeignIdentity
+	}
+	if query.JobType != "" && !slices.Contains(listableJobTypes, query.JobType) {
+		return query, fmt.Errorf("unknown job_type %q", query.JobType)
+	}
+	if query.Status != "" && !slices.Contains(listableStatuses, query.Status) {
+		return query, fmt.Errorf("unknown status %q", query.Status)
+	}
+
+	var err error
+	if query.CreatedFrom, err = parseTimeParam(values, "created_from"); err != nil {
+		return query, err
+	}
+	if query.CreatedTo, err = parseTimeParam(values, "created_to"); err != nil {
+		return query, err
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		query.Limit, err = strconv.Atoi(raw)
+		if err != nil || query.Limit < 1 || query.Limit > config.JobListMaxLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", config.JobListMaxLimit)
+		}
+	}
+	return query, nil
+}
+
+func parseTimeParam(values url.Values, name string) (time.Time, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC3339 time", name)
+	}
+	return parsed, nil
+}
+
+// parseChatQuery chats are only listed for the calling user, there is no listing without an identity
+func parseChatQuery(values url.Values, identity string) (jobModel.ChatQuery, error) {
+	query := jobModel.ChatQuery{Identity: identity, Cursor: values.Get("cursor")}
+	if identity == "" {
+		return query, fmt.Errorf("%s is required to list chats", config.IdentityHeader)
+	}
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > config.JobListMaxLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", config.JobListMaxLimit)
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
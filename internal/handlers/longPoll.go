@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/jobEvents"
+)
+
+// parseWait reads ?wait= as a duration ("30s") or plain seconds ("30"), capped at config.LongPollMaxWait
+func parseWait(r *http.Request) (time.Duration, error) {
+	raw := r.URL.Query().Get("wait")
+	if raw == "" {
+		return 0, nil
+	}
+	wait, err := time.ParseDuration(raw)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			return 0, errors.New("wait must be a duration like 30s")
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+	if wait < 0 {
+		return 0, errors.New("wait can't be negative")
+	}
+	return min(wait, config.LongPollMaxWait), nil
+}
+
+// waitForJob returns as soon as the job has finished or its status differs from the first read,
+// otherwise when wait runs out. A zero wait is a plain lookup.
+func waitForJob(ctx context.Context, id string, wait time.Duration) (jobModel.Job, bool) {
+	if wait == 0 || id == "" {
+		return validateId(id, ctx)
+	}
+
+	//subscribe before the first read so a save in between isn't missed
+	updates, unsubscribe := jobEvents.Subscribe(id)
+	defer unsubscribe()
+
+	job, isFound := validateId(id, ctx)
+	if !isFound || job.IsFinished() {
+		return job, isFound
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return job, true
+		case <-timer.C:
+			return job, true
+		case <-updates:
+			latest, found := validateId(id, ctx)
+			if !found {
+				return job, true
+			}
+			if latest.Status != job.Status {
+				return latest, true
+			}
+			//same status, e.g. the next RAG step - keep the newer copy and keep waiting
+			job = latest
+		}
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,8 +14,11 @@ import (
 	"github.com/akolanti/GoAPI/internal/adapter/utils"
 	"github.com/akolanti/GoAPI/internal/api"
 	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
 	"github.com/akolanti/GoAPI/internal/job"
+	"github.com/akolanti/GoAPI/internal/jobEvents"
 	"github.com/akolanti/GoAPI/internal/mcpImpl"
+	"github.com/akolanti/GoAPI/internal/webhook"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
@@ -38,9 +42,9 @@ func GetHandler(w http.ResponseWriter, r *http.Request) {
 // @Tags         Messaging
 // @Accept       json
 // @Produce      json
-// @Param        request  body      api.ChatRequest      true  "Chat Message and optional Chat ID"
+// @Param        request  body      api.ChatRequest      true  "Chat Message, optional Chat ID and retrieval options"
 // @Success      202      {object}  api.InitJobResponse  "Job successfully created"
-// @Failure      400      {object}  api.JobResponse      "Invalid request data or chat ID"
+// @Failure      400      {object}  api.JobResponse      "Invalid request data, chat ID or retrieval options out of bounds"
 // @Router       /chat [post]
 func ChatHandler(w http.ResponseWriter, request *http.Request) {
 
@@ -53,7 +57,7 @@ func ChatHandler(w http.ResponseWriter, request *http.Request) {
 				logRH.Error("Couldn't close the Chat handler reader :", err)
 			}
 		}(request.Body)
-		if err := json.NewDecoder(request.Body).Decode(&requestData); err != nil || !ValidateChatRequest(requestData) {
+		if err := json.NewDecoder(request.Body).Decode(&requestData); err != nil || !ValidateChatRequest(request.Context(), requestData) {
 
 			logRH.Warn("Bad Chat Request: ", "error:", err, "request data:", requestData)
 			WriteErrorResponse(w, http.StatusBadRequest, requestData.ChatID, "Bad Request")
@@ -67,25 +71,32 @@ func ChatHandler(w http.ResponseWriter, request *http.Request) {
 
 // GetStatusHandler godoc
 // @Summary      Get job status
-// @Description  Retrieves the current status of a specific job using its ID.
+// @Description  Retrieves the current status of a specific job using its ID. With wait the request is held until the job status changes or the wait runs out, the wait is capped just under the server write timeout.
 // @Tags         Job Status
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Job ID "
+// @Param        id    path      string  true   "Job ID "
+// @Param        wait  query     string  false  "Long poll duration, e.g. 30s or 30"
 // @Success      200  {object}  api.JobResponse "The current status of the job"
 // @Success      200  {object}  api.JobResponse   "Successful retrieval of job status"
-// @Failure      404  {object}  api.JobResponse   "Job not found (returns Error object within JobResponse)"
+// @Failure      400  {object}  api.JobResponse   "Invalid wait"
+// @Failure      404  {object}  api.JobResponse   "Job not found or created for another user (returns Error object within JobResponse)"
 // @Router       /status/{id} [get]
 func GetStatusHandler(w http.ResponseWriter, r *http.Request) {
 	if validateContext(r.Context()) {
 		//use chi get the url id
 		idString := utils.GetChiURLParam(r, "id")
-		result, isFound := validateId(idString, r.Context())
+		wait, err := parseWait(r)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, idString, err.Error())
+			return
+		}
+		result, isFound := waitForJob(r.Context(), idString, wait)
 
 		logRH.Debug("Get Status Request:", "URL path", r.URL.Path)
 		if !isFound {
 			WriteErrorResponse(w, http.StatusNotFound, idString, "Job not found")
-
+			return
 		}
 
 		writeJsonResponse(w, http.StatusOK, adapter.ToAPIResponse(result))
@@ -100,6 +111,8 @@ func GetStatusHandler(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        document_name  formData  string  true  "The display name of the document"
 // @Param        document       formData  file    true  "The PDF or DOCX file to upload"
+// @Param        callback_url   formData  string  false "Optional url the finished job is posted to"
+// @Param        deadline       formData  string  false "Optional RFC 3339 time, the job fails with error type TIMEOUT if it hasn't finished by then"
 // @Success      202  {object}  map[string]string "Accepted - returns job_id"
 // @Failure      400  {object}  api.JobResponse "Bad Request - Missing fields or file too large"
 // @Failure      500  {object}  api.JobResponse "Internal Server Error - Storage or Write Error"
@@ -128,6 +141,21 @@ func PostIngestHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		callbackURL := r.FormValue("callback_url")
+		if !webhook.ValidURL(callbackURL) {
+			WriteErrorResponse(w, http.StatusBadRequest, "", "callback_url must be an http(s) url")
+			return
+		}
+
+		var deadline time.Time
+		if value := r.FormValue("deadline"); value != "" {
+			deadline, err = time.Parse(time.RFC3339, value)
+			if err != nil || !validDeadline(deadline) {
+				WriteErrorResponse(w, http.StatusBadRequest, "", "deadline must be an RFC 3339 time in the future")
+				return
+			}
+		}
+
 		//get the document name the user uploads
 		fileReader, fileMetadata, err := r.FormFile("document")
 		if err != nil {
@@ -149,15 +177,362 @@ func PostIngestHandler(w http.ResponseWriter, r *http.Request) {
 			WriteErrorResponse(w, http.StatusInternalServerError, docName, "Write error")
 			return
 		}
-		processNewJobData(r, w, api.ChatRequest{}, filename, tempFilePath)
+		processNewJobData(r, w, api.ChatRequest{CallbackURL: callbackURL, Deadline: deadline}, filename, tempFilePath)
 		return
 	}
 	logRH.Warn("Invalid Context by request ", r.RemoteAddr)
 }
 
+// StreamStatusHandler godoc
+// @Summary      Stream job progress and answer tokens
+// @Description  Server-Sent Events for one job. "step" events carry an api.StreamStep, "delta" events carry answer text as the LLM generates it, and a final "done" event carries the full api.JobResponse before the stream closes. Reconnect with the Last-Event-ID header (or last_event_id query) to continue after the last event received. The answer is still saved to the job store, so polling clients are unaffected.
+// @Tags         Job Status
+// @Produce      text/event-stream
+// @Param        id             path    string  true   "Job ID"
+// @Param        Last-Event-ID  header  string  false  "Resume after this event id"
+// @Param        last_event_id  query   string  false  "Same as Last-Event-ID, for clients that can't set headers"
+// @Success      200  {string}  string           "text/event-stream of step, delta and done events"
+// @Failure      400  {object}  api.JobResponse  "Invalid Last-Event-ID"
+// @Failure      404  {object}  api.JobResponse  "Job not found or created for another user"
+// @Router       /status/{id}/stream [get]
+func StreamStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if !validateContext(r.Context()) {
+		return
+	}
+	ctx := r.Context()
+	id := utils.GetChiURLParam(r, "id")
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get("last_event_id")
+	}
+	if service.EventStore == nil {
+		WriteErrorResponse(w, http.StatusNotImplemented, id, "Streaming is not available")
+		return
+	}
+
+	//subscribe before reading anything so an event in between isn't missed
+	updates, unsubscribe := jobEvents.Subscribe(id)
+	defer unsubscribe()
+
+	job, isFound := validateId(id, ctx)
+	if !isFound {
+		WriteErrorResponse(w, http.StatusNotFound, id, "Job not found")
+		return
+	}
+	events, err := service.EventStore.ReadEvents(ctx, id, lastEventId)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, id, "Invalid Last-Event-ID")
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(config.StreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	maxDuration := time.NewTimer(config.StreamMaxDuration)
+	defer maxDuration.Stop()
+
+	for {
+		for _, event := range events {
+			if err = writeStreamEvent(w, controller, event); err != nil {
+				logRH.Debug("Stream client went away", "job Id", id, "err", err)
+				return
+			}
+			lastEventId = event.Id
+			if event.Type == jobModel.StreamEventDone {
+				return
+			}
+		}
+		if len(events) == 0 && job.IsFinished() {
+			//nothing left in the log but no done event either - MCP jobs don't log events
+			//and the log may have expired, so finish from the job store
+			writeDoneFromJob(w, controller, job)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-maxDuration.C:
+			//the client reconnects with Last-Event-ID and carries on
+			return
+		case <-keepAlive.C:
+			if err = writeStreamComment(w, controller, "keep-alive"); err != nil {
+				return
+			}
+		case <-updates:
+		}
+
+		if events, err = service.EventStore.ReadEvents(ctx, id, lastEventId); err != nil {
+			logRH.Error("Could not read job events", "job Id", id, "err", err)
+			return
+		}
+		if job, isFound = validateId(id, ctx); !isFound {
+			return
+		}
+	}
+}
+
+// ListJobsHandler godoc
+// @Summary      List jobs
+// @Description  Returns the jobs created for the calling user (X-User-Id) newest first, filtered by any combination of the query parameters. Pass next_cursor back as cursor to get the following page. Jobs expire with the job store TTL.
+// @Tags         Job Status
+// @Produce      json
+// @Param        chat_id       query     string  false  "Only jobs of this chat"
+// @Param        job_type      query     string  false  "Query, Ingest, MCP, Batch or Erasure"
+// @Param        status        query     string  false  "QUEUED, RUNNING, COMPLETE or Error"
+// @Param        identity      query     string  false  "Must be the caller's X-User-Id when given"
+// @Param        created_from  query     string  false  "RFC3339, inclusive"
+// @Param        created_to    query     string  false  "RFC3339, exclusive"
+// @Param        limit         query     int     false  "Page size, default 20, max 100"
+// @Param        cursor        query     string  false  "next_cursor of the previous page"
+// @Success      200  {object}  api.JobListResponse  "One page of jobs"
+// @Failure      400  {object}  api.JobResponse      "Invalid filter or cursor"
+// @Failure      403  {object}  api.JobResponse      "Identity is not the caller's X-User-Id"
+// @Router       /jobs [get]
+func ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		query, err := parseJobQuery(r.URL.Query(), requestIdentity(r.Context()))
+		if errors.Is(err, errForeignIdentity) {
+			WriteErrorResponse(w, http.StatusForbidden, "", err.Error())
+			return
+		} else if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, "", err.Error())
+			return
+		}
+
+		page, err := service.ListJobs(r.Context(), query)
+		if errors.Is(err, jobModel.ErrInvalidCursor) {
+			WriteErrorResponse(w, http.StatusBadRequest, "", err.Error())
+			return
+		} else if err != nil {
+			logRH.Error("Error listing jobs", "error", err)
+			WriteErrorResponse(w, http.StatusInternalServerError, "", "Internal Server Error")
+			return
+		}
+		writeJsonResponse(w, http.StatusOK, adapter.ToJobListResponse(page))
+	}
+}
+
+// ListChatsHandler godoc
+// @Summary      List chats
+// @Description  Returns the chats started for the calling user (X-User-Id), newest first. Pass next_cursor back as cursor to get the following page.
+// @Tags         Chats
+// @Produce      json
+// @Param        limit   query     int     false  "Page size, default 20, max 100"
+// @Param        cursor  query     string  false  "next_cursor of the previous page"
+// @Success      200  {object}  api.ChatListResponse  "One page of chats"
+// @Failure      400  {object}  api.JobResponse       "Missing X-User-Id, invalid limit or cursor"
+// @Router       /chats [get]
+func ListChatsHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		query, err := parseChatQuery(r.URL.Query(), requestIdentity(r.Context()))
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, "", err.Error())
+			return
+		}
+
+		page, err := service.ListChats(r.Context(), query)
+		if errors.Is(err, jobModel.ErrInvalidCursor) {
+			WriteErrorResponse(w, http.StatusBadRequest, "", err.Error())
+			return
+		} else if err != nil {
+			logRH.Error("Error listing chats", "error", err)
+			WriteErrorResponse(w, http.StatusInternalServerError, "", "Internal Server Error")
+			return
+		}
+		writeJsonResponse(w, http.StatusOK, adapter.ToChatListResponse(page))
+	}
+}
+
+// GetChatHandler godoc
+// @Summary      Get a chat transcript
+// @Description  Returns every question of the chat with its answer, sources and time, oldest first. Chats of other users are not found.
+// @Tags         Chats
+// @Produce      json
+// @Param        id   path      string                      true  "Chat ID"
+// @Success      200  {object}  api.ChatTranscriptResponse  "The chat and its turns"
+// @Failure      404  {object}  api.JobResponse             "Chat not found"
+// @Router       /chats/{id} [get]
+func GetChatHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		id := utils.GetChiURLParam(r, "id")
+		chat, entries, isFound, err := service.GetTranscript(r.Context(), id, requestIdentity(r.Context()))
+		if err != nil {
+			logRH.Error("Error reading chat", "chat Id", id, "error", err)
+			WriteErrorResponse(w, http.StatusInternalServerError, id, "Internal Server Error")
+			return
+		}
+		if !isFound {
+			WriteErrorResponse(w, http.StatusNotFound, id, "Chat not found")
+			return
+		}
+		writeJsonResponse(w, http.StatusOK, adapter.ToChatTranscriptResponse(chat, entries))
+	}
+}
+
+// ExportChatHandler godoc
+// @Summary      Export a chat
+// @Description  Downloads the chat with every question, answer and cited source (document name and page), as a Markdown document or as JSON that keeps the same schema_version-ed layout so it can be read back in.
+// @Tags         Chats
+// @Produce      json
+// @Produce      text/markdown
+// @Param        id      path      string          true   "Chat ID"
+// @Param        format  query     string          false  "md (default) or json"
+// @Success      200     {object}  api.ChatExport  "The chat, as Markdown for format=md"
+// @Failure      400     {object}  api.JobResponse "Unknown format"
+// @Failure      404     {object}  api.JobResponse "Chat not found"
+// @Router       /chats/{id}/export [get]
+func ExportChatHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		id := utils.GetChiURLParam(r, "id")
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "md"
+		}
+		if format != "md" && format != "json" {
+			WriteErrorResponse(w, http.StatusBadRequest, id, "format must be md or json")
+			return
+		}
+
+		chat, entries, isFound, err := service.GetTranscript(r.Context(), id, requestIdentity(r.Context()))
+		if err != nil {
+			logRH.Error("Error reading chat", "chat Id", id, "error", err)
+			WriteErrorResponse(w, http.StatusInternalServerError, id, "Internal Server Error")
+			return
+		}
+		if !isFound {
+			WriteErrorResponse(w, http.StatusNotFound, id, "Chat not found")
+			return
+		}
+
+		export := adapter.ToChatExport(chat, entries, time.Now())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"chat-%s.%s\"", id, format))
+		if format == "json" {
+			writeJsonResponse(w, http.StatusOK, export)
+			return
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if _, err = io.WriteString(w, adapter.ToChatMarkdown(export)); err != nil {
+			logRH.Error("Error writing chat export", "chat Id", id, "error", err)
+		}
+	}
+}
+
+// RenameChatHandler godoc
+// @Summary      Rename a chat
+// @Description  Sets the title of the chat. Until renamed a chat is titled with the start of its first question.
+// @Tags         Chats
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                 true  "Chat ID"
+// @Param        request  body      api.RenameChatRequest  true  "New title"
+// @Success      200      {object}  api.ChatSummary        "The renamed chat"
+// @Failure      400      {object}  api.JobResponse        "Missing or too long title"
+// @Failure      404      {object}  api.JobResponse        "Chat not found"
+// @Router       /chats/{id} [patch]
+func RenameChatHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		id := utils.GetChiURLParam(r, "id")
+		var requestData api.RenameChatRequest
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logRH.Error("Couldn't close the rename chat reader :", err)
+			}
+		}(r.Body)
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, id, "Bad Request")
+			return
+		}
+		title, err := validTitle(requestData.Title)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, id, err.Error())
+			return
+		}
+
+		chat, isFound, err := service.RenameChat(r.Context(), id, requestIdentity(r.Context()), title)
+		if errors.Is(err, jobModel.ErrChatNotFound) {
+			isFound, err = false, nil
+		}
+		if err != nil {
+			logRH.Error("Error renaming chat", "chat Id", id, "error", err)
+			WriteErrorResponse(w, http.StatusInternalServerError, id, "Internal Server Error")
+			return
+		}
+		if !isFound {
+			WriteErrorResponse(w, http.StatusNotFound, id, "Chat not found")
+			return
+		}
+		writeJsonResponse(w, http.StatusOK, adapter.ToChatSummary(chat))
+	}
+}
+
+// DeleteChatHandler godoc
+// @Summary      Erase a chat
+// @Description  Queues an erasure job that deletes the chat history, the caller's jobs of the chat and the cached answers created from it. Poll the returned status URL for the report of what was removed. A chat that is already gone or belongs to another user is not found.
+// @Tags         Erasure
+// @Produce      json
+// @Param        id   path      string               true  "Chat ID"
+// @Success      202  {object}  api.InitJobResponse  "Erasure job created"
+// @Failure      404  {object}  api.JobResponse      "Chat not found or belongs to another user"
+// @Router       /chats/{id} [delete]
+func DeleteChatHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		id := utils.GetChiURLParam(r, "id")
+		chat, isFound, err := service.MessageStore.GetChat(r.Context(), id)
+		if err != nil {
+			logRH.Error("Error reading chat", "chat Id", id, "error", err)
+			WriteErrorResponse(w, http.StatusInternalServerError, id, "Internal Server Error")
+			return
+		}
+		if !isFound || !chat.OwnedBy(requestIdentity(r.Context())) {
+			WriteErrorResponse(w, http.StatusNotFound, id, "Chat not found")
+			return
+		}
+		queueErasure(w, r, job.CreateJobParams{EraseChatID: id})
+	}
+}
+
+// DeleteIdentityHandler godoc
+// @Summary      Erase everything of an identity
+// @Description  Queues an erasure job that deletes every job created for the identity (X-User-Id), the chats started for it or that those jobs belong to with all of their jobs, and the cached answers created from them. Erasure jobs themselves are kept, they only hold ids.
+// @Tags         Erasure
+// @Produce      json
+// @Param        identity  path      string               true  "Identity, as sent in X-User-Id"
+// @Success      202       {object}  api.InitJobResponse  "Erasure job created"
+// @Failure      403       {object}  api.JobResponse      "Identity is not the caller's X-User-Id"
+// @Router       /identities/{identity} [delete]
+func DeleteIdentityHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		identity := utils.GetChiURLParam(r, "identity")
+		//users can only erase themselves
+		if identity != requestIdentity(r.Context()) {
+			WriteErrorResponse(w, http.StatusForbidden, identity, "Forbidden")
+			return
+		}
+		queueErasure(w, r, job.CreateJobParams{EraseIdentity: identity})
+	}
+}
+
+func queueErasure(w http.ResponseWriter, r *http.Request, params job.CreateJobParams) {
+	params.ID = utils.GetNewUUID()
+	params.IsErasure = true
+	params.TraceID = r.Context().Value(config.TRACE_ID_KEY).(string)
+	params.Identity = requestIdentity(r.Context())
+	service.CreateJob(params)
+	writeJsonResponse(w, http.StatusAccepted, adapter.ToInitJobResponse(params.ID))
+}
+
 // MCPHandler godoc
-// @Summary      Submit a stateless MCP query
-// @Description  Accepts a question, runs tool-use via MCP and returns a job ID. This is stateless - each request is independent with no conversation history. Use /chat for multi-turn conversations.
+// @Summary      Submit an MCP query
+// @Description  Accepts a question, runs tool-use via MCP and returns a job ID. Every question is a turn of a chat: without chatID a new chat is started and its id is returned as chat_id when the job is polled. With chatID the earlier turns, their tool calls and tool results included, are replayed before the question so follow ups can refer to them. A chat of another user is a bad request.
 // @Tags         MCP
 // @Accept       json
 // @Produce      json
@@ -174,16 +549,29 @@ func MCPHandler(w http.ResponseWriter, request *http.Request) {
 				logRH.Error("Couldn't close the mcp reader :", err)
 			}
 		}(request.Body)
-		if err := json.NewDecoder(request.Body).Decode(&requestData); err != nil || !ValidateMcpRequest(requestData) {
+		if err := json.NewDecoder(request.Body).Decode(&requestData); err != nil || !ValidateMcpRequest(request.Context(), requestData) {
 			logRH.Warn("Bad mcp Request: ", "error:", err, "request data:", requestData)
 			WriteErrorResponse(w, http.StatusBadRequest, "", "Bad Request")
 			return
 		}
 
 		jobId := utils.GetNewUUID()
-		traceId := request.Context().Value(config.TRACE_ID_KEY).(string)
+		chatId := requestData.ChatID
+		isNewChat := chatId == ""
+		if isNewChat {
+			chatId = utils.GetNewUUID()
+		}
 
-		mcpImpl.HandleRequest(request.Context(), requestData.Message, jobId, traceId)
+		mcpImpl.HandleRequest(request.Context(), mcpImpl.Request{
+			JobId:       jobId,
+			TraceId:     request.Context().Value(config.TRACE_ID_KEY).(string),
+			ChatId:      chatId,
+			IsNewChat:   isNewChat,
+			Question:    requestData.Message,
+			CallbackURL: requestData.CallbackURL,
+			Identity:    requestIdentity(request.Context()),
+			Deadline:    requestData.Deadline,
+		})
 		writeJsonResponse(w, http.StatusAccepted, adapter.ToInitJobResponse(jobId))
 		return
 	}
@@ -191,18 +579,25 @@ func MCPHandler(w http.ResponseWriter, request *http.Request) {
 
 // MCPStatusHandler godoc
 // @Summary      Get MCP job status
-// @Description  Poll this endpoint to check the status of an MCP query. Returns the final answer when complete.
+// @Description  Poll this endpoint to check the status of an MCP query. Returns the final answer when complete. Supports the same wait long poll as /status/{id}.
 // @Tags         MCP
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Job ID from the /mcp response"
+// @Param        id    path      string  true   "Job ID from the /mcp response"
+// @Param        wait  query     string  false  "Long poll duration, e.g. 30s or 30"
 // @Success      200  {object}  api.JobResponse  "Current job status and result if complete"
-// @Failure      404  {object}  api.JobResponse  "Job not found"
+// @Failure      400  {object}  api.JobResponse  "Invalid wait"
+// @Failure      404  {object}  api.JobResponse  "Job not found or created for another user"
 // @Router       /mcp/status/{id} [get]
 func MCPStatusHandler(w http.ResponseWriter, r *http.Request) {
 	if validateContext(r.Context()) {
 		idString := utils.GetChiURLParam(r, "id")
-		result, isFound := validateId(idString, r.Context())
+		wait, err := parseWait(r)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, idString, err.Error())
+			return
+		}
+		result, isFound := waitForJob(r.Context(), idString, wait)
 
 		logRH.Debug("Get MCP Status Request:", "URL path", r.URL.Path)
 		if !isFound {
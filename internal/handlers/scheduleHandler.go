@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/akolanti/GoAPI/internal/adapter"
+	"github.com/akolanti/GoAPI/internal/adapter/utils"
+	"github.com/akolanti/GoAPI/internal/api"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/scheduler"
+)
+
+// CreateScheduleHandler godoc
+// @Summary      Schedule a job
+// @Description  Enqueues a Query, MCP or Ingest job once at run_at or repeatedly on a cron expression (UTC). Schedules survive restarts and only one replica fires each run.
+// @Tags         Schedules
+// @Accept       json
+// @Produce      json
+// @Param        request  body      api.ScheduleRequest   true  "Job template and timing"
+// @Success      201      {object}  api.ScheduleResponse  "Schedule created"
+// @Failure      400      {object}  api.JobResponse       "Invalid schedule"
+// @Router       /schedules [post]
+func CreateScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		var requestData api.ScheduleRequest
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logRH.Error("Couldn't close the schedule reader :", err)
+			}
+		}(r.Body)
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			logRH.Warn("Bad schedule request: ", "error:", err)
+			WriteErrorResponse(w, http.StatusBadRequest, "", "Bad Request")
+			return
+		}
+
+		traceId := r.Context().Value(config.TRACE_ID_KEY).(string)
+		schedule, err := scheduler.CreateSchedule(r.Context(), adapter.ToSchedule(requestData, traceId, requestIdentity(r.Context())))
+		if err != nil {
+			writeScheduleError(w, "", err)
+			return
+		}
+		writeJsonResponse(w, http.StatusCreated, adapter.ToScheduleResponse(schedule))
+	}
+}
+
+// ListSchedulesHandler godoc
+// @Summary      List schedules
+// @Description  Returns the schedules of the calling user (X-User-Id), including paused and already fired one-off schedules.
+// @Tags         Schedules
+// @Produce      json
+// @Success      200  {array}   api.ScheduleResponse  "The caller's schedules"
+// @Failure      500  {object}  api.JobResponse       "Store error"
+// @Router       /schedules [get]
+func ListSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		schedules, err := scheduler.ListSchedules(r.Context(), requestIdentity(r.Context()))
+		if err != nil {
+			writeScheduleError(w, "", err)
+			return
+		}
+		writeJsonResponse(w, http.StatusOK, adapter.ToScheduleResponses(schedules))
+	}
+}
+
+// PauseScheduleHandler godoc
+// @Summary      Pause a schedule
+// @Description  Stops a schedule from firing until it is resumed.
+// @Tags         Schedules
+// @Produce      json
+// @Param        id   path      string  true  "Schedule ID"
+// @Success      200  {object}  api.ScheduleResponse  "Paused schedule"
+// @Failure      404  {object}  api.JobResponse       "Schedule not found or created by another user"
+// @Router       /schedules/{id}/pause [post]
+func PauseScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	setSchedulePaused(w, r, true)
+}
+
+// ResumeScheduleHandler godoc
+// @Summary      Resume a schedule
+// @Description  Resumes a paused schedule. Cron schedules continue from the next matching time, runs missed while paused are skipped.
+// @Tags         Schedules
+// @Produce      json
+// @Param        id   path      string  true  "Schedule ID"
+// @Success      200  {object}  api.ScheduleResponse  "Resumed schedule"
+// @Failure      404  {object}  api.JobResponse       "Schedule not found or created by another user"
+// @Router       /schedules/{id}/resume [post]
+func ResumeScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	setSchedulePaused(w, r, false)
+}
+
+// DeleteScheduleHandler godoc
+// @Summary      Delete a schedule
+// @Description  Removes a schedule. Jobs it already enqueued are not affected.
+// @Tags         Schedules
+// @Param        id   path      string  true  "Schedule ID"
+// @Success      204  "Schedule deleted"
+// @Failure      404  {object}  api.JobResponse  "Schedule not found or created by another user"
+// @Router       /schedules/{id} [delete]
+func DeleteScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	if validateContext(r.Context()) {
+		id := utils.GetChiURLParam(r, "id")
+		if err := scheduler.DeleteSchedule(r.Context(), id, requestIdentity(r.Context())); err != nil {
+			writeScheduleError(w, id, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func setSchedulePaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if validateContext(r.Context()) {
+		id := utils.GetChiURLParam(r, "id")
+		schedule, err := scheduler.SetPaused(r.Context(), id, requestIdentity(r.Context()), paused)
+		if err != nil {
+			writeScheduleError(w, id, err)
+			return
+		}
+		writeJsonResponse(w, http.StatusOK, adapter.ToScheduleResponse(schedule))
+	}
+}
+
+func writeScheduleError(w http.ResponseWriter, id string, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrScheduleNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, id, "Schedule not found")
+	case errors.Is(err, scheduler.ErrInvalidSchedule):
+		WriteErrorResponse(w, http.StatusBadRequest, id, err.Error())
+	default:
+		logRH.Error("Schedule store error", "error", err)
+		WriteErrorResponse(w, http.StatusInternalServerError, id, "Internal Server Error")
+	}
+}
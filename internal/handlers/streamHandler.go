@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/adapter"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func writeDoneFromJob(w io.Writer, controller *http.ResponseController, job jobModel.Job) {
+	payload, err := json.Marshal(adapter.ToAPIResponse(job))
+	if err != nil {
+		logRH.Error("Could not marshal job for stream", "job Id", job.Id, "err", err)
+		return
+	}
+	_ = writeStreamEvent(w, controller, jobModel.StreamEvent{Type: jobModel.StreamEventDone, Data: string(payload)})
+}
+
+// writeStreamEvent data is split over several data lines, clients join them back with newlines
+func writeStreamEvent(w io.Writer, controller *http.ResponseController, event jobModel.StreamEvent) error {
+	var frame strings.Builder
+	if event.Id != "" {
+		fmt.Fprintf(&frame, "id: %s\n", event.Id)
+	}
+	fmt.Fprintf(&frame, "event: %s\n", event.Type)
+	data := strings.ReplaceAll(strings.ReplaceAll(event.Data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&frame, "data: %s\n", line)
+	}
+	frame.WriteString("\n")
+	return writeStreamFrame(w, controller, frame.String())
+}
+
+func writeStreamComment(w io.Writer, controller *http.ResponseController, comment string) error {
+	return writeStreamFrame(w, controller, ": "+comment+"\n\n")
+}
+
+// writeStreamFrame pushes the write deadline back first, the server WriteTimeout would cut the stream off otherwise
+func writeStreamFrame(w io.Writer, controller *http.ResponseController, frame string) error {
+	if err := controller.SetWriteDeadline(time.Now().Add(config.StreamWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := io.WriteString(w, frame); err != nil {
+		return err
+	}
+	return controller.Flush()
+}
@@ -3,9 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/akolanti/GoAPI/internal/adapter"
 	"github.com/akolanti/GoAPI/internal/adapter/utils"
@@ -13,6 +18,8 @@ import (
 	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
 	"github.com/akolanti/GoAPI/internal/job"
+	"github.com/akolanti/GoAPI/internal/rag/vectorDB"
+	"github.com/akolanti/GoAPI/internal/webhook"
 )
 
 func writeJsonResponse(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -30,7 +37,12 @@ func validateId(id string, context context.Context) (result jobModel.Job, isFoun
 		logRH.Warn("Empty Job ID")
 		return jobModel.Job{}, false
 	}
-	return service.GetJobStatus(id, context)
+	result, isFound = service.GetJobStatus(id, context)
+	//a job of another user is not found, the same as a missing one
+	if isFound && !result.OwnedBy(requestIdentity(context)) {
+		return jobModel.Job{}, false
+	}
+	return result, isFound
 }
 
 func validateContext(ctx context.Context) bool {
@@ -50,6 +62,12 @@ func validateContext(ctx context.Context) bool {
 	}
 }
 
+// requestIdentity the end user resolved by the middleware
+func requestIdentity(ctx context.Context) string {
+	identity, _ := ctx.Value(config.IDENTITY_KEY).(string)
+	return identity
+}
+
 func WriteErrorResponse(w http.ResponseWriter, httpCode int, id string, error string) {
 	writeJsonResponse(w, httpCode, adapter.BadRequest(id, error, httpCode))
 }
@@ -60,7 +78,7 @@ func getTargetDirectory() (string, string) {
 		return "", "Storage Error"
 	}
 
-	targetDir := filepath.Join(root, "temporary_data")
+	targetDir := filepath.Join(root, config.TemporaryDataDir)
 	if err := os.MkdirAll(targetDir, 0750); err != nil {
 		return "", "Storage Error"
 	}
@@ -95,6 +113,10 @@ func processNewJobData(request *http.Request, w http.ResponseWriter, requestData
 		DocumentSource:   docPath,
 		IsDocumentIngest: docName != "" && docPath != "",
 		IsMCPCall:        false,
+		CallbackURL:      requestData.CallbackURL,
+		Identity:         requestIdentity(request.Context()),
+		Deadline:         requestData.Deadline,
+		Retrieval:        adapter.ToRetrievalOptions(requestData.Retrieval),
 	})
 
 	res := adapter.ToInitJobResponse(id)
@@ -102,15 +124,43 @@ func processNewJobData(request *http.Request, w http.ResponseWriter, requestData
 
 }
 
-func ValidateChatRequest(chatReq api.ChatRequest) bool {
-	return validateMessage(chatReq.Message, chatReq.ChatID)
+func ValidateChatRequest(ctx context.Context, chatReq api.ChatRequest) bool {
+	return webhook.ValidURL(chatReq.CallbackURL) && validDeadline(chatReq.Deadline) && validRetrieval(chatReq.Retrieval) && validateMessage(ctx, chatReq.Message, chatReq.ChatID)
+}
+
+// validRetrieval no options, or options within the server bounds, see config.RetrievalMaxTopK
+func validRetrieval(options *api.RetrievalOptions) bool {
+	if options == nil {
+		return true
+	}
+	return options.TopK >= 0 && options.TopK <= config.RetrievalMaxTopK &&
+		options.MinScore >= 0 && options.MinScore <= 1 &&
+		vectorDB.SearchableCollection(options.Collection)
+}
+
+func ValidateMcpRequest(ctx context.Context, req api.MCPRequest) bool {
+	return webhook.ValidURL(req.CallbackURL) && validDeadline(req.Deadline) && validateMessage(ctx, req.Message, req.ChatID)
 }
 
-func ValidateMcpRequest(req api.MCPRequest) bool {
-	return req.Message != ""
+// validDeadline no deadline at all, or one that hasn't passed yet
+func validDeadline(deadline time.Time) bool {
+	return deadline.IsZero() || deadline.After(time.Now())
+}
+
+// validTitle the trimmed title, it may not be empty or longer than config.ChatTitleMaxLength runes
+func validTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", errors.New("title is required")
+	}
+	if utf8.RuneCountInString(title) > config.ChatTitleMaxLength {
+		return "", fmt.Errorf("title may be at most %d characters", config.ChatTitleMaxLength)
+	}
+	return title, nil
 }
 
-func validateMessage(message string, id string) bool {
+// validateMessage an existing chat has to belong to the calling user
+func validateMessage(ctx context.Context, message string, id string) bool {
 
 	if message == "" {
 		return false
@@ -118,6 +168,6 @@ func validateMessage(message string, id string) bool {
 	if id == "" {
 		return true
 	}
-	return service.MessageStore.ValidateChatId(context.Background(), id)
+	return service.OwnsChat(ctx, id, requestIdentity(ctx))
 }
 
@@ -0,0 +1,146 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+func newBatchService() (*Service, *store.InMemoryMessageStore) {
+	logJH = logger_i.NewLogger("TestBatch")
+	messageStore := store.InitMessageStore()
+	return InitJobService(ServiceConfig{
+		JobChannel:        make(chan jobModel.Job, config.MaxBatchSize),
+		DispatcherChannel: make(chan bool, config.MaxBatchSize),
+		JobStore:          store.InitInMemoryJobStore(),
+		MessageStore:      messageStore,
+	}), messageStore
+}
+
+// queuedJobs drains the jobs CreateBatch handed to the worker pool
+func queuedJobs(s *Service) []jobModel.Job {
+	var jobs []jobModel.Job
+	for len(s.JobChannel) > 0 {
+		jobs = append(jobs, <-s.JobChannel)
+	}
+	return jobs
+}
+
+func TestCreateBatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "batch-trace")
+	messages := []string{"q0", "q1", "q2"}
+
+	tests := []struct {
+		name       string
+		sharedChat bool
+	}{
+		{name: "independent chats"},
+		{name: "shared chat", sharedChat: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, messageStore := newBatchService()
+			childIds, err := s.CreateBatch(ctx, CreateBatchParams{ID: "batch-1", Messages: messages, SharedChat: tt.sharedChat, TraceID: "batch-trace", Identity: "alice"})
+			if err != nil || len(childIds) != len(messages) {
+				t.Fatalf("Expected %d child ids, got %v %v", len(messages), childIds, err)
+			}
+
+			parent, found := s.JobStore.GetJob(ctx, "batch-1")
+			if !found || parent.JobType != jobModel.JobTypeBatch || fmt.Sprint(parent.JobPayload.ChildJobIds) != fmt.Sprint(childIds) {
+				t.Errorf("Expected the parent saved with its children, got %+v", parent)
+			}
+
+			jobs := queuedJobs(s)
+			chats := make(map[string]bool)
+			for i, child := range jobs {
+				if child.Id != childIds[i] || child.JobPayload.Question != messages[i] || child.ParentId != "batch-1" || child.Identity != "alice" {
+					t.Errorf("Expected child %d to be %s asking %s, got %+v", i, childIds[i], messages[i], child)
+				}
+				if !messageStore.ValidateChatId(ctx, child.ChatId) {
+					t.Errorf("Expected the chat %s of child %d to exist", child.ChatId, i)
+				}
+				chats[child.ChatId] = true
+			}
+			if len(jobs) != len(messages) {
+				t.Fatalf("Expected %d queued children, got %d", len(messages), len(jobs))
+			}
+
+			if tt.sharedChat {
+				if len(chats) != 1 || !chats[parent.ChatId] {
+					t.Errorf("Expected every child in the batch chat %s, got %v", parent.ChatId, chats)
+				}
+			} else if len(chats) != len(messages) || parent.ChatId != "" {
+				t.Errorf("Expected a chat per child and none on the batch, got %v and %q", chats, parent.ChatId)
+			}
+		})
+	}
+}
+
+func TestGetBatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "batch-trace")
+	s, _ := newBatchService()
+	childIds, _ := s.CreateBatch(ctx, CreateBatchParams{ID: "batch-1", Messages: []string{"q0", "q1"}, TraceID: "batch-trace"})
+
+	//only the first child has been picked up by a worker
+	first := queuedJobs(s)[0]
+	first.Status = jobModel.JobStatusRunning
+	_ = s.JobStore.SaveJob(ctx, first)
+
+	parent, children, found := s.GetBatch(ctx, "batch-1")
+	if !found || parent.Id != "batch-1" {
+		t.Fatalf("Expected the batch found, got %+v %v", parent, found)
+	}
+	if len(children) != 2 || children[childIds[0]].Status != jobModel.JobStatusRunning || children[childIds[1]].Status != jobModel.JobStatusQueued {
+		t.Errorf("Expected a running and a queued child, got %+v", children)
+	}
+
+	if _, _, found = s.GetBatch(ctx, childIds[0]); found {
+		t.Error("Expected a child job not to be read as a batch")
+	}
+	if _, _, found = s.GetBatch(ctx, "missing"); found {
+		t.Error("Expected a missing batch not to be found")
+	}
+}
+
+func TestSyncBatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "batch-trace")
+	s, _ := newBatchService()
+	_, _ = s.CreateBatch(ctx, CreateBatchParams{ID: "batch-1", Messages: []string{"q0", "q1", "q2"}, TraceID: "batch-trace"})
+	children := queuedJobs(s)
+
+	finish := func(child jobModel.Job, status jobModel.JobStatus) {
+		child.Status = status
+		_ = s.JobStore.SaveJob(ctx, child)
+		s.SyncBatch(ctx, child)
+	}
+	status := func() jobModel.JobStatus {
+		parent, _ := s.JobStore.GetJob(ctx, "batch-1")
+		return parent.Status
+	}
+
+	finish(children[0], jobModel.JobStatusRunning)
+	if got := status(); got != jobModel.JobStatusRunning {
+		t.Errorf("Expected the batch running once a child started, got %s", got)
+	}
+	finish(children[0], jobModel.JobStatusComplete)
+	finish(children[1], jobModel.JobStatusError)
+	if got := status(); got != jobModel.JobStatusRunning {
+		t.Errorf("Expected the batch running while a child is queued, got %s", got)
+	}
+
+	//the last child expired before a worker got to it
+	s.JobStore.DeleteJob(ctx, children[2].Id)
+	s.SyncBatch(ctx, children[1])
+	if got := status(); got != jobModel.JobStatusComplete {
+		t.Errorf("Expected the batch complete once no child is left to run, got %s", got)
+	}
+	page, _ := s.ListJobs(ctx, jobModel.JobQuery{Status: jobModel.JobStatusComplete, JobType: jobModel.JobTypeBatch})
+	if len(page.Jobs) != 1 || page.Jobs[0].Id != "batch-1" {
+		t.Errorf("Expected the batch listed as complete, got %+v", page.Jobs)
+	}
+}
@@ -0,0 +1,59 @@
+package job
+
+import (
+	"context"
+
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+// GetChat found is false for a chat that doesn't exist and for one that belongs to another identity,
+// callers can't tell the two apart
+func (s *Service) GetChat(ctx context.Context, chatId string, identity string) (chat jobModel.ChatInfo, found bool, err error) {
+	if chatId == "" {
+		return chat, false, nil
+	}
+	chat, found, err = s.MessageStore.GetChat(ctx, chatId)
+	if err != nil || !found || !chat.OwnedBy(identity) {
+		return jobModel.ChatInfo{}, false, err
+	}
+	return chat, true, nil
+}
+
+// OwnsChat whether identity may add to the chat, store errors count as no
+func (s *Service) OwnsChat(ctx context.Context, chatId string, identity string) bool {
+	_, found, err := s.GetChat(ctx, chatId, identity)
+	if err != nil {
+		logJH.Error("Error reading chat", "chat Id", chatId, "error", err)
+	}
+	return found
+}
+
+func (s *Service) ListChats(ctx context.Context, query jobModel.ChatQuery) (jobModel.ChatPage, error) {
+	return s.MessageStore.ListChats(ctx, query)
+}
+
+// GetTranscript the chat with every answered turn, oldest first
+func (s *Service) GetTranscript(ctx context.Context, chatId string, identity string) (jobModel.ChatInfo, []jobModel.ChatEntry, bool, error) {
+	chat, found, err := s.GetChat(ctx, chatId, identity)
+	if err != nil || !found {
+		return chat, nil, false, err
+	}
+	entries, err := s.MessageStore.GetTranscript(ctx, chatId)
+	if err != nil {
+		return chat, nil, false, err
+	}
+	return chat, entries, true, nil
+}
+
+// RenameChat returns the renamed chat, found is false when the identity doesn't own it
+func (s *Service) RenameChat(ctx context.Context, chatId string, identity string, title string) (jobModel.ChatInfo, bool, error) {
+	chat, found, err := s.GetChat(ctx, chatId, identity)
+	if err != nil || !found {
+		return chat, false, err
+	}
+	if err = s.MessageStore.RenameChat(ctx, chatId, title); err != nil {
+		return chat, false, err
+	}
+	chat.Title = title
+	return chat, true, nil
+}
@@ -0,0 +1,46 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/metrics"
+)
+
+// SaveFeedback rates the job's answer, found is false for a job that doesn't exist or belongs to another identity.
+// A negative rating evicts the answer from the semantic cache before it returns, so it isn't served again
+func (s *Service) SaveFeedback(ctx context.Context, jobId string, identity string, feedback jobModel.Feedback) (jobModel.Job, bool, error) {
+	job, found := s.JobStore.GetJob(ctx, jobId)
+	if !found || !job.OwnedBy(identity) {
+		return jobModel.Job{}, false, nil
+	}
+	if !job.Rateable() {
+		return job, true, jobModel.ErrNotRateable
+	}
+
+	previous := job.Feedback
+	feedback.Time = time.Now()
+	job.Feedback = &feedback
+	if err := s.JobStore.SaveJob(ctx, job); err != nil {
+		return job, true, err
+	}
+	//a repeated rating isn't counted twice
+	if previous == nil || previous.Rating != feedback.Rating {
+		metrics.CaptureFeedback(string(feedback.Rating), string(job.JobType))
+	}
+
+	if feedback.Rating != jobModel.RatingNegative {
+		return job, true, nil
+	}
+	if s.AnswerCache == nil {
+		logJH.Warn("No answer cache to evict the negatively rated answer from", "job Id", jobId)
+		return job, true, nil
+	}
+	evicted, err := s.AnswerCache.EvictAnswer(ctx, jobId, job.JobPayload.Answer)
+	if err != nil {
+		return job, true, err
+	}
+	metrics.CaptureCacheEvictions(evicted)
+	return job, true, nil
+}
@@ -0,0 +1,59 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+type fakeAnswerCache struct {
+	evicted []string
+}
+
+func (c *fakeAnswerCache) EvictAnswer(ctx context.Context, jobId string, answer string) (int, error) {
+	c.evicted = append(c.evicted, jobId+":"+answer)
+	return 1, nil
+}
+
+func TestSaveFeedback(t *testing.T) {
+	logJH = logger_i.NewLogger("TestSaveFeedback")
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "feedback-trace")
+	cache := &fakeAnswerCache{}
+	jobStore := store.InitInMemoryJobStore()
+	s := InitJobService(ServiceConfig{JobStore: jobStore, AnswerCache: cache})
+
+	answered := jobModel.Job{Id: "job-1", Identity: "alice", JobType: jobModel.JobTypeQuery, Status: jobModel.JobStatusComplete,
+		JobPayload: jobModel.JobPayload{Question: "q", Answer: "wrong answer"}}
+	_ = jobStore.SaveJob(ctx, answered)
+	_ = jobStore.SaveJob(ctx, jobModel.Job{Id: "job-2", JobType: jobModel.JobTypeQuery, Status: jobModel.JobStatusRunning})
+
+	if _, found, _ := s.SaveFeedback(ctx, "job-1", "bob", jobModel.Feedback{Rating: jobModel.RatingNegative}); found {
+		t.Error("Expected the job of another identity not to be found")
+	}
+	if _, found, err := s.SaveFeedback(ctx, "job-2", "", jobModel.Feedback{Rating: jobModel.RatingPositive}); !found || !errors.Is(err, jobModel.ErrNotRateable) {
+		t.Errorf("Expected a running job not to be rateable, got %v %v", found, err)
+	}
+
+	job, found, err := s.SaveFeedback(ctx, "job-1", "alice", jobModel.Feedback{Rating: jobModel.RatingPositive, Comment: "thanks"})
+	if err != nil || !found || job.Feedback == nil || job.Feedback.Comment != "thanks" || job.Feedback.Time.IsZero() {
+		t.Fatalf("Expected the rating saved, got %+v %v %v", job.Feedback, found, err)
+	}
+	if len(cache.evicted) != 0 {
+		t.Errorf("Expected a positive rating to leave the cache alone, got %v", cache.evicted)
+	}
+
+	if _, _, err = s.SaveFeedback(ctx, "job-1", "alice", jobModel.Feedback{Rating: jobModel.RatingNegative}); err != nil {
+		t.Fatalf("SaveFeedback failed: %v", err)
+	}
+	if len(cache.evicted) != 1 || cache.evicted[0] != "job-1:wrong answer" {
+		t.Errorf("Expected the answer evicted, got %v", cache.evicted)
+	}
+	if stored, _ := jobStore.GetJob(ctx, "job-1"); stored.Feedback == nil || stored.Feedback.Rating != jobModel.RatingNegative || stored.Feedback.Comment != "" {
+		t.Errorf("Expected the new rating to replace the old one, got %+v", stored.Feedback)
+	}
+}
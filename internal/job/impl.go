@@ -2,12 +2,15 @@ package job
 
 import (
 	"context"
+	"net/http"
 	"sync/atomic"
 	"time"
 
+	"github.com/akolanti/GoAPI/internal/adapter/utils"
 	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
 	"github.com/akolanti/GoAPI/internal/metrics"
+	"github.com/akolanti/GoAPI/internal/webhook"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
@@ -19,7 +22,117 @@ func (s *Service) CreateJob(newJob CreateJobParams) {
 	s.pushToJobChannel(newJob)
 	if newJob.IsNewChat {
 		logJH.Info("Create new chat")
-		s.initNewChat(newJob.ChatID, newJob.TraceID)
+		s.initNewChat(newJob.ChatID, newJob.Identity, newJob.TraceID)
+	}
+}
+
+// CreateBatch saves the parent batch record first so it can be polled straight away,
+// then queues every question as an ordinary child job. It returns the child job ids in
+// the same order as the messages. The parent's status follows its children, see SyncBatch
+func (s *Service) CreateBatch(ctx context.Context, batch CreateBatchParams) ([]string, error) {
+	logJH.With("traceId", batch.TraceID, "batch id", batch.ID)
+	logJH.Info("To create new batch", "size", len(batch.Messages))
+
+	sharedChatId := batch.ChatID
+	if sharedChatId == "" && batch.SharedChat {
+		sharedChatId = utils.GetNewUUID()
+	}
+
+	childIds := make([]string, len(batch.Messages))
+	for i := range batch.Messages {
+		childIds[i] = utils.GetNewUUID()
+	}
+
+	children := make([]jobModel.Job, len(batch.Messages))
+	for i, message := range batch.Messages {
+		chatId := sharedChatId
+		if chatId == "" {
+			chatId = utils.GetNewUUID()
+		}
+		children[i] = queuedJob(CreateJobParams{
+			ID:       childIds[i],
+			ChatID:   chatId,
+			Message:  message,
+			TraceID:  batch.TraceID,
+			ParentID: batch.ID,
+			Identity: batch.Identity,
+			Deadline: batch.Deadline,
+		})
+	}
+
+	parent := jobModel.Job{
+		Id:          batch.ID,
+		ChatId:      sharedChatId,
+		TraceId:     batch.TraceID,
+		Identity:    batch.Identity,
+		JobType:     jobModel.JobTypeBatch,
+		Status:      jobModel.JobStatusQueued,
+		CreatedTime: time.Now(),
+		JobPayload:  jobModel.JobPayload{ChildJobIds: childIds},
+	}
+	if err := s.JobStore.SaveJob(ctx, parent); err != nil {
+		logJH.Error("Error saving batch", "error", err)
+		return nil, err
+	}
+	//every child is saved before the first one is queued, so one missing later on has expired
+	for _, child := range children {
+		s.saveQueued(child)
+	}
+
+	//a shared new chat must exist before any child can finish and append to it
+	if sharedChatId != "" && batch.ChatID == "" {
+		s.initNewChat(sharedChatId, batch.Identity, batch.TraceID)
+	}
+
+	for _, child := range children {
+		if sharedChatId == "" {
+			s.initNewChat(child.ChatId, batch.Identity, batch.TraceID)
+		}
+		s.queue(child)
+	}
+	return childIds, nil
+}
+
+// GetBatch returns the parent record and whatever children are still in the store,
+// children that have expired or been erased are missing from the map
+func (s *Service) GetBatch(ctx context.Context, id string) (parent jobModel.Job, children map[string]jobModel.Job, isFound bool) {
+	parent, isFound = s.GetJobStatus(id, ctx)
+	if !isFound || parent.JobType != jobModel.JobTypeBatch {
+		return parent, nil, false
+	}
+	children = make(map[string]jobModel.Job, len(parent.JobPayload.ChildJobIds))
+	for _, childId := range parent.JobPayload.ChildJobIds {
+		if child, found := s.JobStore.GetJob(ctx, childId); found {
+			children[childId] = child
+		}
+	}
+	return parent, children, true
+}
+
+// SyncBatch brings the stored status of child's batch up to date, so the batch is polled and listed
+// like any other job. Children finishing together can each save what they saw, so the status is
+// read back once more after it was saved
+func (s *Service) SyncBatch(ctx context.Context, child jobModel.Job) {
+	if child.ParentId == "" {
+		return
+	}
+	for range 2 {
+		parent, children, found := s.GetBatch(ctx, child.ParentId)
+		if !found || parent.IsFinished() {
+			return
+		}
+		status := jobModel.NewBatchProgress(parent, children).Status()
+		if status == parent.Status {
+			return
+		}
+		parent.Status = status
+		if parent.IsFinished() {
+			parent.EndTime = time.Now()
+		}
+		if err := s.JobStore.SaveJob(ctx, parent); err != nil {
+			logJH.Error("Error saving batch status", "batch id", parent.Id, "error", err)
+			return
+		}
 	}
 }
 
@@ -30,11 +143,25 @@ func (s *Service) GetJobStatus(id string, ctx context.Context) (result jobModel.
 	return result, false
 }
 
+func (s *Service) ListJobs(ctx context.Context, query jobModel.JobQuery) (jobModel.JobPage, error) {
+	return s.JobStore.ListJobs(ctx, query)
+}
+
 // private methods
 func (s *Service) pushToJobChannel(newJob CreateJobParams) {
+	_job := queuedJob(newJob)
+	s.saveQueued(_job)
+	s.queue(_job)
+}
 
+// queuedJob the record of newJob until a worker picks it up
+func queuedJob(newJob CreateJobParams) jobModel.Job {
 	_job := jobModel.Job{}
 	_job.Id = newJob.ID
+	_job.ParentId = newJob.ParentID
+	_job.CallbackURL = newJob.CallbackURL
+	_job.Identity = newJob.Identity
+	_job.Deadline = newJob.Deadline
 	_job.CreatedTime = time.Now()
 	_job.TraceId = newJob.TraceID
 	_job.Status = jobModel.JobStatusQueued
@@ -45,11 +172,18 @@ func (s *Service) pushToJobChannel(newJob CreateJobParams) {
 		_job.JobPayload.IngestFileName = newJob.DocumentName
 		_job.JobPayload.IngestURL = newJob.DocumentSource
 
+	} else if newJob.IsErasure {
+		_job.CurrentStep = jobModel.ErasureInit
+		_job.JobType = jobModel.JobTypeErasure
+		_job.JobPayload.EraseChatId = newJob.EraseChatID
+		_job.JobPayload.EraseIdentity = newJob.EraseIdentity
+
 	} else {
 		_job.JobType = jobModel.JobTypeQuery
 		_job.Status = jobModel.JobStatusQueued
 		_job.ChatId = newJob.ChatID
 		_job.JobPayload.Question = newJob.Message
+		_job.JobPayload.Retrieval = newJob.Retrieval
 		_job.CurrentStep = jobModel.UserQueryInit
 
 		if newJob.IsMCPCall {
@@ -58,7 +192,28 @@ func (s *Service) pushToJobChannel(newJob CreateJobParams) {
 			_job.JobType = jobModel.JobTypeQuery
 		}
 	}
+	return _job
+}
+
+// saveQueued saves the job before queueing it, so it can be polled while it waits for a worker
+func (s *Service) saveQueued(_job jobModel.Job) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, _job.TraceId)
+	if err := s.JobStore.SaveJob(ctx, _job); err != nil {
+		logJH.Error("Error saving queued job", "job id", _job.Id, "error", err)
+	}
+}
+
+// queue hands a saved job to the worker processes when they run apart, to this process' workers otherwise
+func (s *Service) queue(_job jobModel.Job) {
+	if s.JobQueue != nil {
+		s.enqueue(_job)
+		return
+	}
+	s.Dispatch(_job)
+}
 
+// Dispatch hands the job to the worker pool of this process
+func (s *Service) Dispatch(_job jobModel.Job) {
 	//metrics
 	metrics.IncrementJobsInQueue()
 
@@ -80,9 +235,29 @@ func (s *Service) pushToJobChannel(newJob CreateJobParams) {
 	}
 }
 
-func (s *Service) initNewChat(chatId string, traceId string) {
+func (s *Service) enqueue(_job jobModel.Job) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, _job.TraceId)
+	if err := s.JobQueue.Enqueue(ctx, _job); err != nil {
+		logJH.Error("Error queueing job", "job id", _job.Id, "error", err)
+		_job.Status = jobModel.JobStatusError
+		_job.CurrentStep = jobModel.Error
+		_job.EndTime = time.Now()
+		_job.Error = jobModel.JobError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "Job queue unavailable",
+			Retry:   true,
+		}
+		_ = s.JobStore.SaveJob(ctx, _job)
+		s.SyncBatch(ctx, _job)
+		webhook.Notify(s.JobStore, _job)
+		return
+	}
+	logJH.Info("Queued new job")
+}
+
+func (s *Service) initNewChat(chatId string, identity string, traceId string) {
 	ctxC := context.WithValue(context.Background(), config.TRACE_ID_KEY, traceId)
-	err := s.MessageStore.InitNewChat(ctxC, chatId)
+	err := s.MessageStore.InitNewChat(ctxC, chatId, identity)
 	if err != nil {
 		logJH.Error("Error initiating new chat", chatId, err)
 		return
@@ -1,6 +1,8 @@
 package job
 
 import (
+	"time"
+
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
 )
 
@@ -10,6 +12,12 @@ type Service struct {
 	DispatcherChannel chan bool
 	JobStore          jobModel.JobStore
 	MessageStore      jobModel.MessageStore
+	ScheduleStore     jobModel.ScheduleStore
+	EventStore        jobModel.EventStore
+	//JobQueue is only set when the workers run in their own processes
+	JobQueue jobModel.JobQueue
+	//AnswerCache negative feedback evicts answers from it, nil without a vector DB
+	AnswerCache jobModel.AnswerCache
 }
 
 type ServiceConfig struct {
@@ -18,6 +26,10 @@ type ServiceConfig struct {
 	DispatcherChannel chan bool
 	JobStore          jobModel.JobStore
 	MessageStore      jobModel.MessageStore
+	ScheduleStore     jobModel.ScheduleStore
+	EventStore        jobModel.EventStore
+	JobQueue          jobModel.JobQueue
+	AnswerCache       jobModel.AnswerCache
 }
 
 func InitJobService(cfg ServiceConfig) *Service {
@@ -27,6 +39,10 @@ func InitJobService(cfg ServiceConfig) *Service {
 		DispatcherChannel: cfg.DispatcherChannel,
 		JobStore:          cfg.JobStore,
 		MessageStore:      cfg.MessageStore,
+		ScheduleStore:     cfg.ScheduleStore,
+		EventStore:        cfg.EventStore,
+		JobQueue:          cfg.JobQueue,
+		AnswerCache:       cfg.AnswerCache,
 	}
 }
 
@@ -40,4 +56,24 @@ type CreateJobParams struct {
 	DocumentName     string
 	DocumentSource   string
 	IsMCPCall        bool
+	ParentID         string
+	CallbackURL      string
+	Identity         string
+	Deadline         time.Time
+	Retrieval        *jobModel.RetrievalOptions
+	//erasure, set one of EraseChatID or EraseIdentity
+	IsErasure     bool
+	EraseChatID   string
+	EraseIdentity string
+}
+
+type CreateBatchParams struct {
+	ID       string
+	Messages []string
+	//ChatID is shared by every question, empty with SharedChat starts one new chat for all of them
+	ChatID     string
+	SharedChat bool
+	TraceID    string
+	Identity   string
+	Deadline   time.Time
 }
@@ -0,0 +1,64 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+var defaultTimeouts = map[jobModel.JobType]time.Duration{
+	jobModel.JobTypeQuery:   config.QueryJobTimeout,
+	jobModel.JobTypeMCP:     config.MCPJobTimeout,
+	jobModel.JobTypeIngest:  config.IngestJobTimeout,
+	jobModel.JobTypeErasure: config.ErasureJobTimeout,
+}
+
+// Timeout how long a job of this type may run, JOB_TIMEOUT_<TYPE> overrides the default in config
+func Timeout(jobType jobModel.JobType) time.Duration {
+	timeout, found := defaultTimeouts[jobType]
+	if !found {
+		timeout = config.QueryJobTimeout
+	}
+	if value := os.Getenv("JOB_TIMEOUT_" + strings.ToUpper(string(jobType))); value != "" {
+		if override, err := time.ParseDuration(value); err == nil && override > 0 {
+			timeout = override
+		} else {
+			logJH.Warn("Ignoring invalid job timeout", "job type", jobType, "value", value)
+		}
+	}
+	return timeout
+}
+
+// WithJobDeadline bounds ctx by the timeout of the job type, or by the client's deadline when that comes first.
+// A deadline that passed while the job was queued gives an already expired context.
+func WithJobDeadline(ctx context.Context, job jobModel.Job) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(Timeout(job.JobType))
+	if !job.Deadline.IsZero() && job.Deadline.Before(deadline) {
+		deadline = job.Deadline
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// TimedOut fails the job with ErrorTypeTimeout, whatever error it failed with on the way
+func TimedOut(job jobModel.Job) jobModel.Job {
+	message := fmt.Sprintf("Job did not finish within %s", Timeout(job.JobType))
+	if !job.Deadline.IsZero() && !job.Deadline.After(time.Now()) {
+		message = "Job did not finish before its deadline"
+	}
+	job.EndStep(jobModel.StepOutcomeFailed)
+	job.CurrentStep = jobModel.Error
+	job.Status = jobModel.JobStatusError
+	job.Error = jobModel.JobError{
+		Code:    http.StatusGatewayTimeout,
+		Type:    jobModel.ErrorTypeTimeout,
+		Message: message,
+		Retry:   true,
+	}
+	return job
+}
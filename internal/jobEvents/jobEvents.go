@@ -0,0 +1,47 @@
+package jobEvents
+
+import (
+	"sync"
+)
+
+// waiters job id -> channels of the requests currently long polling that job
+var (
+	mu      sync.Mutex
+	waiters = make(map[string]map[chan struct{}]struct{})
+)
+
+// Subscribe returns a channel that receives a signal every time the job is saved.
+// Subscribe before reading the job, so a save between the read and the wait isn't missed.
+// The returned func must be called to release the subscription.
+func Subscribe(jobId string) (<-chan struct{}, func()) {
+	updates := make(chan struct{}, 1)
+
+	mu.Lock()
+	if waiters[jobId] == nil {
+		waiters[jobId] = make(map[chan struct{}]struct{})
+	}
+	waiters[jobId][updates] = struct{}{}
+	mu.Unlock()
+
+	return updates, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(waiters[jobId], updates)
+		if len(waiters[jobId]) == 0 {
+			delete(waiters, jobId)
+		}
+	}
+}
+
+// Notify wakes every subscriber of the job on this replica. It never blocks,
+// a subscriber that hasn't consumed the previous signal keeps just the one.
+func Notify(jobId string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for updates := range waiters[jobId] {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+}
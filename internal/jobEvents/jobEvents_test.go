@@ -0,0 +1,38 @@
+package jobEvents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotify_WakesOnlyThatJob(t *testing.T) {
+	updates, cancel := Subscribe("job-a")
+	defer cancel()
+	other, cancelOther := Subscribe("job-b")
+	defer cancelOther()
+
+	Notify("job-a")
+	Notify("job-a") //must not block on a full channel
+
+	select {
+	case <-updates:
+	case <-time.After(time.Second):
+		t.Fatal("Subscriber was not notified")
+	}
+	select {
+	case <-other:
+		t.Error("Subscriber of another job was notified")
+	default:
+	}
+}
+
+func TestSubscribe_CancelReleases(t *testing.T) {
+	_, cancel := Subscribe("job-c")
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, found := waiters["job-c"]; found {
+		t.Error("Cancelled subscription is still registered")
+	}
+}
@@ -53,35 +53,38 @@ func newClaudeClient(ctx context.Context, modelName string, apikey string) {
 	go closeClient(ctx, claudeClient)
 }
 
-func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []string, messageHistory []string) (string, error) {
-	if c.client == nil {
-		return "", fmt.Errorf("claude client is nil")
-	}
-
-	//add logging later
-
+func (c *llmClient) generateParams(userQuery string, matches []string, messageHistory []llm.Message) anthropic.MessageNewParams {
 	var contextBuilder strings.Builder
 	contextBuilder.WriteString("This is the context:\n")
 	contextBuilder.WriteString(strings.Join(matches, "\n"))
 
-	if len(messageHistory) > 0 {
-		contextBuilder.WriteString("\n\nThis is Message History:\n")
-		contextBuilder.WriteString("Question stands for the user question and the answer stands for the answer you gave, sources are the source for answer.\n")
-		contextBuilder.WriteString(strings.Join(messageHistory, "\n"))
-	}
-
 	userPrompt := fmt.Sprintf("Context:\n%s\n\nUser Question: %s", contextBuilder.String(), userQuery)
 
-	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
+	//earlier turns go first as real messages, the context only comes with the new question
+	messages := make([]anthropic.MessageParam, 0, len(messageHistory)+1)
+	for _, msg := range messageHistory {
+		messages = append(messages, toAnthropicMessage(msg))
+	}
+	messages = append(messages, anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)))
+
+	return anthropic.MessageNewParams{
 		Model:     anthropic.Model(c.modelName),
 		MaxTokens: 1024,
 		System: []anthropic.TextBlockParam{
 			{Text: config.ModelContext},
 		},
-		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
-		},
-	})
+		Messages: messages,
+	}
+}
+
+func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []string, messageHistory []llm.Message) (string, error) {
+	if c.client == nil {
+		return "", fmt.Errorf("claude client is nil")
+	}
+
+	//add logging later
+
+	message, err := c.client.Messages.New(ctx, c.generateParams(userQuery, matches, messageHistory))
 	if err != nil {
 		logger.Error("Error generating content from Claude:", "error", err)
 		return "", err
@@ -94,6 +97,32 @@ func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []st
 	return "", fmt.Errorf("no content returned from Claude")
 }
 
+func (c *llmClient) GenerateStream(ctx context.Context, userQuery string, matches []string, messageHistory []llm.Message, onDelta func(delta string)) (string, error) {
+	if c.client == nil {
+		return "", fmt.Errorf("claude client is nil")
+	}
+
+	stream := c.client.Messages.NewStreaming(ctx, c.generateParams(userQuery, matches, messageHistory))
+	defer stream.Close()
+
+	var answer strings.Builder
+	for stream.Next() {
+		event := stream.Current()
+		if event.Type == "content_block_delta" && event.Delta.Type == "text_delta" {
+			answer.WriteString(event.Delta.Text)
+			onDelta(event.Delta.Text)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		logger.Error("Error streaming content from Claude:", "error", err)
+		return "", err
+	}
+	if answer.Len() == 0 {
+		return "", fmt.Errorf("no content returned from Claude")
+	}
+	return answer.String(), nil
+}
+
 func (c *llmClient) ChatWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
 	if c.client == nil {
 		return nil, fmt.Errorf("claude client is nil")
@@ -49,36 +49,35 @@ func newGeminiClient(ctx context.Context, apikey string, modelName string) {
 
 }
 
-func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []string, messageHistory []string) (string, error) {
-	if strings.TrimSpace(userQuery) == "" {
-		return "", fmt.Errorf("empty query")
-	}
-	logger.With("traceId", ctx.Value("traceId"))
+func generateRequest(userQuery string, matches []string, messageHistory []llm.Message) ([]*genai.Content, *genai.GenerateContentConfig) {
 	systemInstruction := &genai.Content{
 		Parts: []*genai.Part{
 			{Text: config.ModelContext},
 		},
 	}
 
-	contextText := "This is the context :"
-
-	if messageHistory != nil && len(messageHistory) > 0 {
-		contextText = contextText + "\n This is Message History :" +
-			" Question stands for the user question and the answer stands for the answer you gave, sources are the source for answer \n"
-		contextText = contextText + strings.Join(messageHistory, "\n")
-
-	}
-	contextText = strings.Join(matches, "\n")
+	contextText := strings.Join(matches, "\n")
 	userPrompt := fmt.Sprintf("Context:\n%s\n\nUser Question: %s", contextText, userQuery)
 
 	contentConfig := &genai.GenerateContentConfig{
 		SystemInstruction: systemInstruction,
 	}
+	//earlier turns go first as real messages, the context only comes with the new question
+	contents := append(toGeminiContents(messageHistory), genai.Text(userPrompt)...)
+	return contents, contentConfig
+}
+
+func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []string, messageHistory []llm.Message) (string, error) {
+	if strings.TrimSpace(userQuery) == "" {
+		return "", fmt.Errorf("empty query")
+	}
+	logger.With("traceId", ctx.Value("traceId"))
 
+	contents, contentConfig := generateRequest(userQuery, matches, messageHistory)
 	result, err := c.client.Models.GenerateContent(
 		ctx,
 		c.modelName,
-		genai.Text(userPrompt),
+		contents,
 		contentConfig,
 	)
 	if err != nil {
@@ -88,6 +87,29 @@ func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []st
 	return result.Text(), nil
 }
 
+func (c *llmClient) GenerateStream(ctx context.Context, userQuery string, matches []string, messageHistory []llm.Message, onDelta func(delta string)) (string, error) {
+	if strings.TrimSpace(userQuery) == "" {
+		return "", fmt.Errorf("empty query")
+	}
+	if c.client == nil {
+		return "", fmt.Errorf("gemini client is nil")
+	}
+
+	contents, contentConfig := generateRequest(userQuery, matches, messageHistory)
+	var answer strings.Builder
+	for result, err := range c.client.Models.GenerateContentStream(ctx, c.modelName, contents, contentConfig) {
+		if err != nil {
+			logger.Error("Error streaming content:", "error", err)
+			return "", err
+		}
+		if delta := result.Text(); delta != "" {
+			answer.WriteString(delta)
+			onDelta(delta)
+		}
+	}
+	return answer.String(), nil
+}
+
 func (c *llmClient) ChatWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
 	if c.client == nil {
 		return nil, fmt.Errorf("gemini client is nil")
@@ -0,0 +1,88 @@
+package llm
+
+import "encoding/json"
+
+// HistoryWindow how much of a chat goes along with the next question, a zero limit is no limit
+type HistoryWindow struct {
+	MaxTurns  int
+	MaxTokens int
+}
+
+// TextMessage a message with a single text block
+func TextMessage(role Role, text string) Message {
+	return Message{Role: role, Content: []ContentBlock{{Type: ContentBlockTypeText, Text: text}}}
+}
+
+// Text the text blocks of the message joined together
+func (m Message) Text() string {
+	var text string
+	for _, block := range m.Content {
+		if block.Type == ContentBlockTypeText {
+			text += block.Text
+		}
+	}
+	return text
+}
+
+// EstimateTokens is a rough count, about four characters per token for English text.
+// It only has to keep the history well clear of the context limit, not match the provider's tokenizer.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// Apply keeps the newest turns that fit the window. history is user/assistant pairs, oldest first,
+// and a turn is only ever kept or dropped whole.
+func (w HistoryWindow) Apply(history []Message) []Message {
+	turns := len(history) / 2
+	kept, tokens := 0, 0
+	for i := turns - 1; i >= 0; i-- {
+		if w.MaxTurns > 0 && kept == w.MaxTurns {
+			break
+		}
+		turnTokens := EstimateTokens(history[2*i].Text()) + EstimateTokens(history[2*i+1].Text())
+		if w.MaxTokens > 0 && tokens+turnTokens > w.MaxTokens {
+			break
+		}
+		tokens += turnTokens
+		kept++
+	}
+	return history[2*(turns-kept) : 2*turns]
+}
+
+// EstimateTokens every block of the message, tool calls and tool results included
+func (m Message) EstimateTokens() int {
+	tokens := 0
+	for _, block := range m.Content {
+		tokens += EstimateTokens(block.Text) + EstimateTokens(block.ToolResult)
+		if len(block.ToolArgs) > 0 {
+			args, _ := json.Marshal(block.ToolArgs)
+			tokens += EstimateTokens(string(args))
+		}
+	}
+	return tokens
+}
+
+// ApplyTurns is Apply for turns of any length, like the question, tool calls, tool results and answer
+// of an MCP turn. It keeps the newest turns that fit the window, oldest first and flattened
+func (w HistoryWindow) ApplyTurns(turns [][]Message) []Message {
+	kept, tokens := 0, 0
+	for i := len(turns) - 1; i >= 0; i-- {
+		if w.MaxTurns > 0 && kept == w.MaxTurns {
+			break
+		}
+		turnTokens := 0
+		for _, message := range turns[i] {
+			turnTokens += message.EstimateTokens()
+		}
+		if w.MaxTokens > 0 && tokens+turnTokens > w.MaxTokens {
+			break
+		}
+		tokens += turnTokens
+		kept++
+	}
+	history := make([]Message, 0)
+	for _, turn := range turns[len(turns)-kept:] {
+		history = append(history, turn...)
+	}
+	return history
+}
@@ -56,35 +56,33 @@ func newOpenAIClient(ctx context.Context, modelName string, apikey string) {
 
 }
 
-func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []string, messageHistory []string) (string, error) {
-	if c.client == nil {
-		return "", fmt.Errorf("openai client is nil")
-	}
-
-	log := logger.With("traceId", ctx.Value("traceId"))
-	_ = log
-
+func (c *llmClient) generateParams(userQuery string, matches []string, messageHistory []llm.Message) openai.ChatCompletionNewParams {
 	var contextBuilder strings.Builder
 	contextBuilder.WriteString("This is the context:\n")
 	contextBuilder.WriteString(strings.Join(matches, "\n"))
 
-	if len(messageHistory) > 0 {
-		contextBuilder.WriteString("\n\nThis is Message History:\n")
-		contextBuilder.WriteString("Question stands for the user question and the answer stands for the answer you gave, sources are the source for answer.\n")
-		contextBuilder.WriteString(strings.Join(messageHistory, "\n"))
-	}
-
 	userPrompt := fmt.Sprintf("Context:\n%s\n\nUser Question: %s", contextBuilder.String(), userQuery)
 
-	params := openai.ChatCompletionNewParams{
-		Model: c.modelName,
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(config.ModelContext),
-			openai.UserMessage(userPrompt),
-		},
+	//earlier turns go first as real messages, the context only comes with the new question
+	messages := []openai.ChatCompletionMessageParamUnion{openai.SystemMessage(config.ModelContext)}
+	messages = append(messages, toOpenAIMessages(messageHistory)...)
+	messages = append(messages, openai.UserMessage(userPrompt))
+
+	return openai.ChatCompletionNewParams{
+		Model:    c.modelName,
+		Messages: messages,
+	}
+}
+
+func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []string, messageHistory []llm.Message) (string, error) {
+	if c.client == nil {
+		return "", fmt.Errorf("openai client is nil")
 	}
 
-	completion, err := c.client.Chat.Completions.New(ctx, params)
+	log := logger.With("traceId", ctx.Value("traceId"))
+	_ = log
+
+	completion, err := c.client.Chat.Completions.New(ctx, c.generateParams(userQuery, matches, messageHistory))
 	if err != nil {
 		logger.Error("Error generating content from OpenAI:", "error", err)
 		return "", err
@@ -97,6 +95,32 @@ func (c *llmClient) Generate(ctx context.Context, userQuery string, matches []st
 	return "", fmt.Errorf("no content returned from OpenAI")
 }
 
+func (c *llmClient) GenerateStream(ctx context.Context, userQuery string, matches []string, messageHistory []llm.Message, onDelta func(delta string)) (string, error) {
+	if c.client == nil {
+		return "", fmt.Errorf("openai client is nil")
+	}
+
+	stream := c.client.Chat.Completions.NewStreaming(ctx, c.generateParams(userQuery, matches, messageHistory))
+	defer stream.Close()
+
+	var answer strings.Builder
+	for stream.Next() {
+		chunk := stream.Current()
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			answer.WriteString(chunk.Choices[0].Delta.Content)
+			onDelta(chunk.Choices[0].Delta.Content)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		logger.Error("Error streaming content from OpenAI:", "error", err)
+		return "", err
+	}
+	if answer.Len() == 0 {
+		return "", fmt.Errorf("no content returned from OpenAI")
+	}
+	return answer.String(), nil
+}
+
 func (c *llmClient) ChatWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
 	if c.client == nil {
 		return nil, fmt.Errorf("openai client is nil")
@@ -20,26 +20,28 @@ const (
 	ContentBlockTypeToolResult ContentBlockType = "tool_result"
 )
 
+// ContentBlock the json tags are how MCP chats keep their tool calls in the message store
 type ContentBlock struct {
-	Type ContentBlockType
+	Type ContentBlockType `json:"type"`
 
-	Text string
+	Text string `json:"text,omitempty"`
 
 	//tool use
-	ToolCallID string
-	ToolName   string
-	ToolArgs   map[string]any
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+	ToolName   string         `json:"tool_name,omitempty"`
+	ToolArgs   map[string]any `json:"tool_args,omitempty"`
 
 	//tool result
-	ToolResult string
+	ToolResult string `json:"tool_result,omitempty"`
 
 	//raw fields because gemini needs thought signature bruh
-	RawField any
+	//not saved, a replayed block is built again from the fields above
+	RawField any `json:"-"`
 }
 
 type Message struct {
-	Role    Role
-	Content []ContentBlock
+	Role    Role           `json:"role"`
+	Content []ContentBlock `json:"content"`
 }
 
 type Tool struct {
@@ -61,6 +63,13 @@ type Response struct {
 }
 
 type Provider interface {
-	Generate(ctx context.Context, query string, matches []string, messageHistory []string) (string, error)
+	// Generate answers the query from the matches, messageHistory goes before it as earlier turns of the chat
+	Generate(ctx context.Context, query string, matches []string, messageHistory []Message) (string, error)
 	ChatWithTools(ctx context.Context, messages []Message, tools []Tool) (*Response, error)
 }
+
+// StreamingProvider is optional, providers that implement it can hand out the answer as it is generated.
+// onDelta is called with every text chunk in order, the full answer is returned at the end.
+type StreamingProvider interface {
+	GenerateStream(ctx context.Context, query string, matches []string, messageHistory []Message, onDelta func(delta string)) (string, error)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -90,7 +91,10 @@ func CallMCPTool(ctx context.Context, name string, args map[string]any) (string,
 		return "", fmt.Errorf("MCP client not initialised")
 	}
 
+	//ctx doesn't reach the server, so the identity the tool acts for goes along in _meta
+	identity, _ := ctx.Value(config.IDENTITY_KEY).(string)
 	result, err := mcpSession.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{config.MCPIdentityMetaKey: identity},
 		Name:      name,
 		Arguments: args,
 	})
@@ -2,13 +2,18 @@ package mcpImpl
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/debug"
 	"sync"
 	"time"
 
+	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
 	"github.com/akolanti/GoAPI/internal/job"
 	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/internal/metrics"
+	"github.com/akolanti/GoAPI/internal/webhook"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -18,6 +23,7 @@ const maxToolUseIterations = 10
 var logHandler *logger_i.Logger
 var llmProvider llm.Provider
 var jobStore jobModel.JobStore
+var messageStore jobModel.MessageStore
 var service *job.Service
 var syncOnceHandler sync.Once
 
@@ -25,6 +31,7 @@ func InitMCPHandler(ctx context.Context, provider llm.Provider, svc *job.Service
 	logHandler = logger_i.NewLogger("mcp_handler")
 	llmProvider = provider
 	jobStore = svc.JobStore
+	messageStore = svc.MessageStore
 	service = svc
 	syncOnceHandler.Do(func() {
 		logHandler.Info("Initializing MCP transports and server/client")
@@ -32,29 +39,85 @@ func InitMCPHandler(ctx context.Context, provider llm.Provider, svc *job.Service
 	})
 }
 
-func HandleRequest(ctx context.Context, question string, jobId string, traceId string) {
+// Request an MCP question. Every request is a turn of ChatId, IsNewChat starts the chat for Identity first
+type Request struct {
+	JobId       string
+	TraceId     string
+	ChatId      string
+	IsNewChat   bool
+	Question    string
+	CallbackURL string
+	Identity    string
+	Deadline    time.Time
+}
+
+func HandleRequest(ctx context.Context, request Request) {
+	jobId, traceId, question := request.JobId, request.TraceId, request.Question
+	if request.IsNewChat {
+		if err := messageStore.InitNewChat(ctx, request.ChatId, request.Identity); err != nil {
+			logHandler.With("traceId", traceId).Error("Failed to start MCP chat", "chat Id", request.ChatId, "error", err)
+			return
+		}
+	}
+
 	//save initial job as running so the polling endpoint can find it
 	initialJob := jobModel.Job{
 		Id:          jobId,
+		ChatId:      request.ChatId,
 		TraceId:     traceId,
+		Identity:    request.Identity,
 		JobType:     jobModel.JobTypeMCP,
 		Status:      jobModel.JobStatusRunning,
-		CurrentStep: jobModel.UserQueryInit,
 		CreatedTime: time.Now(),
+		CallbackURL: request.CallbackURL,
+		Deadline:    request.Deadline,
 		JobPayload: jobModel.JobPayload{
 			Question: question,
 		},
 	}
+	initialJob.StartStep(jobModel.LLMCall)
 	if err := jobStore.SaveJob(ctx, initialJob); err != nil {
 		logHandler.With("traceId", traceId).Error("Failed to save initial MCP job", "error", err)
 		return
 	}
 
 	go func() {
-		answer, err := runToolLoop(context.Background(), question, jobId)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				metrics.CapturePanic("mcp")
+				logHandler.With("traceId", traceId).Error("MCP_PANIC", "job Id", jobId, "panic", recovered, "stack", string(debug.Stack()))
+				initialJob.EndStep(jobModel.StepOutcomeFailed)
+				initialJob.Status = jobModel.JobStatusError
+				initialJob.CurrentStep = jobModel.Error
+				initialJob.EndTime = time.Now()
+				initialJob.Error = jobModel.JobError{
+					Code:    500,
+					Message: "Internal Server Error",
+					Retry:   true,
+				}
+				_ = jobStore.SaveJob(context.Background(), initialJob)
+				webhook.Notify(jobStore, initialJob)
+			}
+		}()
+		loopCtx, cancel := job.WithJobDeadline(context.WithValue(context.Background(), config.IDENTITY_KEY, request.Identity), initialJob)
+		defer cancel()
+		history, err := messageStore.GetToolHistory(loopCtx, request.ChatId)
+		if err != nil {
+			logHandler.With("traceId", traceId).Error("Failed to get MCP chat history", "chat Id", request.ChatId, "error", err)
+		}
+		answer, toolMessages, err := runToolLoop(loopCtx, history, question, jobId)
 
+		if err != nil && errors.Is(loopCtx.Err(), context.DeadlineExceeded) {
+			logHandler.With("traceId", traceId).Warn("MCP tool loop timed out", "error", err)
+			initialJob = job.TimedOut(initialJob)
+			initialJob.EndTime = time.Now()
+			_ = jobStore.SaveJob(context.Background(), initialJob)
+			webhook.Notify(jobStore, initialJob)
+			return
+		}
 		if err != nil {
 			logHandler.With("traceId", traceId).Error("MCP tool loop error", "error", err)
+			initialJob.EndStep(jobModel.StepOutcomeFailed)
 			initialJob.Status = jobModel.JobStatusError
 			initialJob.CurrentStep = jobModel.Error
 			initialJob.EndTime = time.Now()
@@ -64,21 +127,31 @@ func HandleRequest(ctx context.Context, question string, jobId string, traceId s
 				Retry:   true,
 			}
 			_ = jobStore.SaveJob(context.Background(), initialJob)
+			webhook.Notify(jobStore, initialJob)
 			return
 		}
 
+		initialJob.EndStep(jobModel.StepOutcomeSuccess)
 		initialJob.Status = jobModel.JobStatusComplete
 		initialJob.CurrentStep = jobModel.Complete
 		initialJob.EndTime = time.Now()
 		initialJob.JobPayload.Answer = answer
+		//saved before the job completes, so a follow up sent once it has can see this turn
+		turn := initialJob.JobPayload
+		turn.ToolMessages = toolMessages
+		if err = messageStore.TrySaveChat(context.Background(), request.ChatId, turn); err != nil {
+			logHandler.With("traceId", traceId).Error("Failed to save MCP chat turn", "chat Id", request.ChatId, "error", err)
+		}
 		_ = jobStore.SaveJob(context.Background(), initialJob)
+		webhook.Notify(jobStore, initialJob)
 	}()
 }
 
-// message list only lives here, nothing persisted to redis
-func runToolLoop(ctx context.Context, question string, id string) (string, error) {
+// runToolLoop answers the question after the earlier turns in history. Besides the answer it returns the
+// tool calls and results it took to get there, the messages between the question and the answer
+func runToolLoop(ctx context.Context, history []llm.Message, question string, id string) (string, []llm.Message, error) {
 	if llmProvider == nil {
-		return "", fmt.Errorf("LLM provider not initialised")
+		return "", nil, fmt.Errorf("LLM provider not initialised")
 	}
 
 	logHandler.With("traceId", id).Info("Handling MCP request")
@@ -93,29 +166,28 @@ func runToolLoop(ctx context.Context, question string, id string) (string, error
 		})
 	}
 
-	messages := []llm.Message{
-		{
-			Role: llm.RoleUser,
-			Content: []llm.ContentBlock{
-				{Type: llm.ContentBlockTypeText, Text: question},
-			},
+	messages := append(history, llm.Message{
+		Role: llm.RoleUser,
+		Content: []llm.ContentBlock{
+			{Type: llm.ContentBlockTypeText, Text: question},
 		},
-	}
+	})
+	turnStart := len(messages)
 
 	//tool use loop
 	for i := 0; i < maxToolUseIterations; i++ {
 		resp, err := llmProvider.ChatWithTools(ctx, messages, tools)
 		if err != nil {
-			return "", fmt.Errorf("ChatWithTools iteration %d: %w", i, err)
+			return "", nil, fmt.Errorf("ChatWithTools iteration %d: %w", i, err)
 		}
 
 		if resp.StopReason == llm.StopReasonEndTurn {
 			for _, block := range resp.Content {
 				if block.Type == llm.ContentBlockTypeText {
-					return block.Text, nil
+					return block.Text, messages[turnStart:], nil
 				}
 			}
-			return "", fmt.Errorf("model returned end_turn but no text content")
+			return "", nil, fmt.Errorf("model returned end_turn but no text content")
 		}
 
 		//tool use - append assistant msg and execute tools
@@ -151,7 +223,7 @@ func runToolLoop(ctx context.Context, question string, id string) (string, error
 		})
 	}
 
-	return "", fmt.Errorf("exceeded maximum tool-use iterations (%d)", maxToolUseIterations)
+	return "", nil, fmt.Errorf("exceeded maximum tool-use iterations (%d)", maxToolUseIterations)
 }
 
 func startMCPClientAndServer(ctx context.Context) {
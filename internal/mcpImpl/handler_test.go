@@ -0,0 +1,51 @@
+package mcpImpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+// scriptedProvider calls a tool on the first call and answers on the second, it keeps what it was sent
+type scriptedProvider struct {
+	calls [][]llm.Message
+}
+
+func (p *scriptedProvider) Generate(ctx context.Context, query string, matches []string, messageHistory []llm.Message) (string, error) {
+	return "", nil
+}
+
+func (p *scriptedProvider) ChatWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
+	p.calls = append(p.calls, append([]llm.Message(nil), messages...))
+	if len(p.calls) == 1 {
+		return &llm.Response{StopReason: llm.StopReasonToolUse, Content: []llm.ContentBlock{
+			{Type: llm.ContentBlockTypeToolUse, ToolCallID: "call-1", ToolName: "get_system_message", ToolArgs: map[string]any{"code": "ABORTWF"}},
+		}}, nil
+	}
+	return &llm.Response{StopReason: llm.StopReasonEndTurn, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeText, Text: "the answer"}}}, nil
+}
+
+func TestRunToolLoop_ReplaysHistoryAndReturnsTheToolMessages(t *testing.T) {
+	logHandler = logger_i.NewLogger("TestRunToolLoop")
+	provider := &scriptedProvider{}
+	llmProvider = provider
+	defer func() { llmProvider = nil }()
+
+	history := []llm.Message{llm.TextMessage(llm.RoleUser, "earlier question"), llm.TextMessage(llm.RoleAssistant, "earlier answer")}
+	answer, toolMessages, err := runToolLoop(context.Background(), history, "follow up", "trace-1")
+	if err != nil || answer != "the answer" {
+		t.Fatalf("Expected the answer, got %q %v", answer, err)
+	}
+
+	first := provider.calls[0]
+	if len(first) != 3 || first[0].Text() != "earlier question" || first[2].Text() != "follow up" {
+		t.Errorf("Expected the history before the question, got %+v", first)
+	}
+	//the MCP client isn't running, the failed call still goes back to the model as a result
+	if len(toolMessages) != 2 || toolMessages[0].Content[0].ToolCallID != "call-1" ||
+		toolMessages[1].Content[0].Type != llm.ContentBlockTypeToolResult || toolMessages[1].Content[0].ToolResult == "" {
+		t.Errorf("Expected the tool call and its result, got %+v", toolMessages)
+	}
+}
@@ -2,8 +2,12 @@ package mcpImpl
 
 import (
 	"context"
+	"fmt"
+	"runtime/debug"
 
 	"github.com/akolanti/GoAPI/internal/adapter/utils"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/metrics"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -21,12 +25,12 @@ func InitMCPServer(ctx context.Context, transport *mcp.InMemoryTransport) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "search_knowledge_base",
 		Description: "This tool takes a user query and returns a response based on the RAG system. The response includes the answer to the query, the sources used to generate the answer, and the status of the query processing.",
-	}, search_knowledge_base)
+	}, recoverTool("search_knowledge_base", search_knowledge_base))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_system_message",
 		Description: "Looks up a system message by its code from the external system messages API. Returns the message code, language, and description. Example codes but do not use these codes directly, these are just examples (ATTACHDISP,ABORTWF). Use the input from the user query to look up the system message.",
-	}, get_system_message)
+	}, recoverTool("get_system_message", get_system_message))
 
 	if err := server.Run(ctx, transport); err != nil {
 		logMCP.With("error", err).Error("Failed to start MCP server")
@@ -34,12 +38,28 @@ func InitMCPServer(ctx context.Context, transport *mcp.InMemoryTransport) {
 	logMCP.Info("MCP server stopped")
 }
 
+// recoverTool turns a panic in a tool into a tool error, tools run on the server's goroutine
+// where a panic would take the whole process down
+func recoverTool[In, Out any](name string, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (result *mcp.CallToolResult, out Out, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				metrics.CapturePanic("mcp_tool")
+				logMCP.Error("MCP_TOOL_PANIC", "tool", name, "panic", recovered, "stack", string(debug.Stack()))
+				err = fmt.Errorf("tool %s failed", name)
+			}
+		}()
+		return handler(ctx, req, in)
+	}
+}
+
 // renamed this so model can understand easily. I might need to rename it even more. change the struct too.
 func search_knowledge_base(ctx context.Context, req *mcp.CallToolRequest, in ProcessQueryStruct) (*mcp.CallToolResult, QueryResult, error) {
 	id := utils.GetNewUUID()
 	logMCP.With("traceId", id).Info("Received RAG Query tool call")
 
-	res := ProcessQuery(ctx, in.Query, id)
+	identity, _ := req.Params.Meta[config.MCPIdentityMetaKey].(string)
+	res := ProcessQuery(ctx, in.Query, id, identity)
 	return nil, res, res.Err
 }
 
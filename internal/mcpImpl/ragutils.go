@@ -9,9 +9,11 @@ import (
 	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
 	"github.com/akolanti/GoAPI/internal/job"
+	"github.com/akolanti/GoAPI/internal/jobEvents"
 )
 
-func ProcessQuery(ctx context.Context, message string, id string) (res QueryResult) {
+// ProcessQuery answers message in a new chat of identity, the identity of the chat that called the tool
+func ProcessQuery(ctx context.Context, message string, id string, identity string) (res QueryResult) {
 	//create new job and inject into the job channel
 	mcpJob := trackJob{
 		QueryResult: QueryResult{
@@ -19,7 +21,8 @@ func ProcessQuery(ctx context.Context, message string, id string) (res QueryResu
 			Query:   message,
 			DoRetry: true,
 		},
-		JobId: utils.GetNewUUID(),
+		JobId:    utils.GetNewUUID(),
+		Identity: identity,
 	}
 
 	select {
@@ -41,37 +44,39 @@ func insertJobIntoJobChannel(args trackJob) (mcpJob trackJob) {
 		TraceID:   args.Id,
 		IsNewChat: true,
 		IsMCPCall: true,
+		Identity:  args.Identity,
 	})
 	return args
 }
 
+// pollForAnswers wakes up whenever the job is saved instead of polling on a fixed schedule,
+// the slow ticker only covers a notification lost with a dropped redis subscription
 func pollForAnswers(args trackJob) (mcpJob trackJob) {
-	pollTimes := []int{10, 20, 50, 100, 300, 700, 1000, 2000, 5000, 7000}
-
 	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, args.Id)
 	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(16000)*time.Millisecond)
 	defer cancel()
-	for _, pollTime := range pollTimes {
+
+	updates, unsubscribe := jobEvents.Subscribe(args.JobId)
+	defer unsubscribe()
+	recheck := time.NewTicker(2 * time.Second)
+	defer recheck.Stop()
+
+	for {
+		//a job that isn't found yet is waited for like one that hasn't finished, the store may not have it
+		//until a worker saves it
+		job, isFound := service.GetJobStatus(args.JobId, timeoutCtx)
+		if isFound && job.IsFinished() {
+			return extractDataFromJob(args, job, nil)
+		}
 
 		select {
 		case <-timeoutCtx.Done():
 			args.DoRetry = false
-			return extractDataFromJob(args, jobModel.Job{}, ctx.Err())
-		case <-time.After(time.Millisecond * time.Duration(pollTime)):
-			job, isFound := service.GetJobStatus(args.JobId, timeoutCtx)
-			if isFound {
-				if job.Status == jobModel.JobStatusComplete || job.Status == jobModel.JobStatusError {
-					return extractDataFromJob(args, job, nil)
-				}
-			} else {
-				//this is a case that shouldnt really happen since we created the job just now
-				return extractDataFromJob(args, jobModel.Job{}, errors.New("job is invalid"))
-			}
+			return extractDataFromJob(args, jobModel.Job{}, timeoutCtx.Err())
+		case <-updates:
+		case <-recheck.C:
 		}
-
 	}
-
-	return extractDataFromJob(args, jobModel.Job{}, errors.New("No job found"))
 }
 
 func extractDataFromJob(args trackJob, job jobModel.Job, err error) (res trackJob) {
@@ -84,7 +89,7 @@ func extractDataFromJob(args trackJob, job jobModel.Job, err error) (res trackJo
 	}
 	args.Status = string(job.Status)
 	args.Response = job.JobPayload.Answer
-	args.Sources = job.JobPayload.Sources
+	args.Sources = jobModel.FlattenSources(job.JobPayload.Sources)
 
 	switch job.Status {
 	case jobModel.JobStatusComplete:
@@ -113,6 +118,7 @@ type QueryResult struct {
 
 type trackJob struct {
 	QueryResult
-	TraceId string `json:"trace_id"`
-	JobId   string `json:"job_id"`
+	TraceId  string `json:"trace_id"`
+	JobId    string `json:"job_id"`
+	Identity string `json:"identity"`
 }
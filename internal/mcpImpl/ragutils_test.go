@@ -0,0 +1,62 @@
+package mcpImpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/job"
+)
+
+// workerSavedJobStore only keeps a job once a worker has picked it up, the way a job waiting in the
+// channel used to be invisible to polls
+type workerSavedJobStore struct {
+	*store.InMemoryJobStore
+}
+
+func (s workerSavedJobStore) SaveJob(ctx context.Context, saved jobModel.Job) error {
+	if saved.Status == jobModel.JobStatusQueued {
+		return nil
+	}
+	return s.InMemoryJobStore.SaveJob(ctx, saved)
+}
+
+func TestProcessQuery_WaitsForASlowWorker(t *testing.T) {
+	jobStore := workerSavedJobStore{store.InitInMemoryJobStore()}
+	service = job.InitJobService(job.ServiceConfig{
+		JobChannel:        make(chan jobModel.Job, 1),
+		DispatcherChannel: make(chan bool, 1),
+		JobStore:          jobStore,
+		MessageStore:      store.InitMessageStore(),
+	})
+	defer func() { service = nil }()
+
+	go func() {
+		queued := <-service.JobChannel
+		ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, queued.TraceId)
+		time.Sleep(300 * time.Millisecond)
+		queued.Status = jobModel.JobStatusRunning
+		_ = jobStore.SaveJob(ctx, queued)
+		time.Sleep(300 * time.Millisecond)
+		queued.Status = jobModel.JobStatusComplete
+		queued.JobPayload.Answer = "the pump runs at 4 bar"
+		_ = jobStore.SaveJob(ctx, queued)
+	}()
+
+	result := ProcessQuery(context.Background(), "pump pressure?", "trace-1", "alice")
+	if result.Err != nil || result.Status != string(jobModel.JobStatusComplete) || result.Response != "the pump runs at 4 bar" {
+		t.Errorf("Expected the worker's answer, got %+v", result)
+	}
+
+	ctx := context.Background()
+	page, _ := jobStore.ListJobs(ctx, jobModel.JobQuery{Identity: "alice"})
+	if len(page.Jobs) != 1 {
+		t.Fatalf("Expected the tool's job saved for the caller, got %+v", page.Jobs)
+	}
+	if chat, found, _ := service.MessageStore.GetChat(ctx, page.Jobs[0].ChatId); !found || chat.Identity != "alice" {
+		t.Errorf("Expected the tool's chat started for the caller, got %+v %v", chat, found)
+	}
+}
@@ -38,6 +38,11 @@ func (r *HttpStatusRecorder) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach Flush and the write deadline of the real writer
+func (r *HttpStatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func IncrementJobsInQueue() {
 	countJobsInQueue.Inc()
 }
@@ -77,3 +82,39 @@ func CaptureExecutionMetrics(label string, timeElapsed time.Duration) {
 func CaptureJobMetrics(label string, timeElapsed time.Duration) {
 	requestDuration.WithLabelValues(label).Observe(timeElapsed.Seconds())
 }
+
+var webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "webhook_deliveries_total",
+	Help: "Webhook delivery attempts labelled by outcome",
+}, []string{"outcome"})
+
+func CaptureWebhookDelivery(outcome string) {
+	webhookDeliveries.WithLabelValues(outcome).Inc()
+}
+
+var recoveredPanics = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "recovered_panics_total",
+	Help: "Panics recovered while running jobs, labelled by component",
+}, []string{"component"})
+
+func CapturePanic(component string) {
+	recoveredPanics.WithLabelValues(component).Inc()
+}
+
+var answerFeedback = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "answer_feedback_total",
+	Help: "Answer ratings labelled by rating and job type, a changed rating counts again",
+}, []string{"rating", "job_type"})
+
+func CaptureFeedback(rating string, jobType string) {
+	answerFeedback.WithLabelValues(rating, jobType).Inc()
+}
+
+var cacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "semantic_cache_evictions_total",
+	Help: "Cached answers evicted after a negative rating",
+})
+
+func CaptureCacheEvictions(count int) {
+	cacheEvictions.Add(float64(count))
+}
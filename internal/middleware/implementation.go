@@ -13,6 +13,8 @@ import (
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
+const maxIdentityLength = 256
+
 func injectTrace(re requestResponseStruct) requestResponseStruct {
 	re.logger.Debug("Injecting trace middleware")
 	req := re.req
@@ -50,6 +52,26 @@ func authenticate(re requestResponseStruct) requestResponseStruct {
 	return re
 }
 
+// resolveIdentity names the end user the request is made for. The token only proves the caller
+// is a trusted service, so the identity is whatever that service puts in config.IdentityHeader.
+func resolveIdentity(re requestResponseStruct) requestResponseStruct {
+	identity := strings.TrimSpace(re.req.Header.Get(config.IdentityHeader))
+	if identity == "" {
+		identity = config.DefaultIdentity
+	}
+	if len(identity) > maxIdentityLength {
+		re.badRequest = failureStruct{
+			isBadRequest: true,
+			httpCode:     http.StatusBadRequest,
+			errorMessage: config.IdentityHeader + " is too long",
+		}
+		return re
+	}
+	re.logger = re.logger.With("identity", identity)
+	re.req = re.req.WithContext(context.WithValue(re.req.Context(), config.IDENTITY_KEY, identity))
+	return re
+}
+
 func IsValidBearerToken(authHeader string, log *logger_i.Logger) bool {
 	if config.NoAuthBypass {
 		log.Error("--------------------------------------- auth bypass----------------------------------------------")
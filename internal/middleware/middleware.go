@@ -27,9 +27,26 @@ var GetHandler = Wrap(handlers.GetHandler)
 
 var ChatHandler = Wrap(handlers.ChatHandler)
 var GetStatusHandler = Wrap(handlers.GetStatusHandler)
+var StreamStatusHandler = Wrap(handlers.StreamStatusHandler)
 var PostIngestHandler = Wrap(handlers.PostIngestHandler)
 var MCPHandler = Wrap(handlers.MCPHandler)
 var MCPStatusHandler = Wrap(handlers.MCPStatusHandler)
+var BatchChatHandler = Wrap(handlers.BatchChatHandler)
+var GetBatchHandler = Wrap(handlers.GetBatchHandler)
+var ListJobsHandler = Wrap(handlers.ListJobsHandler)
+var FeedbackHandler = Wrap(handlers.FeedbackHandler)
+var ListChatsHandler = Wrap(handlers.ListChatsHandler)
+var GetChatHandler = Wrap(handlers.GetChatHandler)
+var ExportChatHandler = Wrap(handlers.ExportChatHandler)
+var RenameChatHandler = Wrap(handlers.RenameChatHandler)
+var DeleteChatHandler = Wrap(handlers.DeleteChatHandler)
+var DeleteIdentityHandler = Wrap(handlers.DeleteIdentityHandler)
+
+var CreateScheduleHandler = Wrap(handlers.CreateScheduleHandler)
+var ListSchedulesHandler = Wrap(handlers.ListSchedulesHandler)
+var PauseScheduleHandler = Wrap(handlers.PauseScheduleHandler)
+var ResumeScheduleHandler = Wrap(handlers.ResumeScheduleHandler)
+var DeleteScheduleHandler = Wrap(handlers.DeleteScheduleHandler)
 
 func Wrap(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -55,6 +72,10 @@ func processRequest(re requestResponseStruct) requestResponseStruct {
 		handleBadRequest(re)
 		return re //stop if auth fails
 	}
+	re = resolveIdentity(re)
+	if re.badRequest.isBadRequest {
+		return re
+	}
 	re = rateLimiter(re)
 	if re.badRequest.isBadRequest {
 		handleBadRequest(re)
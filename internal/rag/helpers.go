@@ -2,28 +2,40 @@ package rag
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
 	"github.com/akolanti/GoAPI/internal/metrics"
+	"github.com/akolanti/GoAPI/internal/rag/vectorDB"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
 func returnOutput(job jobModel.Job, ans string) jobModel.Job {
 	job.JobPayload.Answer = ans
+	job.EndStep(jobModel.StepOutcomeSuccess)
 	job.CurrentStep = jobModel.Complete
 	return job
 }
 
-func logOutput(job jobModel.Job, status jobModel.InternalStatus, log *logger_i.Logger) jobModel.Job {
-	job.CurrentStep = status
+func (s *service) logOutput(ctx context.Context, job *jobModel.Job, status jobModel.InternalStatus, log *logger_i.Logger) {
+	job.StartStep(status)
 	log.Debug("ProcessRequest", "Current Status", job.CurrentStep)
-	return job
+	s.reportProgress(ctx, *job)
+}
+
+func (s *service) reportProgress(ctx context.Context, job jobModel.Job) {
+	if s.progressReporter != nil {
+		s.progressReporter(ctx, job)
+	}
 }
 
 func (s *service) jobError(job jobModel.Job, err error, message string, canRetry bool) jobModel.Job {
 	s.logger.Error(message, "error", err)
+	job.EndStep(jobModel.StepOutcomeFailed)
 
 	job.Error = jobModel.JobError{
 		Code:    http.StatusInternalServerError,
@@ -33,17 +45,30 @@ func (s *service) jobError(job jobModel.Job, err error, message string, canRetry
 	job.Status = jobModel.JobStatusError
 	return job
 }
-func (s *service) executeEmbeddingStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job) ([]float32, error) {
-	*job = logOutput(*job, jobModel.EmbeddingAPICall, log)
+
+// executeEmbeddingStep one embedding per query, in the same order
+func (s *service) executeEmbeddingStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, queries []string) ([][]float32, error) {
+	s.logOutput(ctx, job, jobModel.EmbeddingAPICall, log)
 
 	start := time.Now()
 	defer func() { metrics.CaptureExecutionMetrics("embedding", time.Since(start)) }()
 
-	return s.embedder.GetEmbedding(ctx, job.JobPayload.Question)
+	if len(queries) == 1 {
+		emb, err := s.embedder.GetEmbedding(ctx, queries[0])
+		if err != nil {
+			return nil, err
+		}
+		return [][]float32{emb}, nil
+	}
+	embeddings, err := s.embedder.BatchEmbedding(ctx, queries, false)
+	if err == nil && len(embeddings) != len(queries) {
+		err = fmt.Errorf("got %d embeddings for %d queries", len(embeddings), len(queries))
+	}
+	return embeddings, err
 }
 
 func (s *service) executeCacheCheckStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, emb []float32) (string, bool) {
-	*job = logOutput(*job, jobModel.CacheCall, log)
+	s.logOutput(ctx, job, jobModel.CacheCall, log)
 
 	start := time.Now()
 	defer func() { metrics.CaptureExecutionMetrics("cache_lookup", time.Since(start)) }()
@@ -52,22 +77,94 @@ func (s *service) executeCacheCheckStep(ctx context.Context, log *logger_i.Logge
 	return ans, found
 }
 
-func (s *service) executeVectorSearchStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, emb []float32) ([]string, error) {
-	*job = logOutput(*job, jobModel.VectorDBCall, log)
+func (s *service) executeVectorSearchStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, queries []string, embeddings [][]float32) ([]string, error) {
+	s.logOutput(ctx, job, jobModel.VectorDBCall, log)
 
 	start := time.Now()
 	defer func() { metrics.CaptureExecutionMetrics("vector_search", time.Since(start)) }()
 
-	matches, metaData, err := s.vectorDB.Search(ctx, emb)
-	job.JobPayload.Sources = metaData
+	options := vectorDB.SearchOptions{Limit: topK(job.JobPayload.Retrieval)}
+	if retrieval := job.JobPayload.Retrieval; retrieval != nil {
+		options.MinScore = retrieval.MinScore
+		options.Collection = retrieval.Collection
+	}
+	if s.reranker != nil {
+		options.Limit = max(config.RerankCandidateLimit, options.Limit)
+	}
+	if len(queries) > 1 {
+		return s.searchSubQueries(ctx, log, job, queries, embeddings, options)
+	}
+	matches, metaData, err := s.vectorDB.Search(ctx, queries[0], embeddings[0], options)
+	job.JobPayload.Sources = jobModel.ParseSources(metaData)
 	return matches, err
 }
 
-func (s *service) executeLLMStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, matches []string, history []string) (string, error) {
-	*job = logOutput(*job, jobModel.LLMCall, log)
+// executeRerankStep query is the standalone question, it keeps the top_k best matches, the sources follow their match.
+// A failed rerank is not worth failing the job over, the search order is used instead
+func (s *service) executeRerankStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, query string, matches []string) []string {
+	if s.reranker == nil || len(matches) == 0 {
+		return matches
+	}
+	s.logOutput(ctx, job, jobModel.RerankCall, log)
+
+	start := time.Now()
+	defer func() { metrics.CaptureExecutionMetrics("rerank", time.Since(start)) }()
+
+	rerankCtx, cancel := context.WithTimeout(ctx, config.RerankTimeout)
+	defer cancel()
+	order, err := s.reranker.Rerank(rerankCtx, query, matches)
+	if err != nil {
+		log.Warn("Rerank failed, keeping the search order", "error", err)
+		job.EndStep(jobModel.StepOutcomeFailed)
+		order = make([]int, len(matches))
+		for i := range order {
+			order[i] = i
+		}
+	}
+
+	//every match comes with one source, anything else is left as the search returned it
+	sources := job.JobPayload.Sources
+	keepSources := len(sources) == len(matches)
+	if keepSources {
+		job.JobPayload.Sources = nil
+	}
+	keep := topK(job.JobPayload.Retrieval)
+	reranked := make([]string, 0, keep)
+	for _, i := range order {
+		if len(reranked) == keep {
+			break
+		}
+		if i < 0 || i >= len(matches) {
+			continue
+		}
+		reranked = append(reranked, matches[i])
+		if keepSources {
+			job.JobPayload.Sources = append(job.JobPayload.Sources, sources[i])
+		}
+	}
+	log.Debug("Reranked matches", "candidates", len(matches), "kept", len(reranked))
+	return reranked
+}
+
+// topK how many matches go to the LLM, the request may ask for other than config.SearchResultLimit
+func topK(retrieval *jobModel.RetrievalOptions) int {
+	if retrieval == nil || retrieval.TopK == 0 {
+		return config.SearchResultLimit
+	}
+	return retrieval.TopK
+}
+
+func (s *service) executeLLMStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, matches []string, history []llm.Message) (string, error) {
+	s.logOutput(ctx, job, jobModel.LLMCall, log)
 
 	start := time.Now()
 	defer func() { metrics.CaptureExecutionMetrics("llm_generation", time.Since(start)) }()
 
+	if streamer, ok := s.llmProvider.(llm.StreamingProvider); ok && s.deltaReporter != nil {
+		jobId := job.Id
+		return streamer.GenerateStream(ctx, job.JobPayload.Question, matches, history, func(delta string) {
+			s.deltaReporter(ctx, jobId, delta)
+		})
+	}
 	return s.llmProvider.Generate(ctx, job.JobPayload.Question, matches, history)
 }
@@ -128,9 +128,11 @@ func PrepareChunks(pages []rawPage, doc commonModels.Document, embeddingModel st
 	return allChunks
 }
 
-func BatchIngest(ctx context.Context, chunks []commonModels.DocChunk, vectorDB vectorDB.DataProcessor, embedder embedding.Embedder) error {
-	logger = logger_i.NewLogger("Batch Ingestion ")
-	logger.With("traceId", ctx.Value(config.TRACE_ID_KEY).(string))
+// ProgressFunc is called after every batch is upserted
+type ProgressFunc func(done, total int)
+
+func BatchIngest(ctx context.Context, chunks []commonModels.DocChunk, vectorDB vectorDB.DataProcessor, embedder embedding.Embedder, progress ProgressFunc) error {
+	logger := logger_i.NewLogger("Batch Ingestion ").With("traceId", ctx.Value(config.TRACE_ID_KEY))
 
 	batchSize := 100
 	isHugeDataSet := false
@@ -171,6 +173,9 @@ func BatchIngest(ctx context.Context, chunks []commonModels.DocChunk, vectorDB v
 		if err != nil {
 			return fmt.Errorf("upserting to qdrant failed: %w", err)
 		}
+		if progress != nil {
+			progress(end, len(chunks))
+		}
 	}
 
 	return nil
@@ -20,9 +20,12 @@ type rawPage struct {
 
 var logger *logger_i.Logger
 
-func ProcessDocumentIngestion(ctx context.Context, job jobModel.Job, e embedding.Embedder, vectorDatabase vectorDB.DataProcessor) jobModel.Job {
-	logger = logger_i.NewLogger("Document Ingestion ")
-	logger.With("traceId", ctx.Value(config.TRACE_ID_KEY).(string))
+// ProcessDocumentIngestion report is called with the job whenever a step starts or a batch is upserted, it can be nil
+func ProcessDocumentIngestion(ctx context.Context, job jobModel.Job, e embedding.Embedder, vectorDatabase vectorDB.DataProcessor, report func(jobModel.Job)) jobModel.Job {
+	logger = logger_i.NewLogger("Document Ingestion ").With("traceId", ctx.Value(config.TRACE_ID_KEY), "JobId", job.Id)
+	if report == nil {
+		report = func(jobModel.Job) {}
+	}
 
 	//ideally return batches of upserts
 	docName := job.JobPayload.IngestFileName
@@ -30,20 +33,20 @@ func ProcessDocumentIngestion(ctx context.Context, job jobModel.Job, e embedding
 
 	logger.Debug("Processing document", "filename", docName, "path", docPath)
 
-	job.CurrentStep = jobModel.IngestProcessing
+	if job.CurrentStep != jobModel.IngestProcessing {
+		job.StartStep(jobModel.IngestProcessing)
+	}
 	err := vectorDatabase.CreateCollection(ctx, config.EmbeddingDBName)
 	if err != nil {
 		logger.Error("Error creating collection", "error", err)
-		job.Status = jobModel.JobStatusError
-		return job
+		return ingestError(job, "Error creating collection")
 	}
 
 	docType := getDocType(docPath)
 	logger.Debug("Processing document", "type", docType)
 	if docType == commonModels.ERR {
-		logger.Error("Error getting document type", "error", err)
-		job.Status = jobModel.JobStatusError
-		return job
+		logger.Error("Error getting document type", "path", docPath)
+		return ingestError(job, "Unsupported document type")
 	}
 
 	doc := commonModels.Document{
@@ -53,29 +56,43 @@ func ProcessDocumentIngestion(ctx context.Context, job jobModel.Job, e embedding
 		ContentType:         docType,
 	}
 
+	job.StartStep(jobModel.IngestExtraction)
+	report(job)
 	rawPages, err := extractText(job.JobPayload.IngestURL, doc.ContentType)
 	if err != nil {
 		logger.Error("Error processing document", "error", err)
-		job.Status = jobModel.JobStatusError
-		job.Error.Message = "Error extracting document content"
-		return job
+		return ingestError(job, "Error extracting document content")
 	}
 
 	logger.Debug("Processing document", "Number of raw pages: ", len(rawPages))
 	chunks := PrepareChunks(rawPages, doc, "temp model")
 
 	logger.Debug("Processing document", "Number of chunks: ", len(chunks))
-	err = BatchIngest(ctx, chunks, vectorDatabase, e)
+	job.StartStep(jobModel.IngestUpsert)
+	report(job)
+	err = BatchIngest(ctx, chunks, vectorDatabase, e, func(done, total int) {
+		job.Progress = done * 100 / total
+		report(job)
+	})
 
 	if err != nil {
-		job.Status = jobModel.JobStatusError
 		logger.Error("Error processing document", "error", err)
-		return job
+		return ingestError(job, "Error ingesting document chunks")
 	}
 	err = os.Remove(job.JobPayload.IngestURL)
 	if err != nil {
 		logger.Error("Error removing file", "error", err)
 	}
+	job.EndStep(jobModel.StepOutcomeSuccess)
+	job.CurrentStep = jobModel.Complete
+	job.Progress = 100
 	job.Status = jobModel.JobStatusComplete
 	return job
 }
+
+func ingestError(job jobModel.Job, message string) jobModel.Job {
+	job.EndStep(jobModel.StepOutcomeFailed)
+	job.Status = jobModel.JobStatusError
+	job.Error.Message = message
+	return job
+}
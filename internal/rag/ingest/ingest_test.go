@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/akolanti/GoAPI/internal/domain/commonModels"
+	"github.com/akolanti/GoAPI/internal/rag/vectorDB"
 )
 
 // --- Mocks for BatchIngest ---
@@ -26,15 +27,18 @@ type mockVectorDB struct {
 	upsertFunc func(ctx context.Context, coll string, chunks []commonModels.DocChunk, vectors [][]float32) error
 }
 
-func (m *mockVectorDB) Search(ctx context.Context, v []float32) ([]string, []string, error) {
+func (m *mockVectorDB) Search(ctx context.Context, query string, v []float32, options vectorDB.SearchOptions) ([]string, []string, error) {
 	return nil, nil, nil
 }
 func (m *mockVectorDB) GetCachedAnswer(ctx context.Context, v []float32) (string, bool, error) {
 	return "", false, nil
 }
-func (m *mockVectorDB) SaveToCache(ctx context.Context, id string, v []float32, a string) error {
+func (m *mockVectorDB) SaveToCache(ctx context.Context, id string, v []float32, a string, o commonModels.CacheOwner) error {
 	return nil
 }
+func (m *mockVectorDB) DeleteCachedAnswers(ctx context.Context, chatIds []string, identity string) (int, error) {
+	return 0, nil
+}
 func (m *mockVectorDB) CreateCollection(ctx context.Context, name string) error { return nil }
 func (m *mockVectorDB) UpsertBatch(ctx context.Context, coll string, chunks []commonModels.DocChunk, vectors [][]float32) error {
 	return m.upsertFunc(ctx, coll, chunks, vectors)
@@ -101,7 +105,7 @@ func TestBatchIngest(t *testing.T) {
 		},
 	}
 
-	err := BatchIngest(ctx, chunks, vDB, emb)
+	err := BatchIngest(ctx, chunks, vDB, emb, nil)
 
 	if err != nil {
 		t.Fatalf("BatchIngest failed: %v", err)
@@ -112,6 +116,41 @@ func TestBatchIngest(t *testing.T) {
 	}
 }
 
+func TestBatchIngest_Progress(t *testing.T) {
+	chunks := make([]commonModels.DocChunk, 250)
+	for i := range chunks {
+		chunks[i] = commonModels.DocChunk{Chunk: "test content"}
+	}
+	vDB := &mockVectorDB{
+		upsertFunc: func(ctx context.Context, coll string, c []commonModels.DocChunk, v [][]float32) error {
+			return nil
+		},
+	}
+	emb := &mockEmbedder{
+		batchFunc: func(ctx context.Context, ch []string, huge bool) ([][]float32, error) {
+			return make([][]float32, len(ch)), nil
+		},
+	}
+
+	var reported []int
+	err := BatchIngest(context.Background(), chunks, vDB, emb, func(done, total int) {
+		reported = append(reported, done*100/total)
+	})
+	if err != nil {
+		t.Fatalf("BatchIngest failed: %v", err)
+	}
+
+	expected := []int{40, 80, 100}
+	if len(reported) != len(expected) {
+		t.Fatalf("Expected %d progress reports, got %v", len(expected), reported)
+	}
+	for i := range expected {
+		if reported[i] != expected[i] {
+			t.Errorf("Report %d got %d%%, want %d%%", i, reported[i], expected[i])
+		}
+	}
+}
+
 func TestBatchIngest_Error(t *testing.T) {
 	vDB := &mockVectorDB{
 		upsertFunc: func(ctx context.Context, coll string, c []commonModels.DocChunk, v [][]float32) error {
@@ -124,7 +163,7 @@ func TestBatchIngest_Error(t *testing.T) {
 		},
 	}
 
-	err := BatchIngest(context.Background(), []commonModels.DocChunk{{Chunk: "hi"}}, vDB, emb)
+	err := BatchIngest(context.Background(), []commonModels.DocChunk{{Chunk: "hi"}}, vDB, emb, nil)
 	if err == nil {
 		t.Error("Expected error from BatchIngest, got nil")
 	}
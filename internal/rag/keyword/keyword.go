@@ -0,0 +1,97 @@
+// Package keyword turns text into sparse BM25 term vectors for the keyword half of hybrid retrieval.
+// Documents carry the saturated term frequency of every term, queries a 1 per distinct term, and the
+// vector DB supplies the IDF at query time (Qdrant's IDF modifier), so nothing here needs corpus statistics
+package keyword
+
+import (
+	"hash/fnv"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/akolanti/GoAPI/internal/config"
+)
+
+// BM25 parameters, the usual defaults
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// SparseVector term ids, sorted, with their weights
+type SparseVector struct {
+	Indices []uint32
+	Values  []float32
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true, "do": true,
+	"for": true, "from": true, "how": true, "i": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "what": true, "when": true, "where": true,
+	"which": true, "who": true, "why": true, "with": true,
+}
+
+// Tokenize lower cased terms without stop words. A code written with hyphens, dots or underscores,
+// like X200-14 or E_042, is kept whole next to its parts so an exact match on the code scores highest
+func Tokenize(text string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.'
+	}) {
+		word = strings.Trim(word, "-_.")
+		parts := strings.FieldsFunc(word, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+		if len(parts) > 1 {
+			tokens = append(tokens, word)
+		}
+		for _, part := range parts {
+			if !stopWords[part] {
+				tokens = append(tokens, part)
+			}
+		}
+	}
+	return tokens
+}
+
+// EncodeDocument the BM25 term frequency part of every term, the length is normalised against
+// config.KeywordAvgChunkTerms as chunks are cut to about the same size
+func EncodeDocument(text string) SparseVector {
+	tokens := Tokenize(text)
+	counts := termCounts(tokens)
+	lengthNorm := 1 - b + b*float64(len(tokens))/config.KeywordAvgChunkTerms
+	return toVector(counts, func(count int) float32 {
+		tf := float64(count)
+		return float32(tf * (k1 + 1) / (tf + k1*lengthNorm))
+	})
+}
+
+// EncodeQuery every distinct term once, repeating a word in the question doesn't weigh it more
+func EncodeQuery(text string) SparseVector {
+	return toVector(termCounts(Tokenize(text)), func(int) float32 { return 1 })
+}
+
+func termCounts(tokens []string) map[uint32]int {
+	counts := make(map[uint32]int, len(tokens))
+	for _, token := range tokens {
+		counts[termId(token)]++
+	}
+	return counts
+}
+
+// termId a 32 bit hash of the term, a collision only merges two rare terms' scores
+func termId(term string) uint32 {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(term))
+	return hash.Sum32()
+}
+
+func toVector(counts map[uint32]int, weight func(count int) float32) SparseVector {
+	vector := SparseVector{Indices: make([]uint32, 0, len(counts)), Values: make([]float32, 0, len(counts))}
+	for id := range counts {
+		vector.Indices = append(vector.Indices, id)
+	}
+	slices.Sort(vector.Indices)
+	for _, id := range vector.Indices {
+		vector.Values = append(vector.Values, weight(counts[id]))
+	}
+	return vector
+}
@@ -0,0 +1,32 @@
+package keyword
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTokenize_KeepsCodesWhole(t *testing.T) {
+	got := Tokenize("What does ATTACHDISP mean on the X200-14 pump?")
+	if fmt.Sprint(got) != "[does attachdisp mean x200-14 x200 14 pump]" {
+		t.Errorf("Unexpected tokens %v", got)
+	}
+}
+
+func TestEncodeDocument_SaturatesAndNormalisesLength(t *testing.T) {
+	once := EncodeDocument("attachdisp")
+	repeated := EncodeDocument("attachdisp attachdisp attachdisp")
+	if len(once.Indices) != 1 || len(repeated.Indices) != 1 || once.Indices[0] != repeated.Indices[0] {
+		t.Fatalf("Expected one term, got %+v %+v", once, repeated)
+	}
+	if repeated.Values[0] <= once.Values[0] || repeated.Values[0] >= 3*once.Values[0] {
+		t.Errorf("Expected a repeated term to weigh more but less than linearly, got %v and %v", once.Values[0], repeated.Values[0])
+	}
+
+	query := EncodeQuery("pump pump seal")
+	if len(query.Indices) != 2 || query.Values[0] != 1 || query.Values[1] != 1 {
+		t.Errorf("Expected every distinct query term once, got %+v", query)
+	}
+	if query.Indices[0] > query.Indices[1] {
+		t.Errorf("Expected the term ids sorted, got %v", query.Indices)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/akolanti/GoAPI/internal/adapter/utils"
 	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/commonModels"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
 	"github.com/akolanti/GoAPI/internal/llm"
 	"github.com/akolanti/GoAPI/internal/metrics"
@@ -46,64 +47,129 @@ ARCHITECTURE NOTE: OPAQUE INTERFACE PATTERN
 
 // Service Worker will only call this service - it doesn't need to know the llm or the vector
 type Service interface {
-	ProcessRequest(ctx context.Context, job jobModel.Job, messageHistory []string) jobModel.Job
+	// ProcessRequest answers the job's question, summary covers the chat before messageHistory and may be empty
+	ProcessRequest(ctx context.Context, job jobModel.Job, messageHistory []llm.Message, summary string) jobModel.Job
 	IngestDocument(ctx context.Context, job jobModel.Job) jobModel.Job
+	EraseCachedAnswers(ctx context.Context, chatIds []string, identity string) (int, error)
+	SummariseChat(ctx context.Context, previous string, turns []llm.Message) (string, error)
 }
 
 type service struct {
-	vectorDB    vectorDB.DataProcessor
-	llmProvider llm.Provider
-	embedder    embedding.Embedder
-	logger      *logger_i.Logger
+	vectorDB         vectorDB.DataProcessor
+	llmProvider      llm.Provider
+	embedder         embedding.Embedder
+	logger           *logger_i.Logger
+	progressReporter ProgressReporter
+	deltaReporter    DeltaReporter
+	reranker         Reranker
+	rewriteQueries   int //most queries a question is rewritten into, 0 leaves questions as they are
+}
+
+// ProgressReporter is called with the job every time it moves to a new step,
+// so intermediate steps can be persisted while the job is still running
+type ProgressReporter func(ctx context.Context, job jobModel.Job)
+
+// DeltaReporter is called with every chunk of the answer while the LLM is still generating it,
+// it is only used when the llm provider implements llm.StreamingProvider
+type DeltaReporter func(ctx context.Context, jobId string, delta string)
+
+// Reranker orders the chunks the vector search found by how well they answer the query. It returns
+// indexes into candidates, best first; the service keeps the first config.SearchResultLimit of them
+type Reranker interface {
+	Rerank(ctx context.Context, query string, candidates []string) ([]int, error)
+}
+
+// Option optional dependencies for NewService
+type Option func(*service)
+
+func WithProgressReporter(reporter ProgressReporter) Option {
+	return func(s *service) {
+		s.progressReporter = reporter
+	}
+}
+
+func WithDeltaReporter(reporter DeltaReporter) Option {
+	return func(s *service) {
+		s.deltaReporter = reporter
+	}
+}
+
+// WithReranker the vector search hands config.RerankCandidateLimit chunks to the reranker instead of
+// going straight to the LLM with the top config.SearchResultLimit
+func WithReranker(reranker Reranker) Option {
+	return func(s *service) {
+		s.reranker = reranker
+	}
+}
+
+// WithQueryRewriting has the LLM rewrite follow-ups into a standalone question before they are embedded.
+// maxQueries above 1 lets it add sub-queries for the separate parts of a question, and rewrites every question
+func WithQueryRewriting(maxQueries int) Option {
+	return func(s *service) {
+		s.rewriteQueries = maxQueries
+	}
 }
 
 // NewService constructor
-func NewService(vector vectorDB.DataProcessor, llm llm.Provider, em embedding.Embedder) Service {
-	return &service{
+func NewService(vector vectorDB.DataProcessor, llm llm.Provider, em embedding.Embedder, options ...Option) Service {
+	s := &service{
 		vectorDB:    vector,
 		llmProvider: llm,
 		embedder:    em,
 		logger:      logger_i.NewLogger("RAG Service :"),
 	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
 }
 
-func (s *service) ProcessRequest(ctx context.Context, jobt jobModel.Job, messageHistory []string) jobModel.Job {
+func (s *service) ProcessRequest(ctx context.Context, jobt jobModel.Job, messageHistory []llm.Message, summary string) jobModel.Job {
 	inMethodLogger := s.logger.With("traceId", ctx.Value(config.TRACE_ID_KEY).(string), "JobId", jobt.Id)
 
-	processContext, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	jobt.CurrentStep = jobModel.RAGCall
+	// Query Rewrite
+	queries := s.executeQueryRewriteStep(ctx, inMethodLogger, &jobt, messageHistory, summary)
 
 	// Embedding
-	embeddingStep, err := s.executeEmbeddingStep(processContext, inMethodLogger, &jobt)
+	embeddings, err := s.executeEmbeddingStep(ctx, inMethodLogger, &jobt, queries)
 	if err != nil {
 		return s.jobError(jobt, err, "EMBEDDING_FAILURE", true)
 	}
+	//the cache goes by the standalone question
+	embeddingStep := embeddings[0]
 
-	if jobt.JobType  != jobModel.JobTypeMCP {
+	if jobt.JobType  != jobModel.JobTypeMCP && jobt.JobPayload.Retrieval.UsesCache() {
 	// Cache Check
 		cachedAnswer, found := s.executeCacheCheckStep(ctx, inMethodLogger, &jobt, embeddingStep)
 		if found {
+			jobt.EndStep(jobModel.StepOutcomeHit)
 			return returnOutput(jobt, cachedAnswer)
 		}
+		jobt.EndStep(jobModel.StepOutcomeMiss)
 	}
 
 	// Vector DB Search
-	matches, err := s.executeVectorSearchStep(processContext, inMethodLogger, &jobt, embeddingStep)
+	matches, err := s.executeVectorSearchStep(ctx, inMethodLogger, &jobt, queries, embeddings)
 	if err != nil {
 		return s.jobError(jobt, err, "VECTOR_DB_FAILURE", true)
 	}
 
+	// Rerank
+	matches = s.executeRerankStep(ctx, inMethodLogger, &jobt, queries[0], matches)
+
 	// LLM Generation
-	answer, err := s.executeLLMStep(processContext, inMethodLogger, &jobt, matches, messageHistory)
+	answer, err := s.executeLLMStep(ctx, inMethodLogger, &jobt, withSummary(matches, summary), messageHistory)
 	if err != nil {
 		return s.jobError(jobt, err, "LLM_GENERATION_FAILURE", true)
 	}
 
 	//Background Cache Save
+	if !jobt.JobPayload.Retrieval.UsesCache() {
+		return returnOutput(jobt, answer)
+	}
 	go func() {
-		err = s.vectorDB.SaveToCache(ctx, utils.GetNewUUID(), embeddingStep, answer)
+		owner := commonModels.CacheOwner{JobId: jobt.Id, ChatId: jobt.ChatId, Identity: jobt.Identity}
+		err = s.vectorDB.SaveToCache(ctx, utils.GetNewUUID(), embeddingStep, answer, owner)
 		if err != nil {
 			s.logger.Error("Failed to save to cache")
 		}
@@ -115,9 +181,15 @@ func (s *service) ProcessRequest(ctx context.Context, jobt jobModel.Job, message
 func (s *service) IngestDocument(ctx context.Context, job jobModel.Job) jobModel.Job {
 	start := time.Now()
 	defer func() { metrics.CaptureExecutionMetrics("Document_ingestion", time.Since(start)) }()
-	j := ingest.ProcessDocumentIngestion(ctx, job, s.embedder, s.vectorDB)
+	j := ingest.ProcessDocumentIngestion(ctx, job, s.embedder, s.vectorDB, func(progressJob jobModel.Job) {
+		s.reportProgress(ctx, progressJob)
+	})
 	if j.Status != jobModel.JobStatusComplete {
 		return s.jobError(j, errors.New("ingest Document Failed"), "INGESTION_FAILURE", true)
 	}
 	return j
 }
+
+func (s *service) EraseCachedAnswers(ctx context.Context, chatIds []string, identity string) (int, error) {
+	return s.vectorDB.DeleteCachedAnswers(ctx, chatIds, identity)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/akolanti/GoAPI/internal/domain/commonModels"
 	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/internal/rag/vectorDB"
 )
 
 type MockVectorDB struct {
@@ -13,9 +14,14 @@ type MockVectorDB struct {
 	OnSaveToCache      func(ctx context.Context, id string, vector []float32, answer string) error
 	OnCreateCollection func(ctx context.Context, name string) error
 	OnUpsertBatch      func(ctx context.Context, name string, chunks []commonModels.DocChunk, vectors [][]float32) error
+
+	LastSearch    vectorDB.SearchOptions
+	SearchQueries []string //query of every search
 }
 
-func (m *MockVectorDB) Search(ctx context.Context, v []float32) ([]string, []string, error) {
+func (m *MockVectorDB) Search(ctx context.Context, query string, v []float32, options vectorDB.SearchOptions) ([]string, []string, error) {
+	m.LastSearch = options
+	m.SearchQueries = append(m.SearchQueries, query)
 	if m.OnSearch != nil {
 		return m.OnSearch(ctx, v)
 	}
@@ -29,13 +35,17 @@ func (m *MockVectorDB) GetCachedAnswer(ctx context.Context, v []float32) (string
 	return "", false, nil
 }
 
-func (m *MockVectorDB) SaveToCache(ctx context.Context, id string, v []float32, a string) error {
+func (m *MockVectorDB) SaveToCache(ctx context.Context, id string, v []float32, a string, o commonModels.CacheOwner) error {
 	if m.OnSaveToCache != nil {
 		return m.OnSaveToCache(ctx, id, v, a)
 	}
 	return nil
 }
 
+func (m *MockVectorDB) DeleteCachedAnswers(ctx context.Context, chatIds []string, identity string) (int, error) {
+	return 0, nil
+}
+
 func (m *MockVectorDB) CreateCollection(ctx context.Context, name string) error {
 	if m.OnCreateCollection != nil {
 		return m.OnCreateCollection(ctx, name)
@@ -67,10 +77,10 @@ func (m *MockEmbedder) GetEmbedding(ctx context.Context, query string) ([]float3
 }
 
 type MockLLM struct {
-	OnGenerate func(ctx context.Context, query string, matches []string, history []string) (string, error)
+	OnGenerate func(ctx context.Context, query string, matches []string, history []llm.Message) (string, error)
 }
 
-func (m *MockLLM) Generate(ctx context.Context, q string, mth []string, hist []string) (string, error) {
+func (m *MockLLM) Generate(ctx context.Context, q string, mth []string, hist []llm.Message) (string, error) {
 	if m.OnGenerate != nil {
 		return m.OnGenerate(ctx, q, mth, hist)
 	}
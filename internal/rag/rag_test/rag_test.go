@@ -10,6 +10,7 @@ import (
 	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/internal/domain/commonModels"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
 	"github.com/akolanti/GoAPI/internal/rag"
 )
 
@@ -28,7 +29,7 @@ func TestProcessRequest_Scenarios(t *testing.T) {
 				v.OnGetCachedAnswer = func(ctx context.Context, emb []float32) (string, bool, error) {
 					return "", false, nil
 				}
-				l.OnGenerate = func(ctx context.Context, q string, m []string, h []string) (string, error) {
+				l.OnGenerate = func(ctx context.Context, q string, m []string, h []llm.Message) (string, error) {
 					return "final answer", nil
 				}
 			},
@@ -76,7 +77,7 @@ func TestProcessRequest_Scenarios(t *testing.T) {
 				v.OnGetCachedAnswer = func(ctx context.Context, emb []float32) (string, bool, error) {
 					return "", false, nil
 				}
-				l.OnGenerate = func(ctx context.Context, q string, m []string, h []string) (string, error) {
+				l.OnGenerate = func(ctx context.Context, q string, m []string, h []llm.Message) (string, error) {
 					return "", errors.New("provider down")
 				}
 			},
@@ -105,7 +106,7 @@ func TestProcessRequest_Scenarios(t *testing.T) {
 				},
 			}
 
-			result := s.ProcessRequest(ctx, job, []string{})
+			result := s.ProcessRequest(ctx, job, []llm.Message{}, "")
 
 			if result.Status != tt.expectedStatus {
 				t.Errorf("Step got %v, want %v", result.Status, tt.expectedStatus)
@@ -0,0 +1,100 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/internal/rag"
+)
+
+type stubReranker struct {
+	order []int
+	err   error
+}
+
+func (r stubReranker) Rerank(ctx context.Context, query string, candidates []string) ([]int, error) {
+	return r.order, r.err
+}
+
+// candidates matches m0..m4, each with a source on page 0..4
+func candidates(ctx context.Context, v []float32) ([]string, []string, error) {
+	var matches, metadata []string
+	for i := range 5 {
+		matches = append(matches, fmt.Sprintf("m%d", i))
+		metadata = append(metadata, jobModel.Source{DocumentName: "pump.pdf", Page: i}.Entries()...)
+	}
+	return matches, metadata, nil
+}
+
+func TestProcessRequest_Rerank(t *testing.T) {
+	tests := []struct {
+		name        string
+		reranker    stubReranker
+		wantMatches string
+		wantPages   string
+		wantOutcome jobModel.StepOutcome
+	}{
+		{name: "reordered", reranker: stubReranker{order: []int{3, 0, 4, 1, 2}}, wantMatches: "[m3 m0 m4]", wantPages: "304", wantOutcome: jobModel.StepOutcomeSuccess},
+		{name: "failed keeps the search order", reranker: stubReranker{err: errors.New("reranker down")}, wantMatches: "[m0 m1 m2]", wantPages: "012", wantOutcome: jobModel.StepOutcomeFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vector := &MockVectorDB{OnSearch: candidates}
+			var generatedWith []string
+			provider := &MockLLM{OnGenerate: func(ctx context.Context, q string, m []string, h []llm.Message) (string, error) {
+				generatedWith = m
+				return "answer", nil
+			}}
+			service := rag.NewService(vector, provider, &MockEmbedder{}, rag.WithReranker(tt.reranker))
+			ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "rerank-trace")
+
+			job := service.ProcessRequest(ctx, jobModel.Job{Id: "job-1", JobType: jobModel.JobTypeQuery, JobPayload: jobModel.JobPayload{Question: "q"}}, nil, "")
+
+			if vector.LastSearch.Limit != config.RerankCandidateLimit {
+				t.Errorf("Expected the search to ask for %d candidates, got %d", config.RerankCandidateLimit, vector.LastSearch.Limit)
+			}
+			if fmt.Sprint(generatedWith) != tt.wantMatches {
+				t.Errorf("Expected the LLM to get %s, got %v", tt.wantMatches, generatedWith)
+			}
+			var pages string
+			for _, source := range job.JobPayload.Sources {
+				pages += strconv.Itoa(source.Page)
+			}
+			if pages != tt.wantPages {
+				t.Errorf("Expected the sources to follow their matches (pages %s), got %s", tt.wantPages, pages)
+			}
+			var outcome jobModel.StepOutcome
+			for _, step := range job.Timeline {
+				if step.Step == jobModel.RerankCall {
+					outcome = step.Outcome
+				}
+			}
+			if outcome != tt.wantOutcome || job.JobPayload.Answer != "answer" {
+				t.Errorf("Expected the rerank step to end %s and the job answered, got %s %+v", tt.wantOutcome, outcome, job)
+			}
+		})
+	}
+}
+
+func TestProcessRequest_NoReranker(t *testing.T) {
+	vector := &MockVectorDB{OnSearch: candidates}
+	service := rag.NewService(vector, &MockLLM{}, &MockEmbedder{})
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "rerank-trace")
+
+	job := service.ProcessRequest(ctx, jobModel.Job{Id: "job-1", JobType: jobModel.JobTypeQuery, JobPayload: jobModel.JobPayload{Question: "q"}}, nil, "")
+	if vector.LastSearch.Limit != config.SearchResultLimit {
+		t.Errorf("Expected the search to ask for %d results, got %d", config.SearchResultLimit, vector.LastSearch.Limit)
+	}
+	for _, step := range job.Timeline {
+		if step.Step == jobModel.RerankCall {
+			t.Error("Expected no rerank step without a reranker")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package rag_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/internal/rag"
+	"github.com/akolanti/GoAPI/internal/rag/vectorDB"
+)
+
+func TestProcessRequest_RetrievalOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		retrieval  *jobModel.RetrievalOptions
+		reranker   rag.Reranker
+		wantSearch vectorDB.SearchOptions
+		wantTopK   int
+		wantCache  bool
+	}{
+		{name: "default", wantSearch: vectorDB.SearchOptions{Limit: config.SearchResultLimit}, wantTopK: config.SearchResultLimit, wantCache: true},
+		{
+			name:       "tuned",
+			retrieval:  &jobModel.RetrievalOptions{TopK: 5, MinScore: 0.6, Collection: "pump-manuals"},
+			wantSearch: vectorDB.SearchOptions{Limit: 5, MinScore: 0.6, Collection: "pump-manuals"},
+			wantTopK:   5,
+		},
+		{name: "skip cache", retrieval: &jobModel.RetrievalOptions{SkipCache: true}, wantSearch: vectorDB.SearchOptions{Limit: config.SearchResultLimit}, wantTopK: config.SearchResultLimit},
+		{
+			name:       "reranked down to top_k",
+			retrieval:  &jobModel.RetrievalOptions{TopK: 2},
+			reranker:   stubReranker{order: []int{4, 3, 2, 1, 0}},
+			wantSearch: vectorDB.SearchOptions{Limit: config.RerankCandidateLimit},
+			wantTopK:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cacheReads atomic.Int32
+			vector := &MockVectorDB{}
+			vector.OnSearch = func(ctx context.Context, v []float32) ([]string, []string, error) {
+				var matches []string
+				for i := range vector.LastSearch.Limit {
+					matches = append(matches, fmt.Sprintf("m%d", i))
+				}
+				return matches, nil, nil
+			}
+			vector.OnGetCachedAnswer = func(ctx context.Context, v []float32) (string, bool, error) {
+				cacheReads.Add(1)
+				return "", false, nil
+			}
+			var generatedWith []string
+			provider := &MockLLM{OnGenerate: func(ctx context.Context, q string, m []string, h []llm.Message) (string, error) {
+				generatedWith = m
+				return "answer", nil
+			}}
+			var options []rag.Option
+			if tt.reranker != nil {
+				options = append(options, rag.WithReranker(tt.reranker))
+			}
+			service := rag.NewService(vector, provider, &MockEmbedder{}, options...)
+			ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "retrieval-trace")
+
+			job := service.ProcessRequest(ctx, jobModel.Job{Id: "job-1", JobType: jobModel.JobTypeQuery, JobPayload: jobModel.JobPayload{Question: "q", Retrieval: tt.retrieval}}, nil, "")
+
+			if vector.LastSearch != tt.wantSearch {
+				t.Errorf("Expected the search options %+v, got %+v", tt.wantSearch, vector.LastSearch)
+			}
+			if len(generatedWith) != tt.wantTopK || job.JobPayload.Answer != "answer" {
+				t.Errorf("Expected %d matches to go to the LLM, got %v", tt.wantTopK, generatedWith)
+			}
+			if got := cacheReads.Load() == 1; got != tt.wantCache {
+				t.Errorf("Expected the cache to be read %v, got %d reads", tt.wantCache, cacheReads.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package rag_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/internal/rag"
+)
+
+var followUpHistory = []llm.Message{
+	{Role: llm.RoleUser, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeText, Text: "Which pumps use the X200 impeller?"}}},
+	{Role: llm.RoleAssistant, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeText, Text: "The P10 and the P20."}}},
+}
+
+// rewritingLLM answers the rewrite prompt with rewrite and records the question every answer was generated for
+type rewritingLLM struct {
+	MockLLM
+	rewrite    string
+	rewriteErr error
+	rewrites   int
+	answeredTo []string
+}
+
+func newRewritingLLM(rewrite string, rewriteErr error) *rewritingLLM {
+	l := &rewritingLLM{rewrite: rewrite, rewriteErr: rewriteErr}
+	l.OnGenerate = func(ctx context.Context, q string, m []string, h []llm.Message) (string, error) {
+		if strings.HasPrefix(q, config.QueryRewritePrompt) {
+			l.rewrites++
+			return l.rewrite, l.rewriteErr
+		}
+		l.answeredTo = append(l.answeredTo, q)
+		return "answer", nil
+	}
+	return l
+}
+
+func rewriteStep(job jobModel.Job) jobModel.StepOutcome {
+	for _, step := range job.Timeline {
+		if step.Step == jobModel.QueryRewriteCall {
+			return step.Outcome
+		}
+	}
+	return ""
+}
+
+func TestProcessRequest_RewritesFollowUps(t *testing.T) {
+	vector := &MockVectorDB{}
+	provider := newRewritingLLM("What is the torque of the X200 impeller bolts on the P20?", nil)
+	service := rag.NewService(vector, provider, &MockEmbedder{}, rag.WithQueryRewriting(1))
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "rewrite-trace")
+	question := jobModel.Job{Id: "job-1", JobType: jobModel.JobTypeQuery, JobPayload: jobModel.JobPayload{Question: "and its bolt torque?"}}
+
+	job := service.ProcessRequest(ctx, question, followUpHistory, "")
+	if fmt.Sprint(vector.SearchQueries) != "[What is the torque of the X200 impeller bolts on the P20?]" {
+		t.Errorf("Expected the standalone question to be searched, got %v", vector.SearchQueries)
+	}
+	if len(job.JobPayload.RewrittenQueries) != 1 || rewriteStep(job) != jobModel.StepOutcomeSuccess {
+		t.Errorf("Expected the rewrite kept on the job, got %v %s", job.JobPayload.RewrittenQueries, rewriteStep(job))
+	}
+	if fmt.Sprint(provider.answeredTo) != "[and its bolt torque?]" || job.JobPayload.Answer != "answer" {
+		t.Errorf("Expected the answer generated for the question as asked, got %v", provider.answeredTo)
+	}
+
+	//the first question of a chat has nothing to resolve
+	vector.SearchQueries = nil
+	job = service.ProcessRequest(ctx, question, nil, "")
+	if provider.rewrites != 1 || rewriteStep(job) != "" || fmt.Sprint(vector.SearchQueries) != "[and its bolt torque?]" {
+		t.Errorf("Expected no rewrite without history, got %d rewrites, searched %v", provider.rewrites, vector.SearchQueries)
+	}
+}
+
+func TestProcessRequest_RewriteFailureKeepsQuestion(t *testing.T) {
+	vector := &MockVectorDB{}
+	service := rag.NewService(vector, newRewritingLLM("", errors.New("provider down")), &MockEmbedder{}, rag.WithQueryRewriting(1))
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "rewrite-trace")
+
+	job := service.ProcessRequest(ctx, jobModel.Job{Id: "job-1", JobType: jobModel.JobTypeQuery, JobPayload: jobModel.JobPayload{Question: "and its bolt torque?"}}, nil, "earlier summary")
+	if fmt.Sprint(vector.SearchQueries) != "[and its bolt torque?]" || job.JobPayload.RewrittenQueries != nil {
+		t.Errorf("Expected the question searched as asked, got %v %v", vector.SearchQueries, job.JobPayload.RewrittenQueries)
+	}
+	if rewriteStep(job) != jobModel.StepOutcomeFailed || job.JobPayload.Answer != "answer" {
+		t.Errorf("Expected a failed rewrite step and the job answered, got %s %+v", rewriteStep(job), job)
+	}
+}
+
+func TestProcessRequest_SubQueriesAreMerged(t *testing.T) {
+	//every search finds the shared chunk, and one of its own
+	vector := &MockVectorDB{}
+	vector.OnSearch = func(ctx context.Context, v []float32) ([]string, []string, error) {
+		i := len(vector.SearchQueries) - 1
+		matches := []string{fmt.Sprintf("only%d", i), "shared"}
+		metadata := append(jobModel.Source{DocumentName: "pump.pdf", Page: i}.Entries(), jobModel.Source{DocumentName: "pump.pdf", Page: 9}.Entries()...)
+		return matches, metadata, nil
+	}
+	provider := newRewritingLLM("Compare the P10 and the P20\n1. P10 flow rate\n2. P20 flow rate\n3. P20 flow rate", nil)
+	var generatedWith []string
+	answer := provider.OnGenerate
+	provider.OnGenerate = func(ctx context.Context, q string, m []string, h []llm.Message) (string, error) {
+		generatedWith = m
+		return answer(ctx, q, m, h)
+	}
+	service := rag.NewService(vector, provider, &MockEmbedder{}, rag.WithQueryRewriting(3))
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "rewrite-trace")
+
+	job := service.ProcessRequest(ctx, jobModel.Job{Id: "job-1", JobType: jobModel.JobTypeQuery, JobPayload: jobModel.JobPayload{Question: "P10 vs P20?"}}, nil, "")
+	if fmt.Sprint(vector.SearchQueries) != "[Compare the P10 and the P20 P10 flow rate P20 flow rate]" {
+		t.Errorf("Expected the standalone question and two distinct sub-queries, got %q", vector.SearchQueries)
+	}
+	if fmt.Sprint(generatedWith) != "[shared only0 only1]" {
+		t.Errorf("Expected the chunk every query found first, got %v", generatedWith)
+	}
+	var pages string
+	for _, source := range job.JobPayload.Sources {
+		pages += strconv.Itoa(source.Page)
+	}
+	if pages != "901" {
+		t.Errorf("Expected the sources to follow the merged matches, got pages %s", pages)
+	}
+}
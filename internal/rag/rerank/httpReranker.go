@@ -0,0 +1,81 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/akolanti/GoAPI/internal/config"
+)
+
+// HTTPReranker posts the candidates to a cross-encoder server speaking the text-embeddings-inference
+// rerank API: {"query", "texts"} in, [{"index", "score"}] out
+type HTTPReranker struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPReranker(url string) *HTTPReranker {
+	return &HTTPReranker{url: url, client: &http.Client{Timeout: config.RerankTimeout}}
+}
+
+type rerankRequest struct {
+	Query string   `json:"query"`
+	Texts []string `json:"texts"`
+}
+
+type rerankScore struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+}
+
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, candidates []string) ([]int, error) {
+	body, err := json.Marshal(rerankRequest{Query: query, Texts: candidates})
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, response.Body)
+		return nil, fmt.Errorf("reranker answered %d", response.StatusCode)
+	}
+
+	var scores []rerankScore
+	if err := json.NewDecoder(response.Body).Decode(&scores); err != nil {
+		return nil, fmt.Errorf("decoding reranker scores: %w", err)
+	}
+	//servers sort by score already, that is not part of the contract
+	slices.SortStableFunc(scores, func(a, b rerankScore) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	order := make([]int, 0, len(scores))
+	for _, score := range scores {
+		if score.Index >= 0 && score.Index < len(candidates) {
+			order = append(order, score.Index)
+		}
+	}
+	if len(order) == 0 {
+		return nil, ErrNoRanking
+	}
+	return order, nil
+}
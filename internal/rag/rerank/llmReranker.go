@@ -0,0 +1,62 @@
+package rerank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/llm"
+)
+
+var ErrNoRanking = errors.New("the reranker ranked none of the candidates")
+
+// only the bracketed index the prompt numbers passages with, list numbering, years and counts are not indexes
+var candidateIndex = regexp.MustCompile(`\[(\d+)\]`)
+
+// LLMReranker asks the chat model to order the candidates, no extra service needed but it costs a generation
+type LLMReranker struct {
+	provider llm.Provider
+}
+
+func NewLLMReranker(provider llm.Provider) *LLMReranker {
+	return &LLMReranker{provider: provider}
+}
+
+func (r *LLMReranker) Rerank(ctx context.Context, query string, candidates []string) ([]int, error) {
+	numbered := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		numbered[i] = fmt.Sprintf("[%d] %s", i, candidate)
+	}
+	answer, err := r.provider.Generate(ctx, config.RerankPrompt+"\n\nQuestion: "+query, numbered, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseRanking(answer, len(candidates))
+}
+
+// parseRanking reads the [n] candidate indexes in the order the model gave them, repeats and numbers out of
+// range are dropped. Candidates the model left out follow in the search order
+func parseRanking(answer string, count int) ([]int, error) {
+	order := make([]int, 0, count)
+	listed := make([]bool, count)
+	for _, match := range candidateIndex.FindAllStringSubmatch(answer, -1) {
+		i, err := strconv.Atoi(match[1])
+		if err != nil || i >= count || listed[i] {
+			continue
+		}
+		listed[i] = true
+		order = append(order, i)
+	}
+	if len(order) == 0 {
+		return nil, ErrNoRanking
+	}
+	for i := range count {
+		if !listed[i] {
+			order = append(order, i)
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,73 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/llm"
+)
+
+type answerProvider struct {
+	answer  string
+	matches []string
+}
+
+func (p *answerProvider) Generate(ctx context.Context, query string, matches []string, messageHistory []llm.Message) (string, error) {
+	p.matches = matches
+	return p.answer, nil
+}
+
+func (p *answerProvider) ChatWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
+	return nil, errors.New("not used")
+}
+
+func TestLLMReranker(t *testing.T) {
+	provider := &answerProvider{answer: "[2], [0], [2], [7]"}
+	order, err := NewLLMReranker(provider).Rerank(context.Background(), "q", []string{"a", "b", "c"})
+	if err != nil || fmt.Sprint(order) != "[2 0 1]" {
+		t.Errorf("Expected the listed candidates first and the rest after, got %v %v", order, err)
+	}
+	if provider.matches[1] != "[1] b" {
+		t.Errorf("Expected numbered candidates, got %v", provider.matches)
+	}
+
+	//list numbering and other numbers in the reply are not indexes
+	provider.answer = "1. [1] covers the 2024 revision\n2. [2] lists 3 torque values"
+	order, err = NewLLMReranker(provider).Rerank(context.Background(), "q", []string{"a", "b", "c", "d"})
+	if err != nil || fmt.Sprint(order) != "[1 2 0 3]" {
+		t.Errorf("Expected only the bracketed indexes read, got %v %v", order, err)
+	}
+
+	provider.answer = "none of them help, 1 2 3"
+	if _, err = NewLLMReranker(provider).Rerank(context.Background(), "q", []string{"a"}); !errors.Is(err, ErrNoRanking) {
+		t.Errorf("Expected ErrNoRanking, got %v", err)
+	}
+}
+
+func TestHTTPReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request rerankRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Query != "q" || len(request.Texts) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"index":0,"score":0.1},{"index":2,"score":0.9},{"index":5,"score":0.8},{"index":1,"score":0.5}]`))
+	}))
+	defer server.Close()
+
+	order, err := NewHTTPReranker(server.URL).Rerank(context.Background(), "q", []string{"a", "b", "c"})
+	if err != nil || fmt.Sprint(order) != "[2 1 0]" {
+		t.Errorf("Expected the candidates by score, got %v %v", order, err)
+	}
+
+	_, err = NewHTTPReranker(server.URL).Rerank(context.Background(), "other", []string{"a"})
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected the status code in the error, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/internal/metrics"
+	"github.com/akolanti/GoAPI/internal/rag/vectorDB"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+// executeQueryRewriteStep the queries retrieval runs with, the standalone question first. Without rewriting,
+// on the first question of a chat when there is nothing to split, or when the rewrite fails it is the question itself
+func (s *service) executeQueryRewriteStep(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, history []llm.Message, summary string) []string {
+	question := []string{job.JobPayload.Question}
+	if s.rewriteQueries == 0 || (s.rewriteQueries == 1 && len(history) == 0 && summary == "") {
+		return question
+	}
+	s.logOutput(ctx, job, jobModel.QueryRewriteCall, log)
+
+	start := time.Now()
+	defer func() { metrics.CaptureExecutionMetrics("query_rewrite", time.Since(start)) }()
+
+	prompt := config.QueryRewritePrompt
+	if s.rewriteQueries > 1 {
+		prompt += "\n" + fmt.Sprintf(config.QuerySplitPrompt, s.rewriteQueries-1)
+	}
+	answer, err := s.llmProvider.Generate(ctx, prompt+"\n\nQuestion: "+job.JobPayload.Question, withSummary(nil, summary), history)
+	if err == nil {
+		var queries []string
+		if queries, err = parseQueries(answer, s.rewriteQueries); err == nil {
+			log.Debug("Rewrote question", "queries", queries)
+			job.JobPayload.RewrittenQueries = queries
+			return queries
+		}
+	}
+	log.Warn("Query rewrite failed, retrieving with the question as asked", "error", err)
+	job.EndStep(jobModel.StepOutcomeFailed)
+	return question
+}
+
+// parseQueries one query a line, list markers and quotes the model adds are dropped, so are repeats
+func parseQueries(answer string, maxQueries int) ([]string, error) {
+	var queries []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(answer, "\n") {
+		query := strings.TrimLeft(strings.TrimSpace(line), "-*•0123456789.) ")
+		query = strings.Trim(query, "\"'` ")
+		if query == "" || seen[strings.ToLower(query)] {
+			continue
+		}
+		seen[strings.ToLower(query)] = true
+		queries = append(queries, query)
+		if len(queries) == maxQueries {
+			break
+		}
+	}
+	if len(queries) == 0 {
+		return nil, errors.New("empty rewrite")
+	}
+	return queries, nil
+}
+
+// searchSubQueries searches every query and merges the results with reciprocal rank fusion, the same chunk
+// found by several queries counts once. Only fails when every search does
+func (s *service) searchSubQueries(ctx context.Context, log *logger_i.Logger, job *jobModel.Job, queries []string, embeddings [][]float32, options vectorDB.SearchOptions) ([]string, error) {
+	var rankings []vectorDB.Ranking
+	sources := make(map[string]jobModel.Source)
+	var lastErr error
+	for i, query := range queries {
+		matches, metaData, err := s.vectorDB.Search(ctx, query, embeddings[i], options)
+		if err != nil {
+			log.Warn("Sub-query search failed", "query", query, "error", err)
+			lastErr = err
+			continue
+		}
+		//every match comes with one source, see executeRerankStep
+		if parsed := jobModel.ParseSources(metaData); len(parsed) == len(matches) {
+			for j, match := range matches {
+				sources[match] = parsed[j]
+			}
+		}
+		rankings = append(rankings, vectorDB.Ranking{Ids: matches, Weight: 1})
+	}
+	if len(rankings) == 0 {
+		return nil, lastErr
+	}
+
+	fused := vectorDB.FuseRankings(config.RRFRankConstant, rankings...)
+	fused = fused[:min(len(fused), options.Limit)]
+	job.JobPayload.Sources = nil
+	for _, match := range fused {
+		if source, found := sources[match]; found {
+			job.JobPayload.Sources = append(job.JobPayload.Sources, source)
+		}
+	}
+	return fused, nil
+}
@@ -0,0 +1,40 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/internal/metrics"
+)
+
+// SummariseChat folds the turns into the previous summary, turns are user/assistant pairs oldest first
+func (s *service) SummariseChat(ctx context.Context, previous string, turns []llm.Message) (string, error) {
+	start := time.Now()
+	defer func() { metrics.CaptureExecutionMetrics("chat_summary", time.Since(start)) }()
+
+	prompt := config.ChatSummaryPrompt
+	if previous != "" {
+		prompt += "\n\nPrevious summary:\n" + previous
+	}
+	summary, err := s.llmProvider.Generate(ctx, prompt, nil, turns)
+	if err != nil {
+		return "", err
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", errors.New("empty summary")
+	}
+	return summary, nil
+}
+
+// withSummary the summary of the earlier conversation goes in with the retrieved context of the new question
+func withSummary(matches []string, summary string) []string {
+	if summary == "" {
+		return matches
+	}
+	return append([]string{"Summary of the earlier conversation:\n" + summary}, matches...)
+}
@@ -0,0 +1,54 @@
+package vectorDB
+
+import (
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/akolanti/GoAPI/internal/config"
+)
+
+// Ranking ids best first, Weight scales the ranking's share of the fused score
+type Ranking struct {
+	Ids    []string
+	Weight float64
+}
+
+// FuseRankings weighted reciprocal rank fusion: an id scores weight / (k + rank) in every ranking it is in,
+// rank counting from 1. Only ranks are compared, so dense similarities and BM25 scores never have to share a scale.
+// Ids are returned best first, a tie keeps the id that was ranked first earlier
+func FuseRankings(k int, rankings ...Ranking) []string {
+	scores := make(map[string]float64)
+	var order []string
+	for _, ranking := range rankings {
+		for rank, id := range ranking.Ids {
+			if _, seen := scores[id]; !seen {
+				order = append(order, id)
+			}
+			scores[id] += ranking.Weight / float64(k+rank+1)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b string) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return 0
+	})
+	return order
+}
+
+// HybridWeights the dense and keyword weights, HYBRID_DENSE_WEIGHT and HYBRID_KEYWORD_WEIGHT override the config
+func HybridWeights() (dense float64, keyword float64) {
+	return envWeight("HYBRID_DENSE_WEIGHT", config.HybridDenseWeight), envWeight("HYBRID_KEYWORD_WEIGHT", config.HybridKeywordWeight)
+}
+
+func envWeight(name string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
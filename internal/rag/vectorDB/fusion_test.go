@@ -0,0 +1,33 @@
+package vectorDB
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFuseRankings(t *testing.T) {
+	dense := Ranking{Ids: []string{"a", "b", "c"}, Weight: 1}
+	keyword := Ranking{Ids: []string{"c", "d"}, Weight: 1}
+
+	//c is in both rankings and beats a, which only tops one of them
+	if got := FuseRankings(60, dense, keyword); fmt.Sprint(got) != "[c a b d]" {
+		t.Errorf("Unexpected fusion %v", got)
+	}
+	//with the keyword weight off only the dense order is left, d ties at 0 and stays last
+	keyword.Weight = 0
+	if got := FuseRankings(60, dense, keyword); fmt.Sprint(got) != "[a b c d]" {
+		t.Errorf("Unexpected fusion without keywords %v", got)
+	}
+	keyword.Weight = 3
+	if got := FuseRankings(60, dense, keyword); got[0] != "c" || got[1] != "d" {
+		t.Errorf("Expected the heavier keyword ranking first, got %v", got)
+	}
+}
+
+func TestHybridWeights(t *testing.T) {
+	t.Setenv("HYBRID_KEYWORD_WEIGHT", "0.5")
+	t.Setenv("HYBRID_DENSE_WEIGHT", "not a number")
+	if dense, keyword := HybridWeights(); dense != 1 || keyword != 0.5 {
+		t.Errorf("Expected the config dense weight and the env keyword weight, got %v %v", dense, keyword)
+	}
+}
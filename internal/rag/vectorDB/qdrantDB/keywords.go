@@ -0,0 +1,155 @@
+package qdrantDB
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/rag/keyword"
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// keywordIndexes collection name -> whether it has the keyword sparse vector. Qdrant can't add a sparse vector
+// to an existing collection, collections created before it are migrated at startup, see addKeywordIndex
+var keywordIndexes sync.Map
+
+// addKeywordIndex gives a collection created before the keyword index its sparse vector: the points are copied
+// aside, the collection is created again and they are copied back with their keyword vectors. Every step can be
+// picked up again after a restart, the copies are upserts and the copy aside is dropped once all points are back
+func addKeywordIndex(ctx context.Context, client *qdrant.Client, name string) error {
+	aside := name + config.KeywordMigrationSuffix
+	exists, err := client.CollectionExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	asideExists, err := client.CollectionExists(ctx, aside)
+	if err != nil {
+		return err
+	}
+	if !exists && !asideExists {
+		return nil
+	}
+	if exists {
+		info, err := client.GetCollectionInfo(ctx, name)
+		if err != nil {
+			return err
+		}
+		_, indexed := info.GetConfig().GetParams().GetSparseVectorsConfig().GetMap()[config.KeywordVectorName]
+		if indexed && !asideExists {
+			return nil
+		}
+		if !indexed {
+			logger.Warn("Collection has no keyword index, migrating it", "collection", name)
+			if err = createCollection(ctx, client, aside); err != nil {
+				return err
+			}
+			if err = copyPoints(ctx, client, name, aside); err != nil {
+				return err
+			}
+			if err = client.DeleteCollection(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	if err = createCollection(ctx, client, name); err != nil {
+		return err
+	}
+	if err = copyPoints(ctx, client, aside, name); err != nil {
+		return err
+	}
+	if err = client.DeleteCollection(ctx, aside); err != nil {
+		return err
+	}
+	keywordIndexes.Store(name, true)
+	logger.Info("Collection migrated to the keyword index", "collection", name)
+	return nil
+}
+
+// copyPoints upserts every point of from into to, with the keyword vector of its content
+func copyPoints(ctx context.Context, client *qdrant.Client, from string, to string) error {
+	var offset *qdrant.PointId
+	for {
+		points, next, err := client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: from,
+			Offset:         offset,
+			Limit:          qdrant.PtrOf(uint32(config.KeywordMigrationBatchSize)),
+			WithPayload:    qdrant.NewWithPayload(true),
+			WithVectors:    qdrant.NewWithVectors(true),
+		})
+		if err != nil {
+			return fmt.Errorf("reading the points of %s: %w", from, err)
+		}
+		if len(points) > 0 {
+			upserts := make([]*qdrant.PointStruct, len(points))
+			for i, point := range points {
+				upserts[i] = &qdrant.PointStruct{
+					Id:      point.GetId(),
+					Vectors: pointVectors(denseVector(point.GetVectors()), point.GetPayload()["content"].GetStringValue(), true),
+					Payload: point.GetPayload(),
+				}
+			}
+			_, err = client.Upsert(ctx, &qdrant.UpsertPoints{CollectionName: to, Points: upserts, Wait: qdrant.PtrOf(true)})
+			if err != nil {
+				return fmt.Errorf("copying points into %s: %w", to, err)
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		offset = next
+	}
+}
+
+// denseVector the unnamed dense vector of a point, named "" once the point has a keyword vector too
+func denseVector(vectors *qdrant.VectorsOutput) []float32 {
+	vector := vectors.GetVector()
+	if vector == nil {
+		vector = vectors.GetVectors().GetVectors()[""]
+	}
+	if dense := vector.GetDense(); dense != nil {
+		return dense.GetData()
+	}
+	return vector.GetData()
+}
+
+func (db *ClientHolder) hasKeywordIndex(ctx context.Context, collectionName string) bool {
+	if known, ok := keywordIndexes.Load(collectionName); ok {
+		return known.(bool)
+	}
+	info, err := db.QObj.GetCollectionInfo(ctx, collectionName)
+	if err != nil {
+		//asked again next time
+		logger.Warn("Could not read the collection config, searching without keywords", "collection", collectionName, "error", err)
+		return false
+	}
+	_, found := info.GetConfig().GetParams().GetSparseVectorsConfig().GetMap()[config.KeywordVectorName]
+	if !found {
+		logger.Warn("Collection has no keyword index, only the dense search is used until it is migrated at startup", "collection", collectionName)
+	}
+	keywordIndexes.Store(collectionName, found)
+	return found
+}
+
+func (db *ClientHolder) keywordSearch(ctx context.Context, collection string, keywords keyword.SparseVector, limit int) ([]*qdrant.ScoredPoint, error) {
+	return db.QObj.Query(ctx, &qdrant.QueryPoints{
+		CollectionName: collection,
+		Query:          qdrant.NewQuerySparse(keywords.Indices, keywords.Values),
+		Using:          qdrant.PtrOf(config.KeywordVectorName),
+		Limit:          qdrant.PtrOf(uint64(limit)),
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+}
+
+// pointVectors the chunk's dense vector, with its keyword vector next to it when the collection has the index.
+// "" is the name qdrant gives the unnamed dense vector
+func pointVectors(dense []float32, text string, withKeywords bool) *qdrant.Vectors {
+	keywords := keyword.EncodeDocument(text)
+	if !withKeywords || len(keywords.Indices) == 0 {
+		return qdrant.NewVectors(dense...)
+	}
+	return qdrant.NewVectorsMap(map[string]*qdrant.Vector{
+		"":                       qdrant.NewVectorDense(dense),
+		config.KeywordVectorName: qdrant.NewVectorSparse(keywords.Indices, keywords.Values),
+	})
+}
@@ -1,6 +1,7 @@
 package qdrantDB
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -10,6 +11,8 @@ import (
 
 	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/internal/domain/commonModels"
+	"github.com/akolanti/GoAPI/internal/rag/keyword"
+	"github.com/akolanti/GoAPI/internal/rag/vectorDB"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 	"github.com/qdrant/go-client/qdrant"
 )
@@ -64,6 +67,13 @@ func newClient() *qdrant.Client {
 		logger.Error("could not instantiate: ", "error:", err)
 	}
 
+	//a failed migration leaves the collection dense only, it is picked up again on the next start
+	for _, collection := range vectorDB.SearchableCollections() {
+		if err = addKeywordIndex(context.Background(), client, collection); err != nil {
+			logger.Error("could not migrate the collection to the keyword index", "collectionName", collection, "error:", err)
+		}
+	}
+
 	err = createCollection(context.Background(), client, config.EmbeddingDBName)
 	if err != nil {
 		logger.Error("could not create collection: ", "collectionName", config.EmbeddingDBName, "error:", err)
@@ -83,20 +93,82 @@ func closeQdrant(ctx context.Context, qi *qdrant.Client) {
 	logger.Info("Closed Qdrant")
 }
 
-func (db *ClientHolder) Search(ctx context.Context, vectorFloat []float32) ([]string, []string, error) {
+// Search the chunks that best answer the query. The dense search on vectorFloat and a keyword search on the
+// query's terms run side by side and are fused (see vectorDB.FuseRankings), if either fails the other one's
+// hits are used. Collections created before the keyword index was added only have the dense search
+func (db *ClientHolder) Search(ctx context.Context, query string, vectorFloat []float32, options vectorDB.SearchOptions) ([]string, []string, error) {
 	loggr := logger.With("traceId", ctx.Value(config.TRACE_ID_KEY))
-	result, err := db.QObj.Query(ctx, &qdrant.QueryPoints{
-		CollectionName: collectionName, //TODO:with access control this collection should be dynamic ie parameterized
+	collection := cmp.Or(options.Collection, collectionName)
+	limit := options.Limit
+	denseWeight, keywordWeight := vectorDB.HybridWeights()
+	keywords := keyword.EncodeQuery(query)
+	if keywordWeight == 0 || len(keywords.Indices) == 0 || !db.hasKeywordIndex(ctx, collection) {
+		result, err := db.denseSearch(ctx, collection, vectorFloat, limit, options.MinScore)
+		if err != nil {
+			loggr.Error("Error querying Qdrant: ", "error:", err)
+			return nil, nil, err
+		}
+		return toMatches(loggr, result)
+	}
+
+	candidates := max(config.HybridCandidateLimit, limit)
+	var denseHits, keywordHits []*qdrant.ScoredPoint
+	var denseErr, keywordErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		denseHits, denseErr = db.denseSearch(ctx, collection, vectorFloat, candidates, options.MinScore)
+	}()
+	go func() {
+		defer wg.Done()
+		keywordHits, keywordErr = db.keywordSearch(ctx, collection, keywords, candidates)
+	}()
+	wg.Wait()
+
+	switch {
+	case denseErr != nil && keywordErr != nil:
+		loggr.Error("Error querying Qdrant: ", "error:", denseErr, "keyword error", keywordErr)
+		return nil, nil, denseErr
+	case denseErr != nil:
+		loggr.Warn("Dense search failed, using the keyword hits", "error", denseErr)
+	case keywordErr != nil:
+		loggr.Warn("Keyword search failed, using the dense hits", "error", keywordErr)
+	}
+
+	hits := make(map[string]*qdrant.ScoredPoint, len(denseHits)+len(keywordHits))
+	rankings := []vectorDB.Ranking{{Weight: denseWeight}, {Weight: keywordWeight}}
+	for i, result := range [][]*qdrant.ScoredPoint{denseHits, keywordHits} {
+		for _, hit := range result {
+			id := hit.GetId().String()
+			hits[id] = hit
+			rankings[i].Ids = append(rankings[i].Ids, id)
+		}
+	}
+	fused := vectorDB.FuseRankings(config.RRFRankConstant, rankings...)
+	result := make([]*qdrant.ScoredPoint, 0, limit)
+	for _, id := range fused[:min(len(fused), limit)] {
+		result = append(result, hits[id])
+	}
+	loggr.Debug("Fused search hits", "dense", len(denseHits), "keyword", len(keywordHits))
+	return toMatches(loggr, result)
+}
+
+// denseSearch minScore 0 keeps every hit
+func (db *ClientHolder) denseSearch(ctx context.Context, collection string, vectorFloat []float32, limit int, minScore float32) ([]*qdrant.ScoredPoint, error) {
+	query := &qdrant.QueryPoints{
+		CollectionName: collection,
 		Query:          qdrant.NewQuery(vectorFloat...),
-		Limit:          qdrant.PtrOf(uint64(3)),
+		Limit:          qdrant.PtrOf(uint64(limit)),
 		WithPayload:    qdrant.NewWithPayload(true),
-	})
-
-	if err != nil {
-		loggr.Error("Error querying Qdrant: ", "error:", err)
-		return nil, nil, err
 	}
+	if minScore > 0 {
+		query.ScoreThreshold = qdrant.PtrOf(minScore)
+	}
+	return db.QObj.Query(ctx, query)
+}
 
+func toMatches(loggr *logger_i.Logger, result []*qdrant.ScoredPoint) ([]string, []string, error) {
 	//rewrite this for the structure we want for the pdf db
 	var matches []string
 	var metadata []string
@@ -106,20 +178,29 @@ func (db *ClientHolder) Search(ctx context.Context, vectorFloat []float32) ([]st
 		docName := hit.Payload["doc_name"].GetStringValue()
 		combined := fmt.Sprintf("Content: %s, DocumentName: %s", content, docName)
 
-		pageNum := fmt.Sprintf("page_num:%s", hit.Payload["page_num"].GetStringValue())
-		docId := fmt.Sprintf("source_doc_id:%s", hit.Payload["source_doc_id"].GetStringValue())
-		chunkOrder := fmt.Sprintf("chunk_order:%s", hit.Payload["chunk_order"].GetStringValue())
-		chunkId := fmt.Sprintf("chunk_id:%s", hit.Payload["chunk_id"].GetStringValue())
-		ingestedAt := fmt.Sprintf("ingested_at:%s", hit.Payload["ingested_at"].GetStringValue())
-
-		metadata = append(metadata, pageNum, chunkOrder, chunkId, ingestedAt, docId)
+		//one source is a run of key:value entries starting with doc_name, see jobModel.ParseSources
+		for _, key := range []string{"doc_name", "page_num", "chunk_order", "chunk_id", "ingested_at", "source_doc_id"} {
+			metadata = append(metadata, key+":"+payloadText(hit.Payload[key]))
+		}
 		matches = append(matches, combined)
 	}
 
-	loggr.Debug("Found matches: %v", matches)
+	loggr.Debug("Found matches", "matches", matches)
 	return matches, metadata, nil
 }
 
+// payloadText page_num, chunk_order and ingested_at are stored as integers, GetStringValue reads those as ""
+func payloadText(value *qdrant.Value) string {
+	switch kind := value.GetKind().(type) {
+	case *qdrant.Value_IntegerValue:
+		return strconv.FormatInt(kind.IntegerValue, 10)
+	case *qdrant.Value_DoubleValue:
+		return strconv.FormatFloat(kind.DoubleValue, 'f', -1, 64)
+	default:
+		return value.GetStringValue()
+	}
+}
+
 func (db *ClientHolder) CreateCollection(ctx context.Context, collectionName string) error {
 	return createCollection(ctx, db.QObj, collectionName)
 }
@@ -130,6 +211,7 @@ func (db *ClientHolder) UpsertBatch(ctx context.Context, collectionName string,
 	}
 
 	qdrantPoints := make([]*qdrant.PointStruct, len(chunks))
+	withKeywords := db.hasKeywordIndex(ctx, collectionName)
 
 	for i, chunk := range chunks {
 		// 2. Map Chunk to Qdrant Point
@@ -138,7 +220,7 @@ func (db *ClientHolder) UpsertBatch(ctx context.Context, collectionName string,
 			Id: qdrant.NewID(chunk.ChunkId),
 
 			// Converts []float32 to Qdrant's Vector format
-			Vectors: qdrant.NewVectors(vectors[i]...),
+			Vectors: pointVectors(vectors[i], chunk.Chunk, withKeywords),
 
 			Payload: qdrant.NewValueMap(map[string]any{
 				"content":       chunk.Chunk,
@@ -187,6 +269,13 @@ func createCollection(ctx context.Context, client *qdrant.Client, collectionName
 			Size:     dimension, //TODO:this shouldnt be hardcoded
 			Distance: qdrant.Distance_Cosine,
 		}),
+		//the keyword index, qdrant works out the IDF part of BM25 at query time
+		SparseVectorsConfig: qdrant.NewSparseVectorsConfig(map[string]*qdrant.SparseVectorParams{
+			config.KeywordVectorName: {Modifier: qdrant.Modifier_Idf.Enum()},
+		}),
 	})
+	if err == nil {
+		keywordIndexes.Store(collectionName, true)
+	}
 	return err
 }
@@ -2,20 +2,82 @@ package qdrantDB
 
 import (
 	"context"
+	"crypto/sha256"
+	"strings"
 	"time"
 
 	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/commonModels"
+	"github.com/google/uuid"
 	"github.com/qdrant/go-client/qdrant"
 )
 
 var semanticCacheDBName string = "semantic-cache"
 
+// rejectedAnswersDBName the blocklist of negatively rated answers, one point per answer hash. The API records
+// them and the workers check them, qdrant is the store both sides share
+var rejectedAnswersDBName string = "semantic-cache-rejected"
+
 func initCacheCollection(ctx context.Context, client *qdrant.Client) {
 	loggr := logger.With("traceId", ctx.Value(config.TRACE_ID_KEY))
 	err := createCollection(ctx, client, semanticCacheDBName)
 	if err != nil {
 		loggr.Error("Semantic cache collection creation failed", "error", err)
 	}
+	if err = createRejectedCollection(ctx, client); err != nil {
+		loggr.Error("Rejected answers collection creation failed", "error", err)
+	}
+}
+
+// createRejectedCollection points are only ever looked up by id, the vector is a placeholder qdrant requires
+func createRejectedCollection(ctx context.Context, client *qdrant.Client) error {
+	exists, err := client.CollectionExists(ctx, rejectedAnswersDBName)
+	if err != nil || exists {
+		return err
+	}
+	return client.CreateCollection(ctx, &qdrant.CreateCollection{
+		CollectionName: rejectedAnswersDBName,
+		VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
+			Size:     1,
+			Distance: qdrant.Distance_Dot,
+		}),
+	})
+}
+
+// rejectedAnswerId the answer alone identifies a rejection: eviction removes the answer whatever question it was
+// cached for, and a job served from the cache was asked differently than the entry it got
+func rejectedAnswerId(answer string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(answer)))
+	return uuid.NewSHA1(uuid.Nil, sum[:]).String()
+}
+
+// isRejected an answer that can't be checked counts as rejected, the cost is a cache miss
+func (db *ClientHolder) isRejected(ctx context.Context, answer string) bool {
+	points, err := db.QObj.Get(ctx, &qdrant.GetPoints{
+		CollectionName: rejectedAnswersDBName,
+		Ids:            []*qdrant.PointId{qdrant.NewID(rejectedAnswerId(answer))},
+	})
+	if err != nil {
+		logger.With("traceId", ctx.Value(config.TRACE_ID_KEY)).Warn("Checking rejected answers failed", "error", err)
+		return true
+	}
+	return len(points) > 0
+}
+
+// rejectAnswer only the hash is kept, the blocklist holds nothing erasure has to find
+func (db *ClientHolder) rejectAnswer(ctx context.Context, answer string) error {
+	_, err := db.QObj.Upsert(ctx, &qdrant.UpsertPoints{
+		CollectionName: rejectedAnswersDBName,
+		Wait:           qdrant.PtrOf(true),
+		Points: []*qdrant.PointStruct{
+			{
+				Id:      qdrant.NewID(rejectedAnswerId(answer)),
+				Vectors: qdrant.NewVectors(1),
+				Payload: qdrant.NewValueMap(map[string]any{"timestamp": time.Now().Unix()}),
+			},
+		},
+	})
+	return err
 }
 
 func (db *ClientHolder) GetCachedAnswer(ctx context.Context, queryVector []float32) (string, bool, error) {
@@ -39,15 +101,23 @@ func (db *ClientHolder) GetCachedAnswer(ctx context.Context, queryVector []float
 		return "", false, nil
 	}
 
-	loggr.Info("---------------cache hit---------------------")
 	// Extract the answer from your JobPayload structure stored in payload
 	answer := searchResult[0].Payload["answer"].GetStringValue()
+	if db.isRejected(ctx, answer) {
+		loggr.Info("Cached answer was rated negatively, not serving it")
+		return "", false, nil
+	}
+	loggr.Info("---------------cache hit---------------------")
 	return answer, true, nil
 }
 
-func (db *ClientHolder) SaveToCache(ctx context.Context, id string, vector []float32, answer string) error {
+func (db *ClientHolder) SaveToCache(ctx context.Context, id string, vector []float32, answer string, owner commonModels.CacheOwner) error {
 	loggr := logger.With("traceId", ctx.Value(config.TRACE_ID_KEY))
 
+	if db.isRejected(ctx, answer) {
+		loggr.Info("Answer was rated negatively before, not caching it")
+		return nil
+	}
 	loggr.Debug("Saving answer to cache")
 	_, err := db.QObj.Upsert(ctx, &qdrant.UpsertPoints{
 		CollectionName: "semantic-cache",
@@ -58,6 +128,9 @@ func (db *ClientHolder) SaveToCache(ctx context.Context, id string, vector []flo
 				Payload: qdrant.NewValueMap(map[string]any{
 					"answer":    answer,
 					"timestamp": time.Now().Unix(),
+					"job_id":    owner.JobId,
+					"chat_id":   owner.ChatId,
+					"identity":  owner.Identity,
 				}),
 			},
 		},
@@ -67,3 +140,78 @@ func (db *ClientHolder) SaveToCache(ctx context.Context, id string, vector []flo
 	}
 	return err
 }
+
+// DeleteCachedAnswers answers cached before the owner was saved in the payload can't be matched
+func (db *ClientHolder) DeleteCachedAnswers(ctx context.Context, chatIds []string, identity string) (int, error) {
+	loggr := logger.With("traceId", ctx.Value(config.TRACE_ID_KEY))
+
+	var conditions []*qdrant.Condition
+	if len(chatIds) > 0 {
+		conditions = append(conditions, qdrant.NewMatchKeywords("chat_id", chatIds...))
+	}
+	if identity != "" {
+		conditions = append(conditions, qdrant.NewMatch("identity", identity))
+	}
+	if len(conditions) == 0 {
+		return 0, nil
+	}
+	filter := &qdrant.Filter{Should: conditions}
+
+	count, err := db.QObj.Count(ctx, &qdrant.CountPoints{
+		CollectionName: semanticCacheDBName,
+		Filter:         filter,
+		Exact:          qdrant.PtrOf(true),
+	})
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	_, err = db.QObj.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: semanticCacheDBName,
+		Wait:           qdrant.PtrOf(true),
+		Points:         qdrant.NewPointsSelectorFilter(filter),
+	})
+	if err != nil {
+		loggr.Error("Deleting cached answers failed", "error", err)
+		return 0, err
+	}
+	loggr.Info("Deleted cached answers", "count", count)
+	return int(count), nil
+}
+
+// EvictAnswer removes the job's cache entry and every other entry holding the same answer, a job served from
+// the cache has the answer of the job that created the entry and no entry of its own. The answer is recorded
+// as rejected first, so it is neither cached nor served again when the LLM comes up with it once more
+func (db *ClientHolder) EvictAnswer(ctx context.Context, jobId string, answer string) (int, error) {
+	loggr := logger.With("traceId", ctx.Value(config.TRACE_ID_KEY))
+
+	if err := db.rejectAnswer(ctx, answer); err != nil {
+		loggr.Error("Recording rejected answer failed", "job Id", jobId, "error", err)
+		return 0, err
+	}
+
+	filter := &qdrant.Filter{Should: []*qdrant.Condition{
+		qdrant.NewMatch("job_id", jobId),
+		qdrant.NewMatch("answer", answer),
+	}}
+	count, err := db.QObj.Count(ctx, &qdrant.CountPoints{
+		CollectionName: semanticCacheDBName,
+		Filter:         filter,
+		Exact:          qdrant.PtrOf(true),
+	})
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	_, err = db.QObj.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: semanticCacheDBName,
+		Wait:           qdrant.PtrOf(true),
+		Points:         qdrant.NewPointsSelectorFilter(filter),
+	})
+	if err != nil {
+		loggr.Error("Evicting cached answer failed", "job Id", jobId, "error", err)
+		return 0, err
+	}
+	loggr.Info("Evicted cached answer", "job Id", jobId, "count", count)
+	return int(count), nil
+}
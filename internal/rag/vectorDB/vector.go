@@ -2,16 +2,46 @@ package vectorDB
 
 import (
 	"context"
+	"os"
+	"slices"
+	"strings"
 
+	"github.com/akolanti/GoAPI/internal/config"
 	"github.com/akolanti/GoAPI/internal/domain/commonModels"
 )
 
+// SearchOptions Collection "" is the collection documents are ingested into, MinScore 0 keeps every hit
+type SearchOptions struct {
+	Limit      int
+	MinScore   float32 //only applies to the embedding search, keyword hits are exact term matches
+	Collection string
+}
+
 type DataProcessor interface {
-	Search(ctx context.Context, vectorVal []float32) ([]string, []string, error)
+	// Search the chunks closest to the query, vectorVal is its embedding
+	Search(ctx context.Context, query string, vectorVal []float32, options SearchOptions) ([]string, []string, error)
 	GetCachedAnswer(ctx context.Context, queryVector []float32) (string, bool, error)
-	SaveToCache(ctx context.Context, id string, vector []float32, answer string) error
+	SaveToCache(ctx context.Context, id string, vector []float32, answer string, owner commonModels.CacheOwner) error
+	// DeleteCachedAnswers removes every cached answer of the chats or the identity and returns how many there were
+	DeleteCachedAnswers(ctx context.Context, chatIds []string, identity string) (int, error)
 
 	// CreateCollection Ingest document call
 	CreateCollection(ctx context.Context, collectionName string) error
 	UpsertBatch(ctx context.Context, collectionName string, chunks []commonModels.DocChunk, vectors [][]float32) error
 }
+
+// SearchableCollection the ingest collection, or one listed in RETRIEVAL_COLLECTIONS
+func SearchableCollection(name string) bool {
+	return name == "" || slices.Contains(SearchableCollections(), name)
+}
+
+// SearchableCollections the ingest collection and the ones listed in RETRIEVAL_COLLECTIONS
+func SearchableCollections() []string {
+	collections := []string{config.EmbeddingDBName}
+	for _, collection := range strings.Split(os.Getenv("RETRIEVAL_COLLECTIONS"), ",") {
+		if collection = strings.TrimSpace(collection); collection != "" && !slices.Contains(collections, collection) {
+			collections = append(collections, collection)
+		}
+	}
+	return collections
+}
@@ -0,0 +1,22 @@
+package vectorDB
+
+import (
+	"testing"
+
+	"github.com/akolanti/GoAPI/internal/config"
+)
+
+func TestSearchableCollection(t *testing.T) {
+	t.Setenv("RETRIEVAL_COLLECTIONS", "pump-manuals, valve-manuals")
+	for name, want := range map[string]bool{
+		"":                     true,
+		config.EmbeddingDBName: true,
+		"valve-manuals":        true,
+		"semantic-cache":       false,
+		"pump":                 false,
+	} {
+		if got := SearchableCollection(name); got != want {
+			t.Errorf("SearchableCollection(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
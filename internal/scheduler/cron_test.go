@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	}
+
+	for _, expression := range tests {
+		if _, err := parseCron(expression); err == nil {
+			t.Errorf("parseCron(%q) expected error, got nil", expression)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2026, time.March, 10, 14, 37, 20, 0, time.UTC) //a tuesday
+
+	tests := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.March, 10, 14, 38, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.March, 10, 14, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, time.March, 11, 2, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2026, time.March, 11, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		//both day fields restricted - either one matches
+		{"0 12 1 * 5", time.Date(2026, time.March, 13, 12, 0, 0, 0, time.UTC)},
+		{"5/20 14 * * *", time.Date(2026, time.March, 10, 14, 45, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			cron, err := parseCron(tt.expression)
+			if err != nil {
+				t.Fatalf("parseCron(%q) failed: %v", tt.expression, err)
+			}
+			if got := cron.Next(base); !got.Equal(tt.expected) {
+				t.Errorf("Next got %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCronNext_NeverMatches(t *testing.T) {
+	cron, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parseCron failed: %v", err)
+	}
+	if got := cron.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Expected zero time for an impossible date, got %v", got)
+	}
+}
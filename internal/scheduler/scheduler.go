@@ -0,0 +1,300 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/adapter/utils"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/job"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+//the scheduler is a ticker that every replica runs, but only the replica holding
+//the leader lock in the store actually fires anything.
+//firing a schedule is just calling CreateJob like a handler would, so scheduled jobs
+//are indistinguishable from user jobs once they hit the job channel.
+
+var (
+	logger      *logger_i.Logger
+	_jobService *job.Service
+	instanceId  string
+)
+
+var (
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+)
+
+func InitScheduler(ctx context.Context, jobService *job.Service) {
+	logger = logger_i.NewLogger("Scheduler")
+	_jobService = jobService
+
+	host, _ := os.Hostname()
+	instanceId = host + "-" + utils.GetNewUUID()
+
+	logger.Info("Starting scheduler", "instance", instanceId)
+	go run(ctx)
+}
+
+func run(ctx context.Context) {
+	ticker := time.NewTicker(config.SchedulerTickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Scheduler stopped")
+			return
+		case <-ticker.C:
+			tick(ctx)
+		}
+	}
+}
+
+func tick(ctx context.Context) {
+	store := _jobService.ScheduleStore
+	if !store.AcquireLeaderLock(ctx, config.SchedulerLockName, instanceId, config.SchedulerLockTTL) {
+		return
+	}
+
+	schedules, err := store.ListSchedules(ctx)
+	if err != nil {
+		logger.Error("Could not list schedules", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, schedule := range schedules {
+		if schedule.IsDue(now) {
+			fire(ctx, schedule, now)
+		}
+	}
+}
+
+func fire(ctx context.Context, schedule jobModel.Schedule, now time.Time) {
+	traceId := utils.GetNewUUID()
+	log := logger.With("traceId", traceId, "schedule Id", schedule.Id)
+
+	params, err := toJobParams(ctx, schedule, traceId)
+	if err != nil {
+		log.Error("Could not build scheduled job", "error", err)
+	} else {
+		log.Info("Firing schedule", "job Id", params.ID, "job type", schedule.JobType)
+		_jobService.CreateJob(params)
+	}
+
+	//re-read so a pause or delete that landed while we were firing isn't overwritten
+	latest, found := _jobService.ScheduleStore.GetSchedule(ctx, schedule.Id)
+	if !found {
+		return
+	}
+	latest.LastRun = now
+	if err == nil {
+		latest.LastJobId = params.ID
+	}
+	latest.NextRun = nextRun(latest, now)
+	if err = _jobService.ScheduleStore.SaveSchedule(ctx, latest); err != nil {
+		log.Error("Could not save schedule after firing", "error", err)
+	}
+}
+
+func toJobParams(ctx context.Context, schedule jobModel.Schedule, traceId string) (job.CreateJobParams, error) {
+	params := job.CreateJobParams{
+		ID:       utils.GetNewUUID(),
+		TraceID:  traceId,
+		Identity: schedule.Identity,
+	}
+
+	if schedule.JobType == jobModel.JobTypeIngest {
+		//ingestion deletes its source file when done so hand it a copy
+		copyPath, err := copyToTemporaryData(schedule.JobPayload.IngestURL)
+		if err != nil {
+			return params, err
+		}
+		params.IsDocumentIngest = true
+		params.DocumentName = schedule.JobPayload.IngestFileName
+		params.DocumentSource = copyPath
+		return params, nil
+	}
+
+	params.Message = schedule.JobPayload.Question
+	params.IsMCPCall = schedule.JobType == jobModel.JobTypeMCP
+	params.ChatID = schedule.ChatId
+	//a schedule pointed at a chat that is gone, or that isn't its identity's, starts a fresh one
+	if params.ChatID == "" || !_jobService.OwnsChat(ctx, params.ChatID, schedule.Identity) {
+		params.ChatID = utils.GetNewUUID()
+		params.IsNewChat = true
+	}
+	return params, nil
+}
+
+// nextRun is the zero time once a one-off schedule has fired
+func nextRun(schedule jobModel.Schedule, after time.Time) time.Time {
+	if !schedule.IsRecurring() {
+		return time.Time{}
+	}
+	cron, err := parseCron(schedule.CronExpression)
+	if err != nil {
+		return time.Time{}
+	}
+	return cron.Next(after)
+}
+
+func CreateSchedule(ctx context.Context, schedule jobModel.Schedule) (jobModel.Schedule, error) {
+	if err := validateSchedule(&schedule); err != nil {
+		return schedule, err
+	}
+
+	now := time.Now()
+	schedule.Id = utils.GetNewUUID()
+	schedule.CreatedTime = now
+	if schedule.IsRecurring() {
+		schedule.NextRun = nextRun(schedule, now)
+	} else {
+		schedule.NextRun = schedule.RunAt
+	}
+
+	if err := _jobService.ScheduleStore.SaveSchedule(ctx, schedule); err != nil {
+		return schedule, err
+	}
+	logger.Info("Created schedule", "schedule Id", schedule.Id, "next run", schedule.NextRun)
+	return schedule, nil
+}
+
+// ListSchedules the schedules of the identity, oldest first
+func ListSchedules(ctx context.Context, identity string) ([]jobModel.Schedule, error) {
+	schedules, err := _jobService.ScheduleStore.ListSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedules = slices.DeleteFunc(schedules, func(schedule jobModel.Schedule) bool { return !schedule.OwnedBy(identity) })
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedTime.Before(schedules[j].CreatedTime)
+	})
+	return schedules, nil
+}
+
+// SetPaused pauses or resumes a schedule. Resuming a cron schedule skips the runs
+// missed while paused instead of firing them all at once.
+func SetPaused(ctx context.Context, id string, identity string, paused bool) (jobModel.Schedule, error) {
+	schedule, found := _jobService.ScheduleStore.GetSchedule(ctx, id)
+	if !found || !schedule.OwnedBy(identity) {
+		return schedule, ErrScheduleNotFound
+	}
+	schedule.Paused = paused
+	if !paused && schedule.IsRecurring() {
+		schedule.NextRun = nextRun(schedule, time.Now())
+	}
+	return schedule, _jobService.ScheduleStore.SaveSchedule(ctx, schedule)
+}
+
+// DeleteSchedule a schedule of another identity is not found, the same as a missing one
+func DeleteSchedule(ctx context.Context, id string, identity string) error {
+	if schedule, found := _jobService.ScheduleStore.GetSchedule(ctx, id); !found || !schedule.OwnedBy(identity) {
+		return ErrScheduleNotFound
+	}
+	return _jobService.ScheduleStore.DeleteSchedule(ctx, id)
+}
+
+func validateSchedule(schedule *jobModel.Schedule) error {
+	hasCron := schedule.CronExpression != ""
+	hasRunAt := !schedule.RunAt.IsZero()
+	if hasCron == hasRunAt {
+		return fmt.Errorf("%w: exactly one of run_at or cron is required", ErrInvalidSchedule)
+	}
+	if hasCron {
+		cron, err := parseCron(schedule.CronExpression)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		if cron.Next(time.Now()).IsZero() {
+			return fmt.Errorf("%w: cron expression never fires", ErrInvalidSchedule)
+		}
+	} else if schedule.RunAt.Before(time.Now()) {
+		return fmt.Errorf("%w: run_at is in the past", ErrInvalidSchedule)
+	}
+
+	switch schedule.JobType {
+	case jobModel.JobTypeQuery, jobModel.JobTypeMCP:
+		if strings.TrimSpace(schedule.JobPayload.Question) == "" {
+			return fmt.Errorf("%w: message is required", ErrInvalidSchedule)
+		}
+	case jobModel.JobTypeIngest:
+		if schedule.JobPayload.IngestFileName == "" {
+			return fmt.Errorf("%w: document_name is required", ErrInvalidSchedule)
+		}
+		path, err := resolveIngestSource(schedule.JobPayload.IngestURL)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		schedule.JobPayload.IngestURL = path
+	default:
+		return fmt.Errorf("%w: unknown job type %q", ErrInvalidSchedule, schedule.JobType)
+	}
+	return nil
+}
+
+// resolveIngestSource only allows files inside the scheduled ingest directory
+// so a schedule can't be used to read arbitrary files off the server
+func resolveIngestSource(relativePath string) (string, error) {
+	if relativePath == "" {
+		return "", errors.New("document_path is required")
+	}
+	baseDir := os.Getenv("SCHEDULED_INGEST_DIR")
+	if baseDir == "" {
+		baseDir = config.ScheduledIngestDir
+	}
+	baseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(baseDir, relativePath)
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("document_path must be inside the scheduled ingest directory")
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", fmt.Errorf("document %q not found", relativePath)
+	}
+	return path, nil
+}
+
+func copyToTemporaryData(sourcePath string) (string, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	targetDir := filepath.Join(root, config.TemporaryDataDir)
+	if err = os.MkdirAll(targetDir, 0750); err != nil {
+		return "", err
+	}
+
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	targetPath := filepath.Join(targetDir, fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(sourcePath)))
+	target, err := os.Create(targetPath)
+	if err != nil {
+		return "", err
+	}
+	defer target.Close()
+
+	if _, err = io.Copy(target, source); err != nil {
+		return "", err
+	}
+	return targetPath, nil
+}
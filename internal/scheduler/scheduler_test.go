@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/job"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+func TestSchedules_ScopedToIdentity(t *testing.T) {
+	logger = logger_i.NewLogger("TestScheduler")
+	_jobService = &job.Service{ScheduleStore: store.InitInMemoryJobStore()}
+	ctx := context.Background()
+
+	created := make(map[string]string)
+	for _, identity := range []string{"alice", "bob"} {
+		schedule, err := CreateSchedule(ctx, jobModel.Schedule{
+			JobType:    jobModel.JobTypeQuery,
+			RunAt:      time.Now().Add(time.Hour),
+			JobPayload: jobModel.JobPayload{Question: "pump pressure?"},
+			Identity:   identity,
+		})
+		if err != nil {
+			t.Fatalf("CreateSchedule failed: %v", err)
+		}
+		created[identity] = schedule.Id
+	}
+
+	schedules, err := ListSchedules(ctx, "alice")
+	if err != nil || len(schedules) != 1 || schedules[0].Id != created["alice"] {
+		t.Errorf("Expected only alice's schedule, got %+v %v", schedules, err)
+	}
+	if _, err = SetPaused(ctx, created["bob"], "alice", true); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("Expected another user's schedule not to be found on pause, got %v", err)
+	}
+	if err = DeleteSchedule(ctx, created["bob"], "alice"); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("Expected another user's schedule not to be found on delete, got %v", err)
+	}
+	if paused, err := SetPaused(ctx, created["bob"], "bob", true); err != nil || !paused.Paused {
+		t.Errorf("Expected the owner to pause the schedule, got %+v %v", paused, err)
+	}
+	if err = DeleteSchedule(ctx, created["bob"], "bob"); err != nil {
+		t.Errorf("Expected the owner to delete the schedule, got %v", err)
+	}
+}
@@ -32,10 +32,26 @@ func CreateServer(listenAddr string) {
 	r := utils.GetRouter()
 
 	r.Router.Post("/chat", middleware.ChatHandler)
+	r.Router.Post("/chat/batch", middleware.BatchChatHandler)
+	r.Router.Get("/batch/{id}", middleware.GetBatchHandler)
+	r.Router.Get("/jobs", middleware.ListJobsHandler)
+	r.Router.Post("/jobs/{id}/feedback", middleware.FeedbackHandler)
 	r.Router.Get("/status/{id}", middleware.GetStatusHandler)
+	r.Router.Get("/status/{id}/stream", middleware.StreamStatusHandler)
 	r.Router.Post("/ingest", middleware.PostIngestHandler)
+	r.Router.Get("/chats", middleware.ListChatsHandler)
+	r.Router.Get("/chats/{id}", middleware.GetChatHandler)
+	r.Router.Get("/chats/{id}/export", middleware.ExportChatHandler)
+	r.Router.Patch("/chats/{id}", middleware.RenameChatHandler)
+	r.Router.Delete("/chats/{id}", middleware.DeleteChatHandler)
+	r.Router.Delete("/identities/{identity}", middleware.DeleteIdentityHandler)
 	r.Router.Post("/mcp", middleware.MCPHandler)
 	r.Router.Get("/mcp/status/{id}", middleware.MCPStatusHandler)
+	r.Router.Post("/schedules", middleware.CreateScheduleHandler)
+	r.Router.Get("/schedules", middleware.ListSchedulesHandler)
+	r.Router.Post("/schedules/{id}/pause", middleware.PauseScheduleHandler)
+	r.Router.Post("/schedules/{id}/resume", middleware.ResumeScheduleHandler)
+	r.Router.Delete("/schedules/{id}", middleware.DeleteScheduleHandler)
 	server = &http.Server{
 		Addr:         listenAddr,
 		Handler:      r.Router,
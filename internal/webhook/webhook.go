@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/adapter"
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/metrics"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+var (
+	logger  = logger_i.NewLogger("Webhook")
+	client  = &http.Client{Timeout: config.WebhookRequestTimeout, Transport: publicTransport()}
+	backoff = config.WebhookInitialBackoff
+
+	errPrivateAddress = errors.New("callback address is not public")
+	//shared address space, carrier NAT and some cloud providers' internal networks
+	sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+)
+
+// Notify posts a finished job to its callback url in the background.
+// Every attempt is saved on the job, so it shows up when the job is polled.
+func Notify(store jobModel.JobStore, job jobModel.Job) {
+	if job.CallbackURL == "" {
+		return
+	}
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, job.TraceId)
+	go Deliver(ctx, store, job)
+}
+
+// Deliver blocks until the callback accepts the job, answers with an error that
+// retrying won't fix, or config.WebhookMaxAttempts is reached
+func Deliver(ctx context.Context, store jobModel.JobStore, job jobModel.Job) jobModel.Job {
+	log := logger.With("traceId", ctx.Value(config.TRACE_ID_KEY), "job Id", job.Id)
+	wait := backoff
+
+	for attempt := 1; attempt <= config.WebhookMaxAttempts; attempt++ {
+		delivery, canRetry := post(ctx, job, attempt)
+		job.Deliveries = append(job.Deliveries, delivery)
+		//only the deliveries are written back, the job may have been rated since it finished,
+		//and a job erased meanwhile must not come back
+		if stored, found := store.GetJob(ctx, job.Id); found {
+			stored.Deliveries = job.Deliveries
+			if err := store.SaveJob(ctx, stored); err != nil {
+				log.Error("Failed to save webhook delivery", "err", err)
+			}
+		}
+
+		if delivery.Success {
+			metrics.CaptureWebhookDelivery("success")
+			log.Debug("Webhook delivered", "attempt", attempt)
+			return job
+		}
+		metrics.CaptureWebhookDelivery("failed")
+		log.Warn("Webhook delivery failed", "attempt", attempt, "status", delivery.StatusCode, "error", delivery.Error)
+		if !canRetry || attempt == config.WebhookMaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return job
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	log.Error("Giving up on webhook", "callback url", job.CallbackURL, "attempts", len(job.Deliveries))
+	return job
+}
+
+// Sign returns the signature header value for body, receivers recompute it with the shared secret
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidURL an empty url is valid, it means no callback. Loopback and private hosts are refused unless
+// WEBHOOK_ALLOW_PRIVATE_NETWORKS is set, a name resolving to one is caught when the callback is dialed
+func ValidURL(callbackURL string) bool {
+	if callbackURL == "" {
+		return true
+	}
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return false
+	}
+	if allowPrivateNetworks() {
+		return true
+	}
+	host := parsed.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return publicAddress(ip)
+	}
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+// allowPrivateNetworks WEBHOOK_ALLOW_PRIVATE_NETWORKS=true is for receivers on the service's own network
+func allowPrivateNetworks() bool {
+	allow, _ := strconv.ParseBool(os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS"))
+	return allow
+}
+
+// publicAddress loopback, private, link-local (the cloud metadata service lives at 169.254.169.254),
+// multicast and unspecified addresses are not
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// publicTransport checks every address it dials, after DNS and on every redirect, so a callback can't be
+// pointed at the internal network by a name that resolves into it. There is no proxy, it would do the dialing
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: config.WebhookRequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddress(addrPort.Addr()) && !allowPrivateNetworks() {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+func post(ctx context.Context, job jobModel.Job, attempt int) (jobModel.Delivery, bool) {
+	delivery := jobModel.Delivery{Attempt: attempt, Time: time.Now()}
+
+	body, err := json.Marshal(adapter.ToAPIResponse(job))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, false
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, job.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, false
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if secret := os.Getenv("WEBHOOK_SECRET"); secret != "" {
+		request.Header.Set(config.WebhookSignatureHeader, Sign([]byte(secret), body))
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, !errors.Is(err, errPrivateAddress)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 1<<12))
+
+	delivery.StatusCode = response.StatusCode
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		delivery.Success = true
+		return delivery, false
+	}
+	delivery.Error = response.Status
+	//other 4xx answers mean the receiver rejected the payload, sending it again won't help
+	return delivery, response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusRequestTimeout
+}
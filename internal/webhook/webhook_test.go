@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/store"
+	"github.com/akolanti/GoAPI/internal/domain/jobModel"
+)
+
+func TestDeliver_RetriesUntilAccepted(t *testing.T) {
+	backoff = time.Millisecond
+	t.Setenv("WEBHOOK_SECRET", "shared-secret")
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "true")
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if got := r.Header.Get(config.WebhookSignatureHeader); got != Sign([]byte("shared-secret"), body) {
+			t.Errorf("Signature mismatch, got %q", got)
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	jobStore := store.InitInMemoryJobStore()
+	job := jobModel.Job{Id: "job-1", Status: jobModel.JobStatusComplete, CallbackURL: server.URL}
+	_ = jobStore.SaveJob(context.Background(), job)
+
+	job = Deliver(context.Background(), jobStore, job)
+
+	if calls != 2 {
+		t.Fatalf("Expected 2 calls, got %d", calls)
+	}
+	if len(job.Deliveries) != 2 || job.Deliveries[0].Success || !job.Deliveries[1].Success {
+		t.Errorf("Unexpected deliveries %+v", job.Deliveries)
+	}
+	saved, _ := jobStore.GetJob(context.Background(), "job-1")
+	if len(saved.Deliveries) != 2 {
+		t.Errorf("Delivery attempts were not saved on the job, got %+v", saved.Deliveries)
+	}
+}
+
+func TestDeliver_StopsOnClientError(t *testing.T) {
+	backoff = time.Millisecond
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "true")
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	job := Deliver(context.Background(), store.InitInMemoryJobStore(), jobModel.Job{Id: "job-2", CallbackURL: server.URL})
+
+	if calls != 1 {
+		t.Errorf("A 400 should not be retried, got %d calls", calls)
+	}
+	if len(job.Deliveries) != 1 || job.Deliveries[0].StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected deliveries %+v", job.Deliveries)
+	}
+}
+
+func TestDeliver_KeepsFeedbackGivenMeanwhile(t *testing.T) {
+	backoff = time.Millisecond
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "true")
+	jobStore := store.InitInMemoryJobStore()
+	job := jobModel.Job{Id: "job-3", Status: jobModel.JobStatusComplete}
+	_ = jobStore.SaveJob(context.Background(), job)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//the answer is rated while the callback is being posted
+		rated := job
+		rated.Feedback = &jobModel.Feedback{Rating: jobModel.RatingNegative}
+		_ = jobStore.SaveJob(context.Background(), rated)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	job.CallbackURL = server.URL
+
+	Deliver(context.Background(), jobStore, job)
+
+	saved, _ := jobStore.GetJob(context.Background(), "job-3")
+	if saved.Feedback == nil || len(saved.Deliveries) != 1 {
+		t.Errorf("Expected the delivery saved next to the rating, got %+v %+v", saved.Feedback, saved.Deliveries)
+	}
+}
+
+func TestDeliver_DoesNotRecreateErasedJob(t *testing.T) {
+	backoff = time.Millisecond
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "true")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	//the job was erased before its callback went out
+	jobStore := store.InitInMemoryJobStore()
+	job := Deliver(context.Background(), jobStore, jobModel.Job{Id: "job-4", Status: jobModel.JobStatusComplete, CallbackURL: server.URL})
+
+	if len(job.Deliveries) != 1 || !job.Deliveries[0].Success {
+		t.Errorf("Unexpected deliveries %+v", job.Deliveries)
+	}
+	if _, found := jobStore.GetJob(context.Background(), "job-4"); found {
+		t.Error("Expected the erased job to stay erased after its delivery")
+	}
+}
+
+func TestDeliver_RefusesPrivateAddresses(t *testing.T) {
+	backoff = time.Millisecond
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	job := Deliver(context.Background(), store.InitInMemoryJobStore(), jobModel.Job{Id: "job-5", CallbackURL: server.URL})
+
+	if calls != 0 {
+		t.Errorf("Expected the loopback callback never to be called, got %d calls", calls)
+	}
+	if len(job.Deliveries) != 1 || job.Deliveries[0].Success {
+		t.Errorf("Expected one refused delivery and no retry, got %+v", job.Deliveries)
+	}
+}
+
+func TestValidURL(t *testing.T) {
+	cases := map[string]bool{
+		"":                                 true,
+		"https://example.com/hook":         true,
+		"https://93.184.215.14/hook":       true,
+		"http://10.0.0.4:8080/jobs":        false,
+		"http://127.0.0.1:8080/jobs":       false,
+		"http://localhost:8080/jobs":       false,
+		"http://169.254.169.254/latest":    false,
+		"http://[::1]/hook":                false,
+		"http://[::ffff:192.168.1.1]/hook": false,
+		"ftp://example.com/hook":           false,
+		"example.com/hook":                 false,
+		"https://":                         false,
+	}
+	for callbackURL, want := range cases {
+		if got := ValidURL(callbackURL); got != want {
+			t.Errorf("ValidURL(%q) got %v, want %v", callbackURL, got, want)
+		}
+	}
+
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "true")
+	if !ValidURL("http://10.0.0.4:8080/jobs") {
+		t.Error("Expected a private callback allowed with WEBHOOK_ALLOW_PRIVATE_NETWORKS")
+	}
+}
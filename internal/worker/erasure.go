@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	jobmodel "github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/pkg/logger_i"
+)
+
+// eraseData removes the chat, or every chat of the identity, together with their jobs and cached answers.
+// Only what was actually found is reported, so running the same erasure twice is harmless.
+func eraseData(job jobmodel.Job, ctx context.Context, logger *logger_i.Logger) jobmodel.Job {
+	payload := job.JobPayload
+	report := jobmodel.ErasureReport{ChatIds: []string{}, JobIds: []string{}}
+
+	job.StartStep(jobmodel.EraseJobs)
+	saveJobState(ctx, job, jobmodel.JobStatusRunning)
+	jobs, err := jobsToErase(ctx, job.Identity, payload)
+	if err != nil {
+		return erasureError(job, err, logger)
+	}
+
+	var chatIds []string
+	if payload.EraseChatId != "" {
+		chatIds = append(chatIds, payload.EraseChatId)
+	}
+	if payload.EraseIdentity != "" {
+		//chats outlive their jobs, the chat index still knows the ones whose jobs have expired
+		owned, err := listAllChats(ctx, payload.EraseIdentity)
+		if err != nil {
+			return erasureError(job, err, logger)
+		}
+		chatIds = append(chatIds, owned...)
+	}
+	for _, erased := range jobs {
+		if erased.ChatId != "" && !slices.Contains(chatIds, erased.ChatId) {
+			chatIds = append(chatIds, erased.ChatId)
+		}
+		//erasure records are the audit trail, they only hold ids
+		if erased.JobType == jobmodel.JobTypeErasure {
+			continue
+		}
+		_jobService.JobStore.DeleteJob(ctx, erased.Id)
+		if _jobService.EventStore != nil {
+			if err = _jobService.EventStore.DeleteEvents(ctx, erased.Id); err != nil {
+				return erasureError(job, err, logger)
+			}
+		}
+		report.JobIds = append(report.JobIds, erased.Id)
+	}
+
+	job.StartStep(jobmodel.EraseChats)
+	saveJobState(ctx, job, jobmodel.JobStatusRunning)
+	for _, chatId := range chatIds {
+		removed, err := _jobService.MessageStore.DeleteChat(ctx, chatId)
+		if err != nil {
+			return erasureError(job, err, logger)
+		}
+		if removed {
+			report.ChatIds = append(report.ChatIds, chatId)
+		}
+	}
+
+	job.StartStep(jobmodel.EraseCache)
+	saveJobState(ctx, job, jobmodel.JobStatusRunning)
+	report.CacheEntries, err = _ragService.EraseCachedAnswers(ctx, chatIds, payload.EraseIdentity)
+	if err != nil {
+		return erasureError(job, err, logger)
+	}
+
+	job.EndStep(jobmodel.StepOutcomeSuccess)
+	job.CurrentStep = jobmodel.Complete
+	job.JobPayload.Erasure = &report
+	logger.Info("Erasure finished", "job Id", job.Id, "jobs", len(report.JobIds), "chats", len(report.ChatIds), "cache entries", report.CacheEntries)
+	return job
+}
+
+// jobsToErase every job of the chat, or every job of the identity and of the chats those jobs belong to.
+// Chats started before they had an owner are shared, only the jobs the requester owns are taken from them,
+// jobs saved before they had an owner are kept as nobody can prove they are the requester's
+func jobsToErase(ctx context.Context, requester string, payload jobmodel.JobPayload) ([]jobmodel.Job, error) {
+	if payload.EraseChatId != "" {
+		chatJobs, err := listAllJobs(ctx, jobmodel.JobQuery{ChatId: payload.EraseChatId})
+		if err != nil {
+			return nil, err
+		}
+		return slices.DeleteFunc(chatJobs, func(chatJob jobmodel.Job) bool { return chatJob.Identity != requester }), nil
+	}
+
+	jobs, err := listAllJobs(ctx, jobmodel.JobQuery{Identity: payload.EraseIdentity})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(jobs))
+	for _, found := range jobs {
+		seen[found.Id] = true
+	}
+	for _, found := range slices.Clone(jobs) {
+		if found.ChatId == "" {
+			continue
+		}
+		chatJobs, err := listAllJobs(ctx, jobmodel.JobQuery{ChatId: found.ChatId})
+		if err != nil {
+			return nil, err
+		}
+		for _, chatJob := range chatJobs {
+			if !seen[chatJob.Id] && chatJob.Identity == requester {
+				seen[chatJob.Id] = true
+				jobs = append(jobs, chatJob)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func listAllJobs(ctx context.Context, query jobmodel.JobQuery) ([]jobmodel.Job, error) {
+	query.Limit = config.JobListMaxLimit
+	var jobs []jobmodel.Job
+	for {
+		page, err := _jobService.JobStore.ListJobs(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, page.Jobs...)
+		if page.NextCursor == "" {
+			return jobs, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+func listAllChats(ctx context.Context, identity string) ([]string, error) {
+	query := jobmodel.ChatQuery{Identity: identity, Limit: config.JobListMaxLimit}
+	var chatIds []string
+	for {
+		page, err := _jobService.MessageStore.ListChats(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, chat := range page.Chats {
+			chatIds = append(chatIds, chat.Id)
+		}
+		if page.NextCursor == "" {
+			return chatIds, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+func erasureError(job jobmodel.Job, err error, logger *logger_i.Logger) jobmodel.Job {
+	logger.Error("ERASURE_FAILURE", "job Id", job.Id, "error", err)
+	job.EndStep(jobmodel.StepOutcomeFailed)
+	job.CurrentStep = jobmodel.Error
+	job.Status = jobmodel.JobStatusError
+	job.Error = jobmodel.JobError{
+		Code:    http.StatusInternalServerError,
+		Message: "Erasure did not finish, retry it to remove the rest",
+		Retry:   true,
+	}
+	return job
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/adapter"
+	"github.com/akolanti/GoAPI/internal/config"
+	jobmodel "github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/metrics"
+	"github.com/akolanti/GoAPI/internal/webhook"
+)
+
+// runJob executes the job and reports whether it panicked, a panic fails the job instead of the process
+func runJob(job jobmodel.Job) (panicked bool) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicked = true
+			metrics.CapturePanic("worker")
+			logger.Error("JOB_PANIC", "job Id", job.Id, "panic", recovered, "stack", string(debug.Stack()))
+			failJob(job, jobmodel.JobError{Code: http.StatusInternalServerError, Message: "Internal Server Error", Retry: true})
+		}
+	}()
+	executeJob(job)
+	return false
+}
+
+// failJob saves the job as failed, starting from the last state the worker saved so the timeline shows where it stopped
+func failJob(job jobmodel.Job, jobError jobmodel.JobError) {
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, job.TraceId)
+	if saved, found := _jobService.JobStore.GetJob(ctx, job.Id); found {
+		job = saved
+	}
+	job.EndStep(jobmodel.StepOutcomeFailed)
+	job.CurrentStep = jobmodel.Error
+	job.EndTime = time.Now()
+	job.Error = jobError
+	saveJobState(ctx, job, jobmodel.JobStatusError)
+	_jobService.SyncBatch(ctx, job)
+	appendEvent(ctx, job.Id, jobmodel.StreamEventDone, adapter.ToAPIResponse(job))
+	webhook.Notify(_jobService.JobStore, job)
+}
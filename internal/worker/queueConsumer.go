@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	jobmodel "github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/metrics"
+)
+
+// ConsumeQueue feeds jobs from the shared queue into the worker pool until ctx is done.
+// A job is only taken when the job channel has room, so this process never holds a job it can't start.
+func ConsumeQueue(ctx context.Context, queue jobmodel.JobQueue) {
+	logger.Info("Consuming the shared job queue")
+	for ctx.Err() == nil {
+		if len(_jobService.JobChannel) >= cap(_jobService.JobChannel) {
+			wait(ctx, 100*time.Millisecond)
+			continue
+		}
+
+		job, found, err := queue.Dequeue(ctx, config.JobQueuePollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.Warn("Could not read the job queue, retrying", "err", err)
+			wait(ctx, time.Second)
+			continue
+		}
+		if found {
+			logger.Debug("Took job from the queue", "job Id", job.Id)
+			_jobService.Dispatch(job)
+		}
+	}
+	logger.Info("Stopped consuming the shared job queue")
+}
+
+// RequeueUnstarted gives the jobs still waiting in the job channel back to the queue,
+// call it after the workers have stopped so another worker process picks them up
+func RequeueUnstarted(ctx context.Context, queue jobmodel.JobQueue) {
+	var unstarted []jobmodel.Job
+	for drained := false; !drained; {
+		select {
+		case job := <-_jobService.JobChannel:
+			metrics.DecrementJobsInQueue()
+			unstarted = append(unstarted, job)
+		default:
+			drained = true
+		}
+	}
+	//pushed to the front newest first, so they come out in their original order
+	for i := len(unstarted) - 1; i >= 0; i-- {
+		if err := queue.Requeue(ctx, unstarted[i]); err != nil {
+			logger.Error("Lost unstarted job on shutdown", "job Id", unstarted[i].Id, "err", err)
+			continue
+		}
+		logger.Info("Requeued unstarted job", "job Id", unstarted[i].Id)
+	}
+}
+
+// KeepQueueAlive beats for this process until ctx is done and takes over the jobs of processes that stopped beating.
+// It runs until the process exits, so the jobs still running during a shutdown aren't reaped from under it
+func KeepQueueAlive(ctx context.Context, queue jobmodel.JobQueue) {
+	ticker := time.NewTicker(config.JobQueueHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		if err := queue.Heartbeat(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Could not send the job queue heartbeat", "err", err)
+		}
+		reaped, err := queue.Reap(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Could not reap the jobs of stopped worker processes", "err", err)
+		}
+		for _, job := range reaped {
+			takeOver(ctx, queue, job)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// takeOver settles a job a stopped process held. One it never started goes back to the queue, one it was
+// running fails, as it may have stopped halfway through
+func takeOver(ctx context.Context, queue jobmodel.JobQueue, job jobmodel.Job) {
+	ctx = context.WithValue(ctx, config.TRACE_ID_KEY, job.TraceId)
+	saved, found := _jobService.JobStore.GetJob(ctx, job.Id)
+	switch {
+	case found && saved.Status == jobmodel.JobStatusQueued:
+		if err := queue.Requeue(ctx, job); err != nil {
+			logger.Error("Could not requeue a reaped job", "job Id", job.Id, "err", err)
+			return
+		}
+		logger.Info("Requeued the unstarted job of a stopped worker process", "job Id", job.Id)
+		return
+	case found && !saved.IsFinished():
+		logger.Warn("Failing the job a stopped worker process was running", "job Id", job.Id)
+		failJob(saved, jobmodel.JobError{Code: http.StatusInternalServerError, Message: "The worker running the job stopped", Retry: true})
+	}
+	ackJob(job)
+}
+
+// ackJob releases a job taken from the shared queue, jobs that came straight through the job channel aren't held
+func ackJob(job jobmodel.Job) {
+	if _jobService.JobQueue == nil {
+		return
+	}
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, job.TraceId)
+	if err := _jobService.JobQueue.Ack(ctx, job); err != nil {
+		logger.Warn("Could not ack a finished job, it may be reaped and settled again", "job Id", job.Id, "err", err)
+	}
+}
+
+func wait(ctx context.Context, duration time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(duration):
+	}
+}
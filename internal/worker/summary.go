@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/akolanti/GoAPI/internal/config"
+	jobmodel "github.com/akolanti/GoAPI/internal/domain/jobModel"
+	"github.com/akolanti/GoAPI/internal/llm"
+	"github.com/akolanti/GoAPI/internal/metrics"
+)
+
+// chats with a summary being written by this process
+var summarising sync.Map
+
+// refreshSummary folds the turns that have dropped out of the history window into the chat's summary,
+// once at least config.ChatSummaryMinTurns of them are not covered yet. It runs after the job is
+// finished, so it never holds up an answer, and a failure only means the next question tries again.
+func refreshSummary(chatId string, traceId string) {
+	if _, busy := summarising.LoadOrStore(chatId, true); busy {
+		return
+	}
+	defer summarising.Delete(chatId)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			metrics.CapturePanic("summary")
+			logger.Error("SUMMARY_PANIC", "chat Id", chatId, "panic", recovered, "stack", string(debug.Stack()))
+		}
+	}()
+
+	ctxTrace := context.WithValue(context.Background(), config.TRACE_ID_KEY, traceId)
+	ctx, cancel := context.WithTimeout(ctxTrace, config.ChatSummaryTimeout)
+	defer cancel()
+	messageStore := _jobService.MessageStore
+
+	turns, err := messageStore.GetTurns(ctx, chatId)
+	if err != nil {
+		logger.Warn("Could not read the chat to summarise", "chat Id", chatId, "err", err)
+		return
+	}
+	err, recent := messageStore.GetMessageHistory(ctx, chatId)
+	if err != nil {
+		return
+	}
+	summary, _, err := messageStore.GetSummary(ctx, chatId)
+	if err != nil {
+		logger.Warn("Could not read the chat summary", "chat Id", chatId, "err", err)
+		return
+	}
+
+	//recent is the tail of turns, a turn is as many messages as it took
+	starts := turnStarts(turns)
+	older := len(starts) - len(turnStarts(recent))
+	covered := min(summary.Turns, older)
+	if older-covered < config.ChatSummaryMinTurns {
+		return
+	}
+	end := len(turns)
+	if older < len(starts) {
+		end = starts[older]
+	}
+	text, err := _ragService.SummariseChat(ctx, summary.Text, turns[starts[covered]:end])
+	if err != nil {
+		logger.Warn("Failed to summarise chat", "chat Id", chatId, "err", err)
+		return
+	}
+	//the store refuses the summary of a chat erased meanwhile
+	err = messageStore.SaveSummary(ctx, chatId, jobmodel.ChatSummary{Text: text, Turns: older, UpdatedTime: time.Now()})
+	if errors.Is(err, jobmodel.ErrChatNotFound) {
+		logger.Debug("Chat is gone, dropping its summary", "chat Id", chatId)
+		return
+	} else if err != nil {
+		logger.Warn("Failed to save chat summary", "chat Id", chatId, "err", err)
+		return
+	}
+	logger.Debug("Chat summary refreshed", "chat Id", chatId, "turns", older)
+}
+
+// turnStarts the index of every question in messages. Tool results go back to the LLM as user messages,
+// they are part of the turn and don't start one
+func turnStarts(messages []llm.Message) []int {
+	var starts []int
+	for i, message := range messages {
+		if message.Role == llm.RoleUser && !isToolResult(message) {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+func isToolResult(message llm.Message) bool {
+	for _, block := range message.Content {
+		if block.Type == llm.ContentBlockTypeToolResult {
+			return true
+		}
+	}
+	return false
+}
@@ -60,8 +60,16 @@ func worker() {
 	for {
 		select {
 		case currentJob := <-_jobService.JobChannel:
-			executeJob(currentJob)
+			panicked := runJob(currentJob)
+			ackJob(currentJob)
 			metrics.DecrementJobsInQueue()
+			if panicked {
+				//whatever panicked may have left this goroutine's state broken, a fresh worker takes over.
+				//it is added before this one is done so the wait group never drops to zero in between
+				createWorker()
+				removeWorker("Recovered from a panic")
+				return
+			}
 
 		case <-stopWorkerChannel:
 			removeWorker("Stop worker signal received")
@@ -2,12 +2,19 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/akolanti/GoAPI/internal/adapter"
 	"github.com/akolanti/GoAPI/internal/config"
 	jobmodel "github.com/akolanti/GoAPI/internal/domain/jobModel"
+	jobs "github.com/akolanti/GoAPI/internal/job"
 	"github.com/akolanti/GoAPI/internal/metrics"
+	"github.com/akolanti/GoAPI/internal/webhook"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
@@ -17,33 +24,59 @@ func executeJob(job jobmodel.Job) {
 		// Record total time at the end
 		metrics.CaptureJobMetrics(string(job.Status), time.Since(start))
 	}()
-	ctxTrace := context.WithValue(context.Background(), config.TRACE_ID_KEY, job.TraceId)
-	ctx, cancel := context.WithTimeout(ctxTrace, 60*time.Second)
+	ctx, cancel := jobContext(job)
 	defer cancel()
+	//state is still saved after the job ran out of time
+	saveCtx := context.WithoutCancel(ctx)
 	logger.With("trace Id ", job.TraceId)
 	logger.Debug("Processing job:", "job Id:", job.Id)
 
-	saveJobState(ctx, job, jobmodel.JobStatusRunning)
+	job.Status = jobmodel.JobStatusRunning
+	if ctx.Err() != nil {
+		//the client's deadline passed while the job was queued
+		job.Status = jobmodel.JobStatusError
 
-	if job.JobType == jobmodel.JobTypeIngest {
-		job.CurrentStep = jobmodel.IngestProcessing
+	} else if job.JobType == jobmodel.JobTypeIngest {
+		job.StartStep(jobmodel.IngestProcessing)
+		saveJobState(ctx, job, jobmodel.JobStatusRunning)
 		job = ingestDocument(job, ctx, logger)
 
+	} else if job.JobType == jobmodel.JobTypeErasure {
+		job = eraseData(job, ctx, logger)
+
 	} else {
-		job.CurrentStep = jobmodel.RedisCall
+		job.StartStep(jobmodel.RedisCall)
+		saveJobState(ctx, job, jobmodel.JobStatusRunning)
+		_jobService.SyncBatch(ctx, job)
 		job = processQuery(job, ctx, logger)
 		if job.Status != jobmodel.JobStatusError {
-			if err := _jobService.MessageStore.TrySaveChat(ctx, job.ChatId, job.JobPayload); err != nil {
+			if err := _jobService.MessageStore.TrySaveChat(saveCtx, job.ChatId, job.JobPayload); err != nil {
 				logger.Error("Failed to save chat history", "err", err)
+			} else {
+				go refreshSummary(job.ChatId, job.TraceId)
 			}
 		}
 	}
 
+	if job.Status == jobmodel.JobStatusError && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logger.Warn("Job timed out", "job Id", job.Id, "job type", job.JobType)
+		job = jobs.TimedOut(job)
+	}
+
 	job.EndTime = time.Now()
 	if job.Status != jobmodel.JobStatusError {
 		job.Status = jobmodel.JobStatusComplete
 	}
-	saveJobState(ctx, job, job.Status)
+	saveJobState(saveCtx, job, job.Status)
+	_jobService.SyncBatch(saveCtx, job)
+	appendEvent(saveCtx, job.Id, jobmodel.StreamEventDone, adapter.ToAPIResponse(job))
+	webhook.Notify(_jobService.JobStore, job)
+}
+
+// jobContext carries the trace id and ends at the job's timeout or deadline
+func jobContext(job jobmodel.Job) (context.Context, context.CancelFunc) {
+	ctxTrace := context.WithValue(context.Background(), config.TRACE_ID_KEY, job.TraceId)
+	return jobs.WithJobDeadline(ctxTrace, job)
 }
 
 func removeWorker(reason string) {
@@ -56,8 +89,8 @@ func removeWorker(reason string) {
 }
 
 func ingestDocument(job jobmodel.Job, ctx context.Context, logger *logger_i.Logger) jobmodel.Job {
-	t := _ragService.IngestDocument(ctx, job)
-	print(t.CurrentStep)
+	job = _ragService.IngestDocument(ctx, job)
+	logger.Debug("Ingestion finished", "job Id", job.Id, "status", job.Status)
 	return job
 }
 
@@ -66,13 +99,104 @@ func processQuery(job jobmodel.Job, ctx context.Context, logger *logger_i.Logger
 	if err != nil {
 		logger.Error("Failed to get message history", "err", err)
 	}
-	job = _ragService.ProcessRequest(ctx, job, messageHistory)
+	summary, _, err := _jobService.MessageStore.GetSummary(ctx, job.ChatId)
+	if err != nil {
+		logger.Error("Failed to get chat summary", "err", err)
+	}
+	job = _ragService.ProcessRequest(ctx, job, messageHistory, summary.Text)
 	return job
 }
 
+// ReportProgress persists a job that is still running, so status polls can see the step it is on
+func ReportProgress(ctx context.Context, job jobmodel.Job) {
+	saveJobState(ctx, job, jobmodel.JobStatusRunning)
+}
+
+// deltaBuffers job id -> *deltaBuffer, the answer text of a running job not yet in its event log
+var deltaBuffers sync.Map
+
+// deltaBuffer mu is held while the text is appended, so flushes from the timer and the generation keep their order
+type deltaBuffer struct {
+	mu      sync.Mutex
+	pending strings.Builder
+	timer   *time.Timer
+}
+
+// ReportDelta adds a chunk of the answer to the job's event log for SSE clients, the job store only
+// gets the full answer once the job is done. Chunks are gathered, see config.StreamDeltaFlushBytes
+func ReportDelta(ctx context.Context, jobId string, delta string) {
+	if _jobService.EventStore == nil {
+		return
+	}
+	value, _ := deltaBuffers.LoadOrStore(jobId, &deltaBuffer{})
+	buffer := value.(*deltaBuffer)
+	buffer.mu.Lock()
+	defer buffer.mu.Unlock()
+
+	buffer.pending.WriteString(delta)
+	if buffer.pending.Len() >= config.StreamDeltaFlushBytes {
+		buffer.flush(ctx, jobId)
+		return
+	}
+	if buffer.timer == nil {
+		flushCtx := context.WithoutCancel(ctx)
+		buffer.timer = time.AfterFunc(config.StreamDeltaFlushInterval, func() { flushDeltas(flushCtx, jobId) })
+	}
+}
+
+// flushDeltas appends whatever answer text of the job is still buffered
+func flushDeltas(ctx context.Context, jobId string) {
+	value, found := deltaBuffers.Load(jobId)
+	if !found {
+		return
+	}
+	buffer := value.(*deltaBuffer)
+	buffer.mu.Lock()
+	defer buffer.mu.Unlock()
+	buffer.flush(ctx, jobId)
+}
+
+// flush call it holding mu
+func (b *deltaBuffer) flush(ctx context.Context, jobId string) {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.pending.Len() == 0 {
+		return
+	}
+	delta := b.pending.String()
+	b.pending.Reset()
+	err := _jobService.EventStore.AppendEvent(ctx, jobId, jobmodel.StreamEvent{Type: jobmodel.StreamEventDelta, Data: delta})
+	if err != nil {
+		logger.Warn("Failed to append answer delta", "job Id", jobId, "err", err)
+	}
+}
+
 func saveJobState(ctx context.Context, job jobmodel.Job, jobStatus jobmodel.JobStatus) {
 	job.Status = jobStatus
 	if err := _jobService.JobStore.SaveJob(ctx, job); err != nil {
 		logger.Error("Failed to update status in Redis", "err", err)
 	}
+	appendEvent(ctx, job.Id, jobmodel.StreamEventStep, adapter.ToStreamStep(job))
+}
+
+func appendEvent(ctx context.Context, jobId string, eventType jobmodel.StreamEventType, data any) {
+	if _jobService.EventStore == nil {
+		return
+	}
+	//the answer so far comes before the step or the end of the job
+	flushDeltas(ctx, jobId)
+	if eventType == jobmodel.StreamEventDone {
+		deltaBuffers.Delete(jobId)
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("Failed to marshal job event", "err", err)
+		return
+	}
+	err = _jobService.EventStore.AppendEvent(ctx, jobId, jobmodel.StreamEvent{Type: eventType, Data: string(payload)})
+	if err != nil {
+		logger.Warn("Failed to append job event", "job Id", jobId, "err", err)
+	}
 }
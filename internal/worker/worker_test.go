@@ -2,14 +2,18 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/akolanti/GoAPI/internal/config"
+	"github.com/akolanti/GoAPI/internal/data/store"
 	"github.com/akolanti/GoAPI/internal/domain/jobModel"
 	"github.com/akolanti/GoAPI/internal/job"
+	"github.com/akolanti/GoAPI/internal/llm"
 	"github.com/akolanti/GoAPI/pkg/logger_i"
 )
 
@@ -18,7 +22,7 @@ type MockRagService struct {
 	ProcessedCount int32
 }
 
-func (m *MockRagService) ProcessRequest(ctx context.Context, j jobModel.Job, hist []string) jobModel.Job {
+func (m *MockRagService) ProcessRequest(ctx context.Context, j jobModel.Job, hist []llm.Message, summary string) jobModel.Job {
 	atomic.AddInt32(&m.ProcessedCount, 1)
 	return j
 }
@@ -28,6 +32,14 @@ func (m *MockRagService) IngestDocument(ctx context.Context, j jobModel.Job) job
 	return j
 }
 
+func (m *MockRagService) EraseCachedAnswers(ctx context.Context, chatIds []string, identity string) (int, error) {
+	return 0, nil
+}
+
+func (m *MockRagService) SummariseChat(ctx context.Context, previous string, turns []llm.Message) (string, error) {
+	return "", nil
+}
+
 type MockJobStore struct {
 	OnSaveJob func(ctx context.Context, job jobModel.Job) error
 }
@@ -42,6 +54,11 @@ func (m *MockJobStore) DeleteJob(ctx context.Context, jobID string) {
 	panic("implement me")
 }
 
+func (m *MockJobStore) ListJobs(ctx context.Context, query jobModel.JobQuery) (jobModel.JobPage, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
 func (m *MockJobStore) SaveJob(ctx context.Context, j jobModel.Job) error {
 	if m.OnSaveJob != nil {
 		return m.OnSaveJob(ctx, j)
@@ -51,7 +68,7 @@ func (m *MockJobStore) SaveJob(ctx context.Context, j jobModel.Job) error {
 
 // MockMessageStore handles chat history
 type MockMessageStore struct {
-	OnGetHistory func(ctx context.Context, chatId string) (error, []string)
+	OnGetHistory func(ctx context.Context, chatId string) (error, []llm.Message)
 	OnSaveChat   func(ctx context.Context, chatId string, payload jobModel.JobPayload) error
 }
 
@@ -59,16 +76,52 @@ func (m *MockMessageStore) ValidateChatId(ctx context.Context, id string) bool {
 	return true
 }
 
-func (m *MockMessageStore) InitNewChat(ctx context.Context, id string) error {
+func (m *MockMessageStore) InitNewChat(ctx context.Context, id string, identity string) error {
+	return nil
+}
+
+func (m *MockMessageStore) GetChat(ctx context.Context, chatId string) (jobModel.ChatInfo, bool, error) {
+	return jobModel.ChatInfo{Id: chatId}, true, nil
+}
+
+func (m *MockMessageStore) ListChats(ctx context.Context, query jobModel.ChatQuery) (jobModel.ChatPage, error) {
+	return jobModel.ChatPage{}, nil
+}
+
+func (m *MockMessageStore) GetTranscript(ctx context.Context, chatId string) ([]jobModel.ChatEntry, error) {
+	return nil, nil
+}
+
+func (m *MockMessageStore) RenameChat(ctx context.Context, chatId string, title string) error {
 	return nil
 }
 
-func (m *MockMessageStore) GetMessageHistory(ctx context.Context, id string) (error, []string) {
+func (m *MockMessageStore) GetMessageHistory(ctx context.Context, id string) (error, []llm.Message) {
 	if m.OnGetHistory != nil {
 		return m.OnGetHistory(ctx, id)
 	}
-	return nil, []string{}
+	return nil, []llm.Message{}
 }
+func (m *MockMessageStore) GetTurns(ctx context.Context, chatId string) ([]llm.Message, error) {
+	return nil, nil
+}
+
+func (m *MockMessageStore) GetToolHistory(ctx context.Context, chatId string) ([]llm.Message, error) {
+	return nil, nil
+}
+
+func (m *MockMessageStore) GetSummary(ctx context.Context, chatId string) (jobModel.ChatSummary, bool, error) {
+	return jobModel.ChatSummary{}, false, nil
+}
+
+func (m *MockMessageStore) SaveSummary(ctx context.Context, chatId string, summary jobModel.ChatSummary) error {
+	return nil
+}
+
+func (m *MockMessageStore) DeleteChat(ctx context.Context, chatId string) (bool, error) {
+	return false, nil
+}
+
 func (m *MockMessageStore) TrySaveChat(ctx context.Context, id string, p jobModel.JobPayload) error {
 	if m.OnSaveChat != nil {
 		return m.OnSaveChat(ctx, id, p)
@@ -165,3 +218,316 @@ func TestWorker_IdleTimeout(t *testing.T) {
 		t.Errorf("Assertion Failed: Worker should have timed out and retired, but count is %d", count)
 	}
 }
+
+func TestReportDelta_GathersChunksBeforeTheNextStep(t *testing.T) {
+	logger = logger_i.NewLogger("TestDeltas")
+	jobStore := store.InitInMemoryJobStore()
+	InitServices(&job.Service{JobStore: jobStore, EventStore: jobStore}, &MockRagService{})
+	ctx := context.Background()
+
+	for _, delta := range []string{"The pump ", "runs at ", "4 bar."} {
+		ReportDelta(ctx, "job-1", delta)
+	}
+	appendEvent(ctx, "job-1", jobModel.StreamEventDone, "done")
+
+	events, _ := jobStore.ReadEvents(ctx, "job-1", "")
+	if len(events) != 2 || events[0].Type != jobModel.StreamEventDelta || events[0].Data != "The pump runs at 4 bar." || events[1].Type != jobModel.StreamEventDone {
+		t.Errorf("Expected the chunks as one delta before done, got %+v", events)
+	}
+
+	//a pause in the generation still reaches the client
+	ReportDelta(ctx, "job-2", "The pump")
+	time.Sleep(3 * config.StreamDeltaFlushInterval)
+	events, _ = jobStore.ReadEvents(ctx, "job-2", "")
+	if len(events) != 1 || events[0].Data != "The pump" {
+		t.Errorf("Expected the chunk appended after the flush interval, got %+v", events)
+	}
+
+	long := strings.Repeat("x", config.StreamDeltaFlushBytes)
+	ReportDelta(ctx, "job-3", long)
+	events, _ = jobStore.ReadEvents(ctx, "job-3", "")
+	if len(events) != 1 || events[0].Data != long {
+		t.Errorf("Expected a full buffer appended straight away, got %+v", events)
+	}
+}
+
+func TestEraseData_IdentityIsIdempotent(t *testing.T) {
+	logger = logger_i.NewLogger("TestErasure")
+	jobStore := store.InitInMemoryJobStore()
+	messageStore := store.InitMessageStore()
+	InitServices(&job.Service{JobStore: jobStore, MessageStore: messageStore, EventStore: jobStore}, &MockRagService{})
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "erasure-trace")
+
+	_ = messageStore.InitNewChat(ctx, "chat-alice", "")
+	_ = messageStore.InitNewChat(ctx, "chat-bob", "")
+	for _, saved := range []jobModel.Job{
+		{Id: "alice-1", ChatId: "chat-alice", Identity: "alice", JobType: jobModel.JobTypeQuery, CreatedTime: time.Now()},
+		{Id: "alice-2", ChatId: "chat-alice", Identity: "alice", JobType: jobModel.JobTypeQuery, CreatedTime: time.Now()},
+		{Id: "bob-1", ChatId: "chat-bob", Identity: "bob", JobType: jobModel.JobTypeQuery, CreatedTime: time.Now()},
+	} {
+		_ = jobStore.SaveJob(ctx, saved)
+	}
+
+	erasure := jobModel.Job{Id: "erase-1", Identity: "alice", JobType: jobModel.JobTypeErasure, JobPayload: jobModel.JobPayload{EraseIdentity: "alice"}}
+	first := eraseData(erasure, ctx, logger)
+	if first.Status == jobModel.JobStatusError || first.JobPayload.Erasure == nil {
+		t.Fatalf("Erasure failed: %+v", first)
+	}
+	if len(first.JobPayload.Erasure.JobIds) != 2 || len(first.JobPayload.Erasure.ChatIds) != 1 {
+		t.Errorf("Unexpected report %+v", first.JobPayload.Erasure)
+	}
+	if _, found := jobStore.GetJob(ctx, "alice-1"); found {
+		t.Error("Job of the erased identity is still stored")
+	}
+	if messageStore.ValidateChatId(ctx, "chat-alice") {
+		t.Error("Chat of the erased identity still exists")
+	}
+	if _, found := jobStore.GetJob(ctx, "bob-1"); !found || !messageStore.ValidateChatId(ctx, "chat-bob") {
+		t.Error("Another identity's data was erased")
+	}
+
+	second := eraseData(erasure, ctx, logger)
+	if second.Status == jobModel.JobStatusError || len(second.JobPayload.Erasure.JobIds) != 0 || len(second.JobPayload.Erasure.ChatIds) != 0 {
+		t.Errorf("Repeated erasure should succeed with nothing removed, got %+v", second.JobPayload.Erasure)
+	}
+}
+
+func TestEraseData_SharedChatKeepsOtherUsersJobs(t *testing.T) {
+	logger = logger_i.NewLogger("TestErasure")
+	jobStore := store.InitInMemoryJobStore()
+	messageStore := store.InitMessageStore()
+	InitServices(&job.Service{JobStore: jobStore, MessageStore: messageStore, EventStore: jobStore}, &MockRagService{})
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "erasure-trace")
+
+	//a chat started before chats had an owner, both users asked in it
+	_ = messageStore.InitNewChat(ctx, "chat-shared", "")
+	for _, saved := range []jobModel.Job{
+		{Id: "alice-1", ChatId: "chat-shared", Identity: "alice", JobType: jobModel.JobTypeQuery, CreatedTime: time.Now()},
+		{Id: "bob-1", ChatId: "chat-shared", Identity: "bob", JobType: jobModel.JobTypeQuery, CreatedTime: time.Now()},
+		{Id: "legacy-1", ChatId: "chat-shared", JobType: jobModel.JobTypeQuery, CreatedTime: time.Now()},
+	} {
+		_ = jobStore.SaveJob(ctx, saved)
+	}
+
+	erasure := jobModel.Job{Id: "erase-1", Identity: "alice", JobType: jobModel.JobTypeErasure, JobPayload: jobModel.JobPayload{EraseChatId: "chat-shared"}}
+	erased := eraseData(erasure, ctx, logger)
+	if erased.Status == jobModel.JobStatusError || erased.JobPayload.Erasure == nil {
+		t.Fatalf("Erasure failed: %+v", erased)
+	}
+	if _, found := jobStore.GetJob(ctx, "alice-1"); found {
+		t.Error("Job of the requester is still stored")
+	}
+	if _, found := jobStore.GetJob(ctx, "bob-1"); !found {
+		t.Error("Job of another user was erased with the chat")
+	}
+	if _, found := jobStore.GetJob(ctx, "legacy-1"); !found {
+		t.Error("Job saved before jobs had an owner was erased with the chat")
+	}
+}
+
+// slowRagService fails the way the real service does once the context runs out
+type slowRagService struct {
+	MockRagService
+}
+
+func (m *slowRagService) ProcessRequest(ctx context.Context, j jobModel.Job, hist []llm.Message, summary string) jobModel.Job {
+	atomic.AddInt32(&m.ProcessedCount, 1)
+	<-ctx.Done()
+	j.Status = jobModel.JobStatusError
+	j.Error = jobModel.JobError{Code: 500, Message: "Internal Server Error", Retry: true}
+	return j
+}
+
+func TestExecuteJob_DeadlineFailsWithTimeout(t *testing.T) {
+	logger = logger_i.NewLogger("TestTimeout")
+	jobStore := store.InitInMemoryJobStore()
+	ragService := &slowRagService{}
+	InitServices(&job.Service{JobStore: jobStore, MessageStore: store.InitMessageStore()}, ragService)
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "timeout-trace")
+
+	executeJob(jobModel.Job{Id: "slow", TraceId: "timeout-trace", JobType: jobModel.JobTypeQuery, Deadline: time.Now().Add(50 * time.Millisecond)})
+	slow, _ := jobStore.GetJob(ctx, "slow")
+	if slow.Status != jobModel.JobStatusError || slow.Error.Type != jobModel.ErrorTypeTimeout || !slow.Error.Retry {
+		t.Errorf("Expected a retryable TIMEOUT, got %+v", slow.Error)
+	}
+
+	//a deadline that passed while the job was queued fails without running it
+	executeJob(jobModel.Job{Id: "late", TraceId: "timeout-trace", JobType: jobModel.JobTypeQuery, Deadline: time.Now().Add(-time.Second)})
+	late, _ := jobStore.GetJob(ctx, "late")
+	if late.Error.Type != jobModel.ErrorTypeTimeout {
+		t.Errorf("Expected a TIMEOUT for an expired deadline, got %+v", late.Error)
+	}
+	if atomic.LoadInt32(&ragService.ProcessedCount) != 1 {
+		t.Errorf("Expired job was processed, count %d", ragService.ProcessedCount)
+	}
+}
+
+type panickingRagService struct {
+	MockRagService
+}
+
+func (m *panickingRagService) ProcessRequest(ctx context.Context, j jobModel.Job, hist []llm.Message, summary string) jobModel.Job {
+	var missing *jobModel.ErasureReport
+	_ = missing.ChatIds //nil dereference, like a provider returning no response
+	return j
+}
+
+func TestRunJob_PanicFailsJob(t *testing.T) {
+	logger = logger_i.NewLogger("TestPanic")
+	jobStore := store.InitInMemoryJobStore()
+	InitServices(&job.Service{JobStore: jobStore, MessageStore: store.InitMessageStore()}, &panickingRagService{})
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "panic-trace")
+
+	if !runJob(jobModel.Job{Id: "boom", TraceId: "panic-trace", JobType: jobModel.JobTypeQuery}) {
+		t.Fatal("Expected the panic to be reported")
+	}
+	failed, found := jobStore.GetJob(ctx, "boom")
+	if !found || failed.Status != jobModel.JobStatusError || failed.CurrentStep != jobModel.Error {
+		t.Fatalf("Expected the job to be saved as failed, got %+v", failed)
+	}
+	if len(failed.Timeline) == 0 || failed.Timeline[len(failed.Timeline)-1].Outcome != jobModel.StepOutcomeFailed {
+		t.Errorf("Expected the step it stopped on to be failed, got %+v", failed.Timeline)
+	}
+}
+
+func TestTurnStarts_ToolTurns(t *testing.T) {
+	toolCall := llm.Message{Role: llm.RoleAssistant, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeToolUse, ToolCallID: "call-1", ToolName: "lookup"}}}
+	toolResult := llm.Message{Role: llm.RoleUser, Content: []llm.ContentBlock{{Type: llm.ContentBlockTypeToolResult, ToolCallID: "call-1", ToolResult: "12 bar"}}}
+	messages := []llm.Message{
+		llm.TextMessage(llm.RoleUser, "q1"), llm.TextMessage(llm.RoleAssistant, "a1"),
+		llm.TextMessage(llm.RoleUser, "q2"), toolCall, toolResult, llm.TextMessage(llm.RoleAssistant, "a2"),
+		llm.TextMessage(llm.RoleUser, "q3"), llm.TextMessage(llm.RoleAssistant, "a3"),
+	}
+	if starts := turnStarts(messages); fmt.Sprint(starts) != "[0 2 6]" {
+		t.Errorf("Expected three turns starting at 0, 2 and 6, got %v", starts)
+	}
+}
+
+// summarisingRagService records what it was asked to summarise
+type summarisingRagService struct {
+	MockRagService
+	previous []string
+	turns    [][]llm.Message
+}
+
+func (m *summarisingRagService) SummariseChat(ctx context.Context, previous string, turns []llm.Message) (string, error) {
+	m.previous = append(m.previous, previous)
+	m.turns = append(m.turns, turns)
+	return fmt.Sprintf("summary of %d turns", len(turns)/2), nil
+}
+
+func TestRefreshSummary_FoldsTurnsOutsideTheWindow(t *testing.T) {
+	logger = logger_i.NewLogger("TestSummary")
+	t.Setenv("CHAT_HISTORY_MAX_TURNS", "2")
+	t.Setenv("CHAT_HISTORY_MAX_TOKENS", "0")
+	messageStore := store.InitMessageStore()
+	ragService := &summarisingRagService{}
+	InitServices(&job.Service{JobStore: store.InitInMemoryJobStore(), MessageStore: messageStore}, ragService)
+	ctx := context.WithValue(context.Background(), config.TRACE_ID_KEY, "summary-trace")
+
+	_ = messageStore.InitNewChat(ctx, "long-chat", "")
+	ask := func(i int) {
+		_ = messageStore.TrySaveChat(ctx, "long-chat", jobModel.JobPayload{Question: fmt.Sprintf("q%d", i), Answer: fmt.Sprintf("a%d", i)})
+		refreshSummary("long-chat", "summary-trace")
+	}
+
+	//3 turns leave one outside the window, below the minimum
+	for i := 1; i <= 3; i++ {
+		ask(i)
+	}
+	if _, found, _ := messageStore.GetSummary(ctx, "long-chat"); found || len(ragService.turns) != 0 {
+		t.Fatalf("Summarised too early: %v", ragService.turns)
+	}
+
+	ask(4)
+	summary, found, _ := messageStore.GetSummary(ctx, "long-chat")
+	if !found || summary.Turns != 2 || summary.Text != "summary of 2 turns" {
+		t.Fatalf("Expected the first two turns summarised, got %+v", summary)
+	}
+
+	ask(5)
+	ask(6)
+	summary, _, _ = messageStore.GetSummary(ctx, "long-chat")
+	if summary.Turns != 4 || len(ragService.turns) != 2 {
+		t.Fatalf("Expected a second summary covering four turns, got %+v after %d calls", summary, len(ragService.turns))
+	}
+	//only the newly dropped turns are sent, with the previous summary
+	if ragService.previous[1] != "summary of 2 turns" || ragService.turns[1][0].Text() != "q3" || len(ragService.turns[1]) != 4 {
+		t.Errorf("Unexpected second summarisation: previous %q turns %+v", ragService.previous[1], ragService.turns[1])
+	}
+}
+
+type recordingQueue struct {
+	requeued []string
+	acked    []string
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, j jobModel.Job) error { return nil }
+
+func (q *recordingQueue) Requeue(ctx context.Context, j jobModel.Job) error {
+	//every requeue goes to the front, like the redis queue
+	q.requeued = append([]string{j.Id}, q.requeued...)
+	return nil
+}
+
+func (q *recordingQueue) Dequeue(ctx context.Context, timeout time.Duration) (jobModel.Job, bool, error) {
+	return jobModel.Job{}, false, nil
+}
+
+func (q *recordingQueue) Ack(ctx context.Context, j jobModel.Job) error {
+	q.acked = append(q.acked, j.Id)
+	return nil
+}
+
+func (q *recordingQueue) Heartbeat(ctx context.Context) error { return nil }
+
+func (q *recordingQueue) Reap(ctx context.Context) ([]jobModel.Job, error) { return nil, nil }
+
+func TestRequeueUnstarted_KeepsOrder(t *testing.T) {
+	logger = logger_i.NewLogger("TestRequeue")
+	jobSvc := &job.Service{JobChannel: make(chan jobModel.Job, 3)}
+	InitServices(jobSvc, &MockRagService{})
+	for _, id := range []string{"a", "b", "c"} {
+		jobSvc.JobChannel <- jobModel.Job{Id: id}
+	}
+
+	queue := &recordingQueue{}
+	RequeueUnstarted(context.Background(), queue)
+
+	if len(jobSvc.JobChannel) != 0 {
+		t.Errorf("Job channel still holds %d jobs", len(jobSvc.JobChannel))
+	}
+	if fmt.Sprint(queue.requeued) != "[a b c]" {
+		t.Errorf("Queue order after requeue is %v", queue.requeued)
+	}
+}
+
+func TestTakeOver_SettlesTheJobsOfAStoppedProcess(t *testing.T) {
+	logger = logger_i.NewLogger("TestTakeOver")
+	jobStore := store.InitInMemoryJobStore()
+	queue := &recordingQueue{}
+	InitServices(&job.Service{JobStore: jobStore, EventStore: jobStore, JobQueue: queue}, &MockRagService{})
+	ctx := context.Background()
+
+	statuses := map[string]jobModel.JobStatus{"queued": jobModel.JobStatusQueued, "running": jobModel.JobStatusRunning, "complete": jobModel.JobStatusComplete}
+	for _, id := range []string{"queued", "running", "complete"} {
+		reaped := jobModel.Job{Id: id, JobType: jobModel.JobTypeQuery, Status: statuses[id]}
+		_ = jobStore.SaveJob(ctx, reaped)
+		takeOver(ctx, queue, reaped)
+	}
+	//erased while the process was down
+	takeOver(ctx, queue, jobModel.Job{Id: "erased"})
+
+	if fmt.Sprint(queue.requeued) != "[queued]" {
+		t.Errorf("Expected only the unstarted job requeued, got %v", queue.requeued)
+	}
+	if fmt.Sprint(queue.acked) != "[running complete erased]" {
+		t.Errorf("Expected every other job acked, got %v", queue.acked)
+	}
+	if running, _ := jobStore.GetJob(ctx, "running"); running.Status != jobModel.JobStatusError || !running.Error.Retry {
+		t.Errorf("Expected the running job failed, got %+v", running)
+	}
+	if complete, _ := jobStore.GetJob(ctx, "complete"); complete.Status != jobModel.JobStatusComplete {
+		t.Errorf("Expected the complete job left alone, got %+v", complete)
+	}
+}
@@ -38,9 +38,9 @@ export default function () {
         let retries = 10;
 
         while (!jobCompleted && retries > 0) {
-            sleep(2);
+            //long poll - the server holds the request until the job status changes
             const statusReq = JSON.stringify({ job_id: jobId });
-            let tempurl = `${BASE_URL}/${statusUrl}`
+            let tempurl = `${BASE_URL}/${statusUrl}?wait=9s`
             console.log(tempurl)
             const pollRes = http.get(tempurl, statusReq, params);
 